@@ -60,11 +60,30 @@ func LoadDB() (*gorm.DB, error) {
 		&model.JobApplication{},
 		&model.Audit{},
 		&model.MailLog{},
+		&model.SavedJob{},
+		&model.JobAlert{},
+		&model.Skill{},
+		&model.SkillAlias{},
+		&model.JobSkill{},
+		&model.StudentSkill{},
+		&model.JobRevision{},
+		&model.JobTemplate{},
+		&model.JobView{},
+		&model.JobViewFlush{},
+		&model.Report{},
+		&model.JobQuestion{},
+		&model.ApplicationStatusChange{},
+		&model.Interview{},
+		&model.InterviewSlot{},
 	}
 
 	db_err := db.AutoMigrate(allModels...)
 	if db_err != nil {
 		return nil, err
 	}
+
+	if err := SetupJobSearch(db); err != nil {
+		return nil, err
+	}
 	return db, nil
 }
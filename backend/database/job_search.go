@@ -0,0 +1,52 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// JobSearchConfig is the PostgreSQL text search configuration used to build and query job search documents.
+// Its stemming only helps words in the Latin script, Thai keywords are matched as substrings instead (see helper.SubstringSearchWords).
+const JobSearchConfig = "english"
+
+// jobSearchSQL keeps jobs.search_document in sync with the job and the company username.
+// Name, position and company are weighted above duration and description so ts_rank favours title matches.
+const jobSearchSQL = `
+CREATE OR REPLACE FUNCTION jobs_refresh_search_document() RETURNS trigger AS $$
+BEGIN
+	NEW.search_document :=
+		setweight(to_tsvector('` + JobSearchConfig + `', coalesce(NEW.name, '')), 'A') ||
+		setweight(to_tsvector('` + JobSearchConfig + `', coalesce(NEW.position, '')), 'A') ||
+		setweight(to_tsvector('` + JobSearchConfig + `', coalesce((SELECT username FROM users WHERE users.id = NEW.company_id), '')), 'B') ||
+		setweight(to_tsvector('` + JobSearchConfig + `', coalesce(NEW.duration, '')), 'C') ||
+		setweight(to_tsvector('` + JobSearchConfig + `', coalesce(NEW.description, '')), 'D');
+	RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER jobs_search_document_refresh
+	BEFORE INSERT OR UPDATE ON jobs
+	FOR EACH ROW EXECUTE FUNCTION jobs_refresh_search_document();
+
+CREATE OR REPLACE FUNCTION users_refresh_job_search_document() RETURNS trigger AS $$
+BEGIN
+	UPDATE jobs SET search_document = NULL WHERE company_id = NEW.id;
+	RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER users_job_search_document_refresh
+	AFTER UPDATE OF username ON users
+	FOR EACH ROW
+	WHEN (OLD.username IS DISTINCT FROM NEW.username)
+	EXECUTE FUNCTION users_refresh_job_search_document();
+`
+
+// SetupJobSearch installs the triggers that maintain the job search document
+// and backfills documents for jobs created before the triggers existed.
+func SetupJobSearch(db *gorm.DB) error {
+	if err := db.Exec(jobSearchSQL).Error; err != nil {
+		return err
+	}
+	// Updating any column fires the trigger, which recomputes the document.
+	return db.Exec("UPDATE jobs SET search_document = NULL WHERE search_document IS NULL").Error
+}
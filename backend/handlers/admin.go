@@ -1,13 +1,13 @@
 package handlers
 
 import (
+	"ku-work/backend/helper"
 	"ku-work/backend/model"
 	"log/slog"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
 type AdminHandlers struct {
@@ -25,14 +25,20 @@ func NewAdminHandlers(db *gorm.DB) *AdminHandlers {
 // @Tags Admin
 // @Security BearerAuth
 // @Produce json
+// @Param offset query uint false "Pagination offset"
+// @Param limit query uint false "Pagination limit" default(32)
+// @Param cursor query string false "Opaque cursor from the X-Next-Cursor or X-Prev-Cursor header of a previous page, used instead of offset"
 // @Success 200 {array} model.Audit "List of all audit log entries"
+// @Header 200 {string} X-Next-Cursor "Cursor for the next page, absent on the last page"
+// @Header 200 {string} X-Prev-Cursor "Cursor for the previous page, absent on the first page"
 // @Failure 403 {object} object{error=string} "Forbidden"
 // @Failure 500 {object} object{error=string} "Internal Server Error"
 // @Router /admin/audits [get]
 func (h *AdminHandlers) FetchAuditLog(ctx *gin.Context) {
 	type FetchAuditLogInput struct {
-		Offset uint `json:"offset" form:"offset"`
-		Limit  uint `json:"limit" form:"limit" binding:"max=64"`
+		Offset uint   `json:"offset" form:"offset"`
+		Limit  uint   `json:"limit" form:"limit" binding:"max=64"`
+		Cursor string `json:"cursor" form:"cursor" binding:"max=512"`
 	}
 	input := FetchAuditLogInput{
 		Limit: 32,
@@ -43,13 +49,23 @@ func (h *AdminHandlers) FetchAuditLog(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
 		return
 	}
+	query, cursor, ok := applyLogPagination(ctx, h.DB.Model(&model.Audit{}), input.Cursor, input.Offset, int(input.Limit))
+	if !ok {
+		return
+	}
 	var auditLogEntry []model.Audit
-	result := h.DB.Model(&model.Audit{}).Offset(int(input.Offset)).Limit(int(input.Limit)).Order(clause.OrderByColumn{Column: clause.Column{Name: "created_at"}, Desc: true}).Find(&auditLogEntry)
+	result := query.Find(&auditLogEntry)
 	if result.Error != nil {
 		slog.Error("Failed to fetch audit log", "error", result.Error)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit log"})
 		return
 	}
+	auditLogEntry, hasMore := helper.KeysetPage(auditLogEntry, int(input.Limit), cursor)
+	nextCursor, prevCursor := helper.PageCursors("", auditLogEntry, func(entry model.Audit) []any {
+		return []any{entry.CreatedAt, entry.ID}
+	}, hasMore, cursor, input.Offset)
+	ctx.Header("X-Next-Cursor", nextCursor)
+	ctx.Header("X-Prev-Cursor", prevCursor)
 	ctx.JSON(http.StatusOK, auditLogEntry)
 }
 
@@ -58,14 +74,20 @@ func (h *AdminHandlers) FetchAuditLog(ctx *gin.Context) {
 // @Tags Admin
 // @Security BearerAuth
 // @Produce json
+// @Param offset query uint false "Pagination offset"
+// @Param limit query uint false "Pagination limit" default(32)
+// @Param cursor query string false "Opaque cursor from the X-Next-Cursor or X-Prev-Cursor header of a previous page, used instead of offset"
 // @Success 200 {array} model.MailLog "List of all audit log entries"
+// @Header 200 {string} X-Next-Cursor "Cursor for the next page, absent on the last page"
+// @Header 200 {string} X-Prev-Cursor "Cursor for the previous page, absent on the first page"
 // @Failure 403 {object} object{error=string} "Forbidden"
 // @Failure 500 {object} object{error=string} "Internal Server Error"
 // @Router /admin/emaillog [get]
 func (h *AdminHandlers) FetchEmailLog(ctx *gin.Context) {
 	type FetchEmailLogInput struct {
-		Offset uint `json:"offset" form:"offset"`
-		Limit  uint `json:"limit" form:"limit" binding:"max=64"`
+		Offset uint   `json:"offset" form:"offset"`
+		Limit  uint   `json:"limit" form:"limit" binding:"max=64"`
+		Cursor string `json:"cursor" form:"cursor" binding:"max=512"`
 	}
 	input := FetchEmailLogInput{
 		Limit: 32,
@@ -76,16 +98,49 @@ func (h *AdminHandlers) FetchEmailLog(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
 		return
 	}
+	query, cursor, ok := applyLogPagination(ctx, h.DB.Model(&model.MailLog{}), input.Cursor, input.Offset, int(input.Limit))
+	if !ok {
+		return
+	}
 	var emailLogs []model.MailLog
-	result := h.DB.Model(&model.MailLog{}).
-		Offset(int(input.Offset)).
-		Limit(int(input.Limit)).
-		Order(clause.OrderByColumn{Column: clause.Column{Name: "created_at"}, Desc: true}).
-		Find(&emailLogs)
+	result := query.Find(&emailLogs)
 	if result.Error != nil {
 		slog.Error("Failed to fetch email log", "error", result.Error)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch email log"})
 		return
 	}
+	emailLogs, hasMore := helper.KeysetPage(emailLogs, int(input.Limit), cursor)
+	nextCursor, prevCursor := helper.PageCursors("", emailLogs, func(entry model.MailLog) []any {
+		return []any{entry.CreatedAt, entry.ID}
+	}, hasMore, cursor, input.Offset)
+	ctx.Header("X-Next-Cursor", nextCursor)
+	ctx.Header("X-Prev-Cursor", prevCursor)
 	ctx.JSON(http.StatusOK, emailLogs)
 }
+
+// logSortKeys orders admin logs newest first, with the ID breaking ties between entries written at the same time.
+var logSortKeys = []helper.SortKey{
+	{Expr: "created_at", Desc: true, Kind: helper.CursorTime},
+	{Expr: "id", Desc: true, Kind: helper.CursorInt},
+}
+
+// applyLogPagination pages an admin log query by cursor if one is given, otherwise by offset.
+// The log arrays are returned as they always were, so the cursors travel in the X-Next-Cursor and X-Prev-Cursor headers.
+// It writes the error response itself and reports whether the handler should continue.
+func applyLogPagination(ctx *gin.Context, query *gorm.DB, encodedCursor string, offset uint, limit int) (*gorm.DB, *helper.Cursor, bool) {
+	var cursor *helper.Cursor
+	if encodedCursor != "" {
+		if offset != 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "cursor cannot be combined with offset"})
+			return nil, nil, false
+		}
+		var err error
+		if cursor, err = helper.DecodeCursor(encodedCursor, "", logSortKeys); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return nil, nil, false
+		}
+	} else {
+		query = query.Offset(int(offset))
+	}
+	return query.Scopes(helper.KeysetScope(logSortKeys, cursor, limit)), cursor, true
+}
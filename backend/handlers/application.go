@@ -2,8 +2,12 @@ package handlers
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
+	"io"
+	"ku-work/backend/helper"
 	"ku-work/backend/model"
 	"ku-work/backend/services"
 	"log/slog"
@@ -11,6 +15,7 @@ import (
 	"mime/multipart"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -23,6 +28,8 @@ type ApplicationHandlers struct {
 	emailService                            *services.EmailService
 	jobApplicationStatusUpdateEmailTemplate *template.Template
 	newApplicantEmailTemplate               *template.Template
+	positionFilledEmailTemplate             *template.Template
+	applicationWithdrawnEmailTemplate       *template.Template
 }
 
 func NewApplicationHandlers(db *gorm.DB, emailService *services.EmailService) (*ApplicationHandlers, error) {
@@ -34,12 +41,22 @@ func NewApplicationHandlers(db *gorm.DB, emailService *services.EmailService) (*
 	if err != nil {
 		return nil, err
 	}
+	positionFilledEmailTemplate, err := template.New("job_position_filled.tmpl").ParseFiles("email_templates/job_position_filled.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	applicationWithdrawnEmailTemplate, err := template.New("job_application_withdrawn.tmpl").ParseFiles("email_templates/job_application_withdrawn.tmpl")
+	if err != nil {
+		return nil, err
+	}
 	return &ApplicationHandlers{
 		DB:                                      db,
 		FileHandlers:                            NewFileHandlers(db),
 		emailService:                            emailService,
 		jobApplicationStatusUpdateEmailTemplate: jobApplicationStatusUpdateEmailTemplate,
 		newApplicantEmailTemplate:               newApplicantEmailTemplate,
+		positionFilledEmailTemplate:             positionFilledEmailTemplate,
+		applicationWithdrawnEmailTemplate:       applicationWithdrawnEmailTemplate,
 	}, nil
 }
 
@@ -48,6 +65,8 @@ type ApplyJobInput struct {
 	AltPhone string                  `form:"phone" binding:"max=20"`
 	AltEmail string                  `form:"email" binding:"max=128"`
 	Files    []*multipart.FileHeader `form:"files" binding:"max=2,required"`
+	// Answers is a JSON list of answers to the job's screening questions
+	Answers string `form:"answers" binding:"max=65536"`
 }
 
 // ShortApplicationDetail defines the response structure including the applicant's name.
@@ -57,19 +76,24 @@ type ShortApplicationDetail struct {
 	Major     string `json:"major"`
 	StudentID string `json:"studentId"`
 	Status    string `json:"status"`
+	// Evaluation is only shown to the company that posted the job
+	Evaluation *ApplicationEvaluation `gorm:"-" json:"evaluation,omitempty"`
 }
 
 type ApplicationWithJobDetails struct {
 	model.JobApplication
-	JobPosition   string `json:"position"`
-	JobName       string `json:"jobName"`
-	CompanyName   string `json:"companyName"`
-	CompanyLogoID string `json:"photoId"`
-	JobType       string `json:"jobType"`
-	Experience    string `json:"experience"`
-	MinSalary     uint   `json:"minSalary"`
-	MaxSalary     uint   `json:"maxSalary"`
-	IsOpen        bool   `json:"isOpen"`
+	JobPosition       string `json:"position"`
+	JobName           string `json:"jobName"`
+	CompanyName       string `json:"companyName"`
+	CompanyLogoID     string `json:"photoId"`
+	JobType           string `json:"jobType"`
+	Experience        string `json:"experience"`
+	MinSalary         uint   `json:"minSalary"`
+	MaxSalary         uint   `json:"maxSalary"`
+	SalaryCurrency    string `json:"salaryCurrency"`
+	SalaryPeriod      string `json:"salaryPeriod"`
+	SalaryUndisclosed bool   `json:"salaryUndisclosed"`
+	IsOpen            bool   `json:"isOpen"`
 }
 
 // FullApplicantDetail defines the response structure for a detailed application view.
@@ -85,6 +109,10 @@ type FullApplicantDetail struct {
 	LinkedIn  string    `json:"linkedIn"`
 	StudentID string    `json:"studentId"`
 	Major     string    `json:"major"`
+	// ScreeningAnswers are the student's answers to the job's screening questions
+	ScreeningAnswers []model.ApplicationAnswer `gorm:"-" json:"answers"`
+	// Evaluation is only shown to the company that posted the job
+	Evaluation *ApplicationEvaluation `gorm:"-" json:"evaluation,omitempty"`
 }
 
 // @Summary Apply to a job
@@ -97,10 +125,11 @@ type FullApplicantDetail struct {
 // @Param Files formData file true "Files to upload (e.g., Resume, Cover Letter). Max 2 files."
 // @Param AltPhone formData string false "Alternate phone number"
 // @Param AltEmail formData string false "Alternate email address"
+// @Param answers formData string false "JSON list of answers to the job's screening questions, e.g. [{\"questionId\": 1, \"value\": \"yes\"}]"
 // @Success 200 {object} object{message=string} "Successfully created job application"
 // @Failure 400 {object} object{error=string} "Bad Request: Invalid input"
 // @Failure 401 {object} object{error=string} "Unauthorized"
-// @Failure 403 {object} object{error=string} "Forbidden: Student status not approved"
+// @Failure 403 {object} object{error=string} "Forbidden: Student status not approved, job closed or application deadline passed"
 // @Failure 404 {object} object{error=string} "Not Found: Invalid Job ID"
 // @Failure 500 {object} object{error=string} "Internal Server Error"
 // @Router /jobs/{id}/apply [post]
@@ -157,6 +186,38 @@ func (h *ApplicationHandlers) CreateJobApplicationHandler(ctx *gin.Context) {
 		}
 		return
 	}
+	if !job.IsPublishedAt(time.Now()) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+		return
+	}
+	if !job.IsOpen {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "this job is no longer accepting applications"})
+		return
+	}
+	if job.ApplicationDeadline != nil && time.Now().After(*job.ApplicationDeadline) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "the application deadline for this job has passed"})
+		return
+	}
+
+	answerInputs := []ApplicationAnswerInput{}
+	if input.Answers != "" {
+		if err := json.Unmarshal([]byte(input.Answers), &answerInputs); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "answers must be a JSON list of answers"})
+			return
+		}
+	}
+	questions, err := loadJobQuestions(h.DB, job.ID)
+	if err != nil {
+		msg := "Failed to fetch job questions"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+	answers, msg := validateApplicationAnswers(questions, answerInputs)
+	if msg != "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
 
 	// Use the student's profile phone and email as the default contact information if none is provided
 	if input.AltPhone == "" {
@@ -181,6 +242,8 @@ func (h *ApplicationHandlers) CreateJobApplicationHandler(ctx *gin.Context) {
 		ContactPhone: input.AltPhone,
 		ContactEmail: input.AltEmail,
 		Status:       model.JobApplicationPending,
+		Stage:        job.Stages()[0].Key,
+		Answers:      answers,
 	}
 	success := false
 	// If create job application fails remove files
@@ -203,8 +266,19 @@ func (h *ApplicationHandlers) CreateJobApplicationHandler(ctx *gin.Context) {
 		jobApplication.Files = append(jobApplication.Files, *fileObject)
 	}
 
-	// Create application database object
-	if err := h.DB.Create(&jobApplication).Error; err != nil {
+	// Create application database object, the first entry of its timeline with it
+	if err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&jobApplication).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.ApplicationStatusChange{
+			JobID:    jobApplication.JobID,
+			UserID:   jobApplication.UserID,
+			ActorID:  student.UserID,
+			ToStatus: jobApplication.Status,
+			ToStage:  jobApplication.Stage,
+		}).Error
+	}); err != nil {
 		slog.Error("Failed to create job application", "error", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create job application"})
 		return
@@ -267,17 +341,24 @@ func (h *ApplicationHandlers) CreateJobApplicationHandler(ctx *gin.Context) {
 
 // @Summary Get applications for a specific job
 // @Description Fetches all job applications for a specific job posting. This endpoint is for companies to view applicants. It supports status filtering (pending, accepted, rejected) and pagination.
+// @Description The company that posted the job also sees its private evaluations, and can filter and sort applications by them.
 // @Tags Job Applications
 // @Security BearerAuth
 // @Produce json
 // @Param id path uint true "Job ID"
 // @Param status query string false "Filter by status (pending, accepted, rejected)"
+// @Param stage query string false "Filter by pipeline stage key"
+// @Param minRating query int false "Only applications rated at least this, from 1 to 5"
+// @Param maxRating query int false "Only applications rated at most this, from 1 to 5"
+// @Param rated query bool false "Only applications with (true) or without (false) a rating"
+// @Param notes query string false "Only applications whose notes contain this text"
+// @Param sortBy query string false "Sort by (latest, oldest, name_az, name_za, rating_desc, rating_asc)"
 // @Param offset query int false "Offset for pagination" default(0)
 // @Param limit query int false "Limit for pagination" default(32)
 // @Success 200 {array} handlers.ShortApplicationDetail "List of job applications"
-// @Failure 400 {object} object{error=string} "Bad Request: Invalid job ID"
+// @Failure 400 {object} object{error=string} "Bad Request: Invalid job ID or input, or minRating greater than maxRating"
 // @Failure 401 {object} object{error=string} "Unauthorized"
-// @Failure 403 {object} object{error=string} "Forbidden: User is not authorized to view these applications"
+// @Failure 403 {object} object{error=string} "Forbidden: User is not authorized to view these applications, or to filter or sort them by evaluation"
 // @Failure 404 {object} object{error=string} "Not Found: Job not found"
 // @Failure 500 {object} object{error=string} "Internal Server Error"
 // @Router /jobs/{id}/applications [get]
@@ -327,9 +408,15 @@ func (h *ApplicationHandlers) GetJobApplicationsHandler(ctx *gin.Context) {
 	// Parse and validate query parameters
 	type FetchJobApplicationsInput struct {
 		Status *string `json:"status" form:"status" binding:"omitempty,max=64"`
-		Offset uint    `json:"offset" form:"offset"`
-		Limit  uint    `json:"limit" form:"limit" binding:"max=64"`
-		SortBy string  `json:"sortBy" form:"sortBy" binding:"oneof='latest' 'oldest' 'name_az' 'name_za'"`
+		Stage  string  `json:"stage" form:"stage" binding:"max=32"`
+		// Evaluation filters, only for the company that posted the job
+		MinRating uint   `json:"minRating" form:"minRating" binding:"max=5"`
+		MaxRating uint   `json:"maxRating" form:"maxRating" binding:"max=5"`
+		Rated     *bool  `json:"rated" form:"rated"`
+		Notes     string `json:"notes" form:"notes" binding:"max=128"`
+		Offset    uint   `json:"offset" form:"offset"`
+		Limit     uint   `json:"limit" form:"limit" binding:"max=64"`
+		SortBy    string `json:"sortBy" form:"sortBy" binding:"oneof='latest' 'oldest' 'name_az' 'name_za' 'rating_desc' 'rating_asc'"`
 	}
 
 	input := FetchJobApplicationsInput{}
@@ -339,12 +426,25 @@ func (h *ApplicationHandlers) GetJobApplicationsHandler(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
 		return
 	}
+	if input.MaxRating != 0 && input.MinRating > input.MaxRating {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "minRating must not be greater than maxRating"})
+		return
+	}
 
 	// Set default limit if not provided
 	if input.Limit == 0 {
 		input.Limit = 32
 	}
 
+	// Evaluations are private to the company, so admins may not filter or sort by them either
+	isOwner := job.CompanyID == userId
+	byEvaluation := input.MinRating != 0 || input.MaxRating != 0 || input.Rated != nil || input.Notes != "" ||
+		input.SortBy == "rating_desc" || input.SortBy == "rating_asc"
+	if byEvaluation && !isOwner {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "forbidden: only the company that posted this job can filter or sort by its evaluations"})
+		return
+	}
+
 	// Build base query joining with users table to fetch applicant username
 	// Filter by the job ID from the URL parameter
 	query := h.DB.Model(&model.JobApplication{}).
@@ -361,6 +461,31 @@ func (h *ApplicationHandlers) GetJobApplicationsHandler(ctx *gin.Context) {
 	if input.Status != nil && *input.Status != "" {
 		query = query.Where("job_applications.status = ?", *input.Status)
 	}
+	stages := job.Stages()
+	if input.Stage != "" {
+		stageQuery, ok := stageScope(h.DB, stages, input.Stage)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("the job has no stage %q", input.Stage)})
+			return
+		}
+		query = query.Where(stageQuery)
+	}
+	if input.MinRating != 0 {
+		query = query.Where("job_applications.rating >= ?", input.MinRating)
+	}
+	if input.MaxRating != 0 {
+		query = query.Where("job_applications.rating <= ?", input.MaxRating)
+	}
+	if input.Rated != nil {
+		if *input.Rated {
+			query = query.Where("job_applications.rating IS NOT NULL")
+		} else {
+			query = query.Where("job_applications.rating IS NULL")
+		}
+	}
+	if notes := strings.TrimSpace(input.Notes); notes != "" {
+		query = query.Where("job_applications.recruiter_notes ILIKE ?", "%"+helper.EscapeLikePattern(notes)+"%")
+	}
 
 	// Sort results
 	switch input.SortBy {
@@ -372,6 +497,10 @@ func (h *ApplicationHandlers) GetJobApplicationsHandler(ctx *gin.Context) {
 		query = query.Order("username ASC")
 	case "name_za":
 		query = query.Order("username DESC")
+	case "rating_desc":
+		query = query.Order("job_applications.rating DESC NULLS LAST").Order("job_applications.created_at DESC")
+	case "rating_asc":
+		query = query.Order("job_applications.rating ASC NULLS LAST").Order("job_applications.created_at DESC")
 	}
 
 	// Execute query with pagination
@@ -398,6 +527,13 @@ func (h *ApplicationHandlers) GetJobApplicationsHandler(ctx *gin.Context) {
 			return
 		}
 		jobApplications[i].Files = files
+		// Applications from before pipelines are shown in the stage they are counted in
+		if stage := model.ApplicationStage(stages, jobApplications[i].Stage, jobApplications[i].JobApplication.Status); stage != -1 {
+			jobApplications[i].Stage = stages[stage].Key
+		}
+		if isOwner {
+			jobApplications[i].Evaluation = evaluationOf(&jobApplications[i].JobApplication)
+		}
 	}
 
 	ctx.JSON(http.StatusOK, jobApplications)
@@ -433,9 +569,10 @@ func (h *ApplicationHandlers) ClearJobApplicationsHandler(ctx *gin.Context) {
 
 	// Parse parameters
 	type ClearJobApplicationsInput struct {
-		Pending  bool `json:"pending"`
-		Rejected bool `json:"rejected"`
-		Accepted bool `json:"accepted"`
+		Pending   bool `json:"pending"`
+		Rejected  bool `json:"rejected"`
+		Accepted  bool `json:"accepted"`
+		Withdrawn bool `json:"withdrawn"`
 	}
 	var input ClearJobApplicationsInput
 	if err := ctx.ShouldBind(&input); err != nil {
@@ -470,6 +607,9 @@ func (h *ApplicationHandlers) ClearJobApplicationsHandler(ctx *gin.Context) {
 	if !input.Pending {
 		query = query.Not("status = ?", model.JobApplicationPending)
 	}
+	if !input.Withdrawn {
+		query = query.Not("status = ?", model.JobApplicationWithdrawn)
+	}
 	if err := query.Delete(&model.JobApplication{}).Error; err != nil {
 		slog.Error("Failed to delete job applications", "error", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete job applications"})
@@ -480,6 +620,7 @@ func (h *ApplicationHandlers) ClearJobApplicationsHandler(ctx *gin.Context) {
 
 // @Summary Get a specific job application
 // @Description Retrieves detailed information about a single job application for a specific student, including the applicant's full profile, contact information, and attached files (resume, etc.).
+// @Description The company that posted the job also sees its private notes and rating of the applicant.
 // @Tags Job Applications
 // @Security BearerAuth
 // @Produce json
@@ -492,6 +633,8 @@ func (h *ApplicationHandlers) ClearJobApplicationsHandler(ctx *gin.Context) {
 // @Failure 500 {object} object{error=string} "Internal Server Error"
 // @Router /jobs/{id}/applications/{email} [get]
 func (h *ApplicationHandlers) GetJobApplicationHandler(ctx *gin.Context) {
+	userId := ctx.MustGet("userID").(string)
+
 	// Extract job ID from URL parameter
 	jobIdStr := ctx.Param("id")
 	jobId64, err := strconv.ParseUint(jobIdStr, 10, 64)
@@ -540,6 +683,21 @@ func (h *ApplicationHandlers) GetJobApplicationHandler(ctx *gin.Context) {
 		return
 	}
 
+	jobApplication.ScreeningAnswers = jobApplication.Answers
+	if jobApplication.ScreeningAnswers == nil {
+		jobApplication.ScreeningAnswers = []model.ApplicationAnswer{}
+	}
+
+	var companyId string
+	if err := h.DB.Model(&model.Job{}).Where("id = ?", jobId).Pluck("company_id", &companyId).Error; err != nil {
+		slog.Error("Failed to get job", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get job"})
+		return
+	}
+	if companyId == userId {
+		jobApplication.Evaluation = evaluationOf(&jobApplication.JobApplication)
+	}
+
 	ctx.JSON(http.StatusOK, jobApplication)
 }
 
@@ -548,11 +706,12 @@ func (h *ApplicationHandlers) GetJobApplicationHandler(ctx *gin.Context) {
 // @Tags Job Applications
 // @Security BearerAuth
 // @Produce json
-// @Param status query string false "Filter by status (pending, accepted, rejected)"
+// @Param status query string false "Filter by status (pending, accepted, rejected, withdrawn)"
 // @Param sortBy query string false "Sort by (name, date-desc, date-asc)" default(date-desc)
 // @Param offset query int false "Offset for pagination" default(0)
 // @Param limit query int false "Limit for pagination" default(32)
-// @Success 200 {object} object{applications=[]handlers.ApplicationWithJobDetails,total=int} "List of job applications with total count"
+// @Param cursor query string false "Opaque cursor from nextCursor or prevCursor of a previous page, used instead of offset"
+// @Success 200 {object} object{applications=[]handlers.ApplicationWithJobDetails,total=int,nextCursor=string,prevCursor=string} "List of job applications with total count"
 // @Failure 401 {object} object{error=string} "Unauthorized"
 // @Failure 403 {object} object{error=string} "Forbidden: User is not a company or student"
 // @Failure 500 {object} object{error=string} "Internal Server Error"
@@ -563,10 +722,11 @@ func (h *ApplicationHandlers) GetAllJobApplicationsHandler(ctx *gin.Context) {
 
 	// Parse and validate query parameters
 	type FetchJobApplicationsInput struct {
-		Status *string `json:"status" form:"status" binding:"omitempty,oneof=pending accepted rejected"`
+		Status *string `json:"status" form:"status" binding:"omitempty,oneof=pending accepted rejected withdrawn"`
 		SortBy string  `json:"sortBy" form:"sortBy" binding:"omitempty,oneof=name date-desc date-asc"`
 		Offset uint    `json:"offset" form:"offset"`
 		Limit  uint    `json:"limit" form:"limit" binding:"max=64"`
+		Cursor string  `json:"cursor" form:"cursor" binding:"max=512"`
 	}
 	input := FetchJobApplicationsInput{}
 	err := ctx.Bind(&input)
@@ -595,6 +755,9 @@ func (h *ApplicationHandlers) GetAllJobApplicationsHandler(ctx *gin.Context) {
 			"jobs.experience as experience",
 			"jobs.min_salary as min_salary",
 			"jobs.max_salary as max_salary",
+			"jobs.salary_currency as salary_currency",
+			"jobs.salary_period as salary_period",
+			"jobs.salary_undisclosed as salary_undisclosed",
 			"jobs.is_open as is_open",
 			"users.username as company_name",
 			"companies.photo_id as company_logo_id")
@@ -611,6 +774,7 @@ func (h *ApplicationHandlers) GetAllJobApplicationsHandler(ctx *gin.Context) {
 		return
 	}
 
+	isStudent := false
 	if result.RowsAffected != 0 {
 		// User is a company: fetch applications for all their job postings
 		query = query.Where("jobs.company_id = ?", userId)
@@ -628,6 +792,7 @@ func (h *ApplicationHandlers) GetAllJobApplicationsHandler(ctx *gin.Context) {
 
 		if result.RowsAffected != 0 {
 			// User is a student: fetch only their own applications
+			isStudent = true
 			query = query.Where("job_applications.user_id = ?", userId)
 		} else {
 			// User is neither company nor student: deny access
@@ -641,27 +806,37 @@ func (h *ApplicationHandlers) GetAllJobApplicationsHandler(ctx *gin.Context) {
 		query = query.Where("job_applications.status = ?", *input.Status)
 	}
 
-	// Apply sorting
-	switch input.SortBy {
-	case "name":
-		query = query.Order("users.username ASC")
-	case "date-desc":
-		query = query.Order("job_applications.created_at DESC")
-	case "date-asc":
-		query = query.Order("job_applications.created_at ASC")
-	default:
-		// Default sort by creation date descending (newest first)
-		query = query.Order("job_applications.created_at DESC")
+	// Apply sorting, defaulting to newest first
+	if input.SortBy == "" {
+		input.SortBy = "date-desc"
+	}
+	sortKeys := applicationSortKeys(input.SortBy)
+	var cursor *helper.Cursor
+	if input.Cursor != "" {
+		if input.Offset != 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "cursor cannot be combined with offset"})
+			return
+		}
+		if cursor, err = helper.DecodeCursor(input.Cursor, input.SortBy, sortKeys); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	} else {
+		query = query.Offset(int(input.Offset))
 	}
 
 	// Execute query with pagination
 	var jobApplications []ApplicationWithJobDetails
-	result = query.Offset(int(input.Offset)).Limit(int(input.Limit)).Scan(&jobApplications)
+	result = query.Scopes(helper.KeysetScope(sortKeys, cursor, int(input.Limit))).Scan(&jobApplications)
 	if result.Error != nil {
 		slog.Error("Failed to get job applications", "error", result.Error)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get job applications"})
 		return
 	}
+	jobApplications, hasMore := helper.KeysetPage(jobApplications, int(input.Limit), cursor)
+	nextCursor, prevCursor := helper.PageCursors(input.SortBy, jobApplications, func(application ApplicationWithJobDetails) []any {
+		return applicationSortValues(input.SortBy, &application)
+	}, hasMore, cursor, input.Offset)
 
 	// Manually load files for each application
 	for i := range jobApplications {
@@ -678,6 +853,10 @@ func (h *ApplicationHandlers) GetAllJobApplicationsHandler(ctx *gin.Context) {
 			return
 		}
 		jobApplications[i].Files = files
+		// Students only see the simplified status, not the company's pipeline stages
+		if isStudent {
+			jobApplications[i].Stage = ""
+		}
 	}
 
 	// Get total count for the same query (without pagination)
@@ -714,11 +893,40 @@ func (h *ApplicationHandlers) GetAllJobApplicationsHandler(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{
 		"applications": jobApplications,
 		"total":        totalCount,
+		"nextCursor":   nextCursor,
+		"prevCursor":   prevCursor,
 	})
 }
 
+// applicationSortKeys lists the keyset ordering of an application listing sort option.
+// Every order ends with the application's primary key so pages never skip or repeat applications.
+func applicationSortKeys(sortBy string) []helper.SortKey {
+	desc := sortBy != "date-asc"
+	keys := []helper.SortKey{}
+	if sortBy == "name" {
+		keys = append(keys, helper.SortKey{Expr: "users.username", Kind: helper.CursorString})
+	}
+	return append(keys,
+		helper.SortKey{Expr: "job_applications.created_at", Desc: desc, Kind: helper.CursorTime},
+		helper.SortKey{Expr: "job_applications.job_id", Desc: desc, Kind: helper.CursorInt},
+		helper.SortKey{Expr: "job_applications.user_id", Desc: desc, Kind: helper.CursorString},
+	)
+}
+
+// applicationSortValues returns an application's values for the keys from applicationSortKeys.
+func applicationSortValues(sortBy string, application *ApplicationWithJobDetails) []any {
+	values := []any{}
+	if sortBy == "name" {
+		values = append(values, application.CompanyName)
+	}
+	return append(values, application.CreatedAt, application.JobID, application.UserID)
+}
+
 // @Summary Update job application status
-// @Description Updates the status of a job application to 'accepted', 'rejected', or 'pending'. This action can only be performed by the company that posted the job.
+// @Description Moves a job application to another stage of the job's hiring pipeline, given either as a stage key or as a status ('accepted', 'rejected', or 'pending'), which moves it to the first stage with that outcome. This action can only be performed by the company that posted the job.
+// @Description Applications in progress can move forward, skipping stages, or be rejected. Rejected applications can be moved back into progress and hired applications can only be rejected. The status students see follows from the outcome of the stage.
+// @Description If the job has a number of openings and this acceptance fills the last one, the job is closed and, if the company asked for it, the applicants still pending are told that the position has been filled.
+// @Description A job closed this way is not reopened when an accepted application is later rejected or withdrawn, the company reopens it by editing the job.
 // @Tags Job Applications
 // @Security BearerAuth
 // @Accept json
@@ -726,11 +934,12 @@ func (h *ApplicationHandlers) GetAllJobApplicationsHandler(ctx *gin.Context) {
 // @Param id path uint true "Job ID"
 // @Param studentUserId path string true "Student User ID"
 // @Param status body handlers.UpdateJobApplicationStatusHandler.UpdateStatusInput true "New status"
-// @Success 200 {object} object{message=string, status=string} "Application status updated successfully"
-// @Failure 400 {object} object{error=string} "Bad Request: Invalid ID or input"
+// @Success 200 {object} object{message=string, status=string, stage=string, jobClosed=bool} "Application status updated successfully"
+// @Failure 400 {object} object{error=string} "Bad Request: Invalid ID or input, unknown stage or a move the pipeline does not allow"
 // @Failure 401 {object} object{error=string} "Unauthorized"
 // @Failure 403 {object} object{error=string} "Forbidden: User is not authorized to update this application"
 // @Failure 404 {object} object{error=string} "Not Found: Job or application not found"
+// @Failure 409 {object} object{error=string} "Conflict: The application has been withdrawn"
 // @Failure 500 {object} object{error=string} "Internal Server Error"
 // @Router /jobs/{id}/applications/{studentUserId} [patch]
 func (h *ApplicationHandlers) UpdateJobApplicationStatusHandler(ctx *gin.Context) {
@@ -770,7 +979,10 @@ func (h *ApplicationHandlers) UpdateJobApplicationStatusHandler(ctx *gin.Context
 
 	// Parse input data
 	type UpdateStatusInput struct {
-		Status string `json:"status" binding:"required,oneof=accepted rejected pending"`
+		Status string `json:"status" binding:"required_without=Stage,omitempty,oneof=accepted rejected pending"`
+		Stage  string `json:"stage" binding:"required_without=Status,max=32"`
+		// Reason is kept in the application's timeline for the company, students do not see it
+		Reason string `json:"reason" binding:"max=1024"`
 	}
 	input := UpdateStatusInput{}
 	if err := ctx.BindJSON(&input); err != nil {
@@ -782,8 +994,8 @@ func (h *ApplicationHandlers) UpdateJobApplicationStatusHandler(ctx *gin.Context
 	// Find the job application
 	jobApplication := &model.JobApplication{}
 	if err := h.DB.Model(&model.JobApplication{}).
-		Joins("INNER JOIN students ON students.user_id = students.user_id").
-		Where("job_id = ? AND students.user_id = ?", jobId, studentUserId).First(jobApplication).Error; err != nil {
+		Joins("INNER JOIN students ON students.user_id = job_applications.user_id").
+		Where("job_applications.job_id = ? AND students.user_id = ?", jobId, studentUserId).First(jobApplication).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "job application not found"})
 		} else {
@@ -792,21 +1004,87 @@ func (h *ApplicationHandlers) UpdateJobApplicationStatusHandler(ctx *gin.Context
 		}
 		return
 	}
+	if jobApplication.Status == model.JobApplicationWithdrawn {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "the applicant has withdrawn this application"})
+		return
+	}
 
-	// Update the status
-	jobApplication.Status = model.JobApplicationStatus(input.Status)
-	if err := h.DB.Save(jobApplication).Error; err != nil {
+	// Work out the stage to move to and check that the pipeline allows the move
+	stages := job.Stages()
+	from := model.ApplicationStage(stages, jobApplication.Stage, jobApplication.Status)
+	to := from
+	if input.Stage != "" {
+		if to = model.FindStage(stages, input.Stage); to == -1 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("the job has no stage %q", input.Stage)})
+			return
+		}
+		if input.Status != "" && string(stages[to].Outcome) != input.Status {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("stage %q does not have the %s outcome", input.Stage, input.Status)})
+			return
+		}
+	} else if string(jobApplication.Status) != input.Status {
+		to = model.FirstStageWithOutcome(stages, model.JobApplicationStatus(input.Status))
+	}
+	if !model.CanMoveToStage(stages, from, to) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("an application cannot move from stage %q to %q", stages[from].Key, stages[to].Key)})
+		return
+	}
+
+	// Update the stage, and the status with it
+	previousStatus := jobApplication.Status
+	jobApplication.Stage = stages[to].Key
+	jobApplication.Status = stages[to].Outcome
+	jobClosed := false
+	if err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(jobApplication).Error; err != nil {
+			return err
+		}
+		if from != to {
+			if err := tx.Create(&model.ApplicationStatusChange{
+				JobID:      jobApplication.JobID,
+				UserID:     jobApplication.UserID,
+				ActorID:    userId,
+				FromStatus: previousStatus,
+				ToStatus:   jobApplication.Status,
+				FromStage:  stages[from].Key,
+				ToStage:    jobApplication.Stage,
+				Reason:     strings.TrimSpace(input.Reason),
+			}).Error; err != nil {
+				return err
+			}
+		}
+		if jobApplication.Status != model.JobApplicationAccepted {
+			return nil
+		}
+		var err error
+		jobClosed, err = closeFilledJob(tx, job)
+		return err
+	}); err != nil {
 		slog.Error("Failed to update job application status", "error", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update job application status"})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "application status updated successfully",
-		"status":  jobApplication.Status,
+		"message":   "application status updated successfully",
+		"status":    jobApplication.Status,
+		"stage":     jobApplication.Stage,
+		"jobClosed": jobClosed,
 	})
 
-	// Send mail
+	if jobClosed {
+		if err := services.RemoveSavedJobsForClosedJobs(h.DB, job.ID); err != nil {
+			slog.Warn("Failed to remove saved jobs for closed job", "job_id", job.ID, "error", err)
+		}
+		if job.NotifyPendingWhenFilled {
+			go h.sendPositionFilledEmails(job)
+		}
+	}
+
+	// Moves between stages are only emailed when they change what the student sees
+	if jobApplication.Status == previousStatus {
+		return
+	}
 	go (func() {
 		type Context struct {
 			OAuth       model.GoogleOAuthDetails
@@ -817,7 +1095,7 @@ func (h *ApplicationHandlers) UpdateJobApplicationStatusHandler(ctx *gin.Context
 		var context Context
 		context.OAuth.UserID = jobApplication.UserID
 		context.Job = job
-		context.Status = string(input.Status)
+		context.Status = string(jobApplication.Status)
 		if err := h.DB.Select("email", "first_name", "last_name").Take(&context.OAuth).Error; err != nil {
 			return
 		}
@@ -835,3 +1113,220 @@ func (h *ApplicationHandlers) UpdateJobApplicationStatusHandler(ctx *gin.Context
 		)
 	})()
 }
+
+// @Summary Withdraw a job application
+// @Description Lets a student withdraw their own application, for example after accepting another offer. Pending and accepted applications can be withdrawn. The application stays in the history of both sides but no longer counts towards the company's active applications. If the company asked to be emailed about applications to this job, it is told about the withdrawal.
+// @Description Withdrawing an accepted application does not reopen a job that was closed because its openings were filled, the company reopens it by editing the job.
+// @Tags Job Applications
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param jobId path uint true "Job ID"
+// @Param reason body object{reason=string} false "Why the application is withdrawn"
+// @Success 200 {object} object{message=string, status=string, withdrawnAt=string} "Application withdrawn"
+// @Failure 400 {object} object{error=string} "Bad Request: Invalid job ID"
+// @Failure 401 {object} object{error=string} "Unauthorized"
+// @Failure 404 {object} object{error=string} "Not Found: Job application not found"
+// @Failure 409 {object} object{error=string} "Conflict: The application was already withdrawn or rejected"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /applications/{jobId}/withdraw [post]
+func (h *ApplicationHandlers) WithdrawJobApplicationHandler(ctx *gin.Context) {
+	userId := ctx.MustGet("userID").(string)
+
+	jobId64, err := strconv.ParseUint(ctx.Param("jobId"), 10, 64)
+	if err != nil || jobId64 <= 0 || jobId64 > math.MaxUint32 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid job ID"})
+		return
+	}
+
+	// The reason is optional, and so is the body
+	type WithdrawInput struct {
+		Reason string `json:"reason" binding:"max=1024"`
+	}
+	input := WithdrawInput{}
+	if err := ctx.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		slog.Debug("Failed to bind withdraw job application request", "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	jobApplication := model.JobApplication{JobID: uint(jobId64), UserID: userId}
+	if err := h.DB.Take(&jobApplication).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "job application not found"})
+		} else {
+			slog.Error("Failed to get job application", "error", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get job application"})
+		}
+		return
+	}
+	switch jobApplication.Status {
+	case model.JobApplicationWithdrawn:
+		ctx.JSON(http.StatusConflict, gin.H{"error": "this application has already been withdrawn"})
+		return
+	case model.JobApplicationRejected:
+		ctx.JSON(http.StatusConflict, gin.H{"error": "a rejected application cannot be withdrawn"})
+		return
+	}
+
+	// Only update if the status is still the one read, in case the company changed it meanwhile
+	now := time.Now()
+	errStatusChanged := errors.New("application status changed")
+	if err := h.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.JobApplication{}).
+			Where("job_id = ? AND user_id = ? AND status = ?", jobApplication.JobID, jobApplication.UserID, jobApplication.Status).
+			Updates(map[string]any{"status": model.JobApplicationWithdrawn, "withdrawn_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errStatusChanged
+		}
+		return tx.Create(&model.ApplicationStatusChange{
+			JobID:      jobApplication.JobID,
+			UserID:     jobApplication.UserID,
+			ActorID:    userId,
+			FromStatus: jobApplication.Status,
+			ToStatus:   model.JobApplicationWithdrawn,
+			FromStage:  jobApplication.Stage,
+			ToStage:    jobApplication.Stage,
+			Reason:     strings.TrimSpace(input.Reason),
+		}).Error
+	}); err != nil {
+		if err == errStatusChanged {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "the application status changed, please try again"})
+			return
+		}
+		slog.Error("Failed to withdraw job application", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to withdraw job application"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":     "application withdrawn",
+		"status":      model.JobApplicationWithdrawn,
+		"withdrawnAt": now,
+	})
+
+	go h.sendApplicationWithdrawnEmail(jobApplication.JobID, userId, now)
+}
+
+// sendApplicationWithdrawnEmail tells the company that an applicant withdrew, if it asked to be emailed about applications to the job.
+func (h *ApplicationHandlers) sendApplicationWithdrawnEmail(jobID uint, studentUserID string, withdrawnAt time.Time) {
+	type Context struct {
+		CompanyUser model.User
+		Job         model.Job
+		Applicant   model.GoogleOAuthDetails
+		Application struct {
+			Date time.Time
+		}
+	}
+	var context Context
+	if err := h.DB.Take(&context.Job, jobID).Error; err != nil {
+		slog.Warn("Failed to fetch job for withdrawal email", "job_id", jobID, "error", err)
+		return
+	}
+	if !context.Job.NotifyOnApplication {
+		return
+	}
+	// Convert to Bangkok timezone (GMT+7)
+	bangkokLocation, _ := time.LoadLocation("Asia/Bangkok")
+	context.Application.Date = withdrawnAt.In(bangkokLocation)
+
+	if err := h.DB.Where("id = ?", context.Job.CompanyID).First(&context.CompanyUser).Error; err != nil {
+		return
+	}
+	var company model.Company
+	if err := h.DB.Where("user_id = ?", context.Job.CompanyID).First(&company).Error; err != nil {
+		return
+	}
+	if err := h.DB.Select("first_name", "last_name").Where("user_id = ?", studentUserID).First(&context.Applicant).Error; err != nil {
+		return
+	}
+
+	var tpl bytes.Buffer
+	if err := h.applicationWithdrawnEmailTemplate.Execute(&tpl, context); err != nil {
+		slog.Warn("Failed to render withdrawal email", "job_id", jobID, "error", err)
+		return
+	}
+	_ = h.emailService.SendTo(
+		company.Email,
+		fmt.Sprintf("[KU-Work] Application Withdrawn for %s - %s", context.Job.Name, context.Job.Position),
+		tpl.String(),
+	)
+}
+
+// closeFilledJob closes an open job once its accepted applications reach its number of openings,
+// recording the change as a revision by the company. Reports whether the job was closed.
+// Jobs are never reopened automatically, the company decides whether to hire again when an accepted applicant drops out.
+func closeFilledJob(tx *gorm.DB, job *model.Job) (bool, error) {
+	if job.Openings == nil || !job.IsOpen {
+		return false, nil
+	}
+	var accepted int64
+	if err := tx.Model(&model.JobApplication{}).
+		Where("job_id = ? AND status = ?", job.ID, model.JobApplicationAccepted).
+		Count(&accepted).Error; err != nil {
+		return false, err
+	}
+	if accepted < int64(*job.Openings) {
+		return false, nil
+	}
+
+	result := tx.Model(&model.Job{}).
+		Where("id = ? AND is_open = ?", job.ID, true).
+		Update("is_open", false)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	job.IsOpen = false
+	return true, tx.Create(&model.JobRevision{
+		JobID:    job.ID,
+		EditorID: job.CompanyID,
+		Changes:  []model.JobFieldChange{{Field: "open", Old: true, New: false}},
+	}).Error
+}
+
+// sendPositionFilledEmails tells the applicants still pending on a filled job that it is no longer hiring.
+func (h *ApplicationHandlers) sendPositionFilledEmails(job *model.Job) {
+	type Applicant struct {
+		Email     string
+		FirstName string
+		LastName  string
+	}
+	type Context struct {
+		Applicant   Applicant
+		Job         *model.Job
+		CompanyName string
+	}
+	var context Context
+	context.Job = job
+	if err := h.DB.Model(&model.User{ID: job.CompanyID}).Pluck("username", &context.CompanyName).Error; err != nil {
+		slog.Warn("Failed to fetch company for position filled emails", "job_id", job.ID, "error", err)
+		return
+	}
+
+	var applicants []Applicant
+	if err := h.DB.Model(&model.JobApplication{}).
+		Joins("INNER JOIN google_o_auth_details ON google_o_auth_details.user_id = job_applications.user_id").
+		Select("google_o_auth_details.email, google_o_auth_details.first_name, google_o_auth_details.last_name").
+		Where("job_applications.job_id = ? AND job_applications.status = ?", job.ID, model.JobApplicationPending).
+		Scan(&applicants).Error; err != nil {
+		slog.Warn("Failed to fetch pending applicants for position filled emails", "job_id", job.ID, "error", err)
+		return
+	}
+
+	for _, applicant := range applicants {
+		context.Applicant = applicant
+		var tpl bytes.Buffer
+		if err := h.positionFilledEmailTemplate.Execute(&tpl, context); err != nil {
+			slog.Warn("Failed to render position filled email", "job_id", job.ID, "error", err)
+			return
+		}
+		_ = h.emailService.SendTo(
+			applicant.Email,
+			fmt.Sprintf("[KU-Work] %s - %s has been filled", job.Name, job.Position),
+			tpl.String(),
+		)
+	}
+}
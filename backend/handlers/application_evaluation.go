@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"ku-work/backend/model"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ApplicationEvaluation is the private evaluation of an applicant by the company that posted the job.
+type ApplicationEvaluation struct {
+	Notes       string     `json:"notes"`
+	Rating      *uint      `json:"rating"`
+	EvaluatedAt *time.Time `json:"evaluatedAt"`
+}
+
+// evaluationOf returns the company's evaluation of a job application.
+func evaluationOf(jobApplication *model.JobApplication) *ApplicationEvaluation {
+	return &ApplicationEvaluation{
+		Notes:       jobApplication.RecruiterNotes,
+		Rating:      jobApplication.Rating,
+		EvaluatedAt: jobApplication.EvaluatedAt,
+	}
+}
+
+// @Summary Evaluate a job application
+// @Description Lets the company that posted a job keep private notes and a rating from 1 to 5 on an applicant. Fields left out are kept, a rating of 0 removes it.
+// @Description Only the company sees its evaluations, they are shown in its application lists and details.
+// @Tags Job Applications
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path uint true "Job ID"
+// @Param studentUserId path string true "Student User ID"
+// @Param evaluation body object{notes=string,rating=uint} true "Notes and rating"
+// @Success 200 {object} object{evaluation=handlers.ApplicationEvaluation} "Evaluation saved"
+// @Failure 400 {object} object{error=string} "Bad Request: Invalid ID or input"
+// @Failure 401 {object} object{error=string} "Unauthorized"
+// @Failure 403 {object} object{error=string} "Forbidden: Only the company that posted the job"
+// @Failure 404 {object} object{error=string} "Not Found: Job or application not found"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /jobs/{id}/applications/{studentUserId}/evaluation [patch]
+func (h *ApplicationHandlers) EvaluateJobApplicationHandler(ctx *gin.Context) {
+	userId := ctx.MustGet("userID").(string)
+
+	jobId64, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil || jobId64 <= 0 || jobId64 > math.MaxUint32 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid job ID"})
+		return
+	}
+	jobId := uint(jobId64)
+	studentUserId := ctx.Param("studentUserId")
+
+	type EvaluateInput struct {
+		Notes  *string `json:"notes" binding:"omitempty,max=4096"`
+		Rating *uint   `json:"rating" binding:"omitempty,max=5"`
+	}
+	input := EvaluateInput{}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		slog.Debug("Failed to bind evaluate job application request", "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if input.Notes == nil && input.Rating == nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "notes or rating is required"})
+		return
+	}
+
+	job := model.Job{}
+	if err := h.DB.Select("id", "company_id").Take(&job, jobId).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+		} else {
+			slog.Error("Failed to get job", "error", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get job"})
+		}
+		return
+	}
+	if job.CompanyID != userId {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "forbidden: only the company that posted this job"})
+		return
+	}
+
+	jobApplication := model.JobApplication{JobID: jobId, UserID: studentUserId}
+	if err := h.DB.Take(&jobApplication).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "job application not found"})
+		} else {
+			slog.Error("Failed to get job application", "error", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get job application"})
+		}
+		return
+	}
+
+	now := time.Now()
+	updates := map[string]any{"evaluated_at": now}
+	if input.Notes != nil {
+		jobApplication.RecruiterNotes = strings.TrimSpace(*input.Notes)
+		updates["recruiter_notes"] = jobApplication.RecruiterNotes
+	}
+	if input.Rating != nil {
+		jobApplication.Rating = input.Rating
+		if *input.Rating == 0 {
+			jobApplication.Rating = nil
+		}
+		updates["rating"] = jobApplication.Rating
+	}
+	jobApplication.EvaluatedAt = &now
+	if err := h.DB.Model(&model.JobApplication{}).
+		Where("job_id = ? AND user_id = ?", jobApplication.JobID, jobApplication.UserID).
+		Updates(updates).Error; err != nil {
+		msg := "Failed to save evaluation"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"evaluation": evaluationOf(&jobApplication)})
+}
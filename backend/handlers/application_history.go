@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"ku-work/backend/helper"
+	"ku-work/backend/model"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ApplicationStatusChangeResponse is an entry of an application's timeline as shown to the company.
+type ApplicationStatusChangeResponse struct {
+	model.ApplicationStatusChange
+	ActorName string `json:"actorName"`
+}
+
+// ApplicationTimelineEntry is an entry of an application's timeline as shown to the student who applied.
+// Only changes of the status students see are listed, without who made them, the stages or the reasons.
+type ApplicationTimelineEntry struct {
+	CreatedAt  time.Time                  `json:"createdAt"`
+	FromStatus model.JobApplicationStatus `json:"fromStatus"`
+	ToStatus   model.JobApplicationStatus `json:"toStatus"`
+}
+
+// @Summary Get the status history of a job application
+// @Description Lists every change of a job application, oldest first, with when it happened, who made it, the status and stage before and after and the reason given.
+// @Description The company that posted the job and admins see the full history. The student who applied sees a redacted timeline of their own application, listing only changes of the status they see.
+// @Tags Job Applications
+// @Security BearerAuth
+// @Produce json
+// @Param id path uint true "Job ID"
+// @Param studentUserId path string true "Student User ID"
+// @Success 200 {object} object{history=[]handlers.ApplicationStatusChangeResponse} "Full history, or []handlers.ApplicationTimelineEntry for the student"
+// @Failure 400 {object} object{error=string} "Bad Request: Invalid job ID"
+// @Failure 401 {object} object{error=string} "Unauthorized"
+// @Failure 403 {object} object{error=string} "Forbidden"
+// @Failure 404 {object} object{error=string} "Not Found: Job application not found"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /jobs/{id}/applicants/{studentUserId}/history [get]
+func (h *ApplicationHandlers) GetJobApplicationHistoryHandler(ctx *gin.Context) {
+	userId := ctx.MustGet("userID").(string)
+
+	jobId64, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil || jobId64 <= 0 || jobId64 > math.MaxUint32 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid job ID"})
+		return
+	}
+	jobId := uint(jobId64)
+	studentUserId := ctx.Param("studentUserId")
+
+	job := model.Job{}
+	if err := h.DB.Select("id", "company_id").Take(&job, jobId).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+		} else {
+			slog.Error("Failed to get job", "error", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get job"})
+		}
+		return
+	}
+	isApplicant := studentUserId == userId
+	if !isApplicant && job.CompanyID != userId && helper.GetRole(userId, h.DB) != helper.Admin {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		return
+	}
+
+	var count int64
+	if err := h.DB.Model(&model.JobApplication{}).
+		Where("job_id = ? AND user_id = ?", jobId, studentUserId).
+		Count(&count).Error; err != nil {
+		slog.Error("Failed to get job application", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get job application"})
+		return
+	}
+	if count == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "job application not found"})
+		return
+	}
+
+	history := []ApplicationStatusChangeResponse{}
+	if err := h.DB.Model(&model.ApplicationStatusChange{}).
+		Joins("LEFT JOIN users ON users.id = application_status_changes.actor_id").
+		Select("application_status_changes.*, users.username AS actor_name").
+		Where("application_status_changes.job_id = ? AND application_status_changes.user_id = ?", jobId, studentUserId).
+		Order("application_status_changes.created_at ASC").
+		Order("application_status_changes.id ASC").
+		Scan(&history).Error; err != nil {
+		slog.Error("Failed to get job application history", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get job application history"})
+		return
+	}
+
+	if !isApplicant {
+		ctx.JSON(http.StatusOK, gin.H{"history": history})
+		return
+	}
+	timeline := []ApplicationTimelineEntry{}
+	for _, change := range history {
+		if change.FromStatus == change.ToStatus {
+			continue
+		}
+		timeline = append(timeline, ApplicationTimelineEntry{
+			CreatedAt:  change.CreatedAt,
+			FromStatus: change.FromStatus,
+			ToStatus:   change.ToStatus,
+		})
+	}
+	ctx.JSON(http.StatusOK, gin.H{"history": timeline})
+}
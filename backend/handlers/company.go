@@ -3,6 +3,7 @@ package handlers
 import (
 	"ku-work/backend/helper"
 	"ku-work/backend/model"
+	"ku-work/backend/services"
 	"log/slog"
 	"net/http"
 	"time"
@@ -36,6 +37,8 @@ type CompanyResponse struct {
 	Website   string    `json:"website"`
 	AboutUs   string    `json:"about"`
 	Name      string    `json:"name"`
+	// SuspendedAt is only included in the admin company list
+	SuspendedAt *time.Time `json:"suspendedAt,omitempty"`
 }
 
 // anonymizeCompany zeros or replaces personally-identifying fields for deactivated accounts.
@@ -123,23 +126,24 @@ func (h *CompanyHandlers) GetCompanyListHandler(ctx *gin.Context) {
 	}
 
 	var rawResults []struct {
-		CreatedAt time.Time
-		UpdatedAt time.Time
-		UserID    string
-		Email     string
-		Phone     string
-		PhotoID   string
-		BannerID  string
-		Address   string
-		City      string
-		Country   string
-		Website   string
-		AboutUs   string
-		Name      string
+		CreatedAt   time.Time
+		UpdatedAt   time.Time
+		UserID      string
+		Email       string
+		Phone       string
+		PhotoID     string
+		BannerID    string
+		Address     string
+		City        string
+		Country     string
+		Website     string
+		AboutUs     string
+		Name        string
+		SuspendedAt *time.Time
 	}
 
 	if err := h.DB.Model(&model.Company{}).
-		Select("companies.created_at, companies.updated_at, companies.user_id, companies.email, companies.phone, companies.photo_id, companies.banner_id, companies.address, companies.city, companies.country, companies.website, companies.about_us, users.username as name").
+		Select("companies.created_at, companies.updated_at, companies.user_id, companies.email, companies.phone, companies.photo_id, companies.banner_id, companies.address, companies.city, companies.country, companies.website, companies.about_us, companies.suspended_at, users.username as name").
 		Joins("INNER JOIN users on users.id = companies.user_id").
 		Find(&rawResults).Error; err != nil {
 		slog.Error("Failed to get company list", "error", err)
@@ -150,19 +154,20 @@ func (h *CompanyHandlers) GetCompanyListHandler(ctx *gin.Context) {
 	companies := make([]CompanyResponse, 0, len(rawResults))
 	for _, r := range rawResults {
 		company := CompanyResponse{
-			CreatedAt: r.CreatedAt,
-			UpdatedAt: r.UpdatedAt,
-			UserID:    r.UserID,
-			Email:     r.Email,
-			Phone:     r.Phone,
-			PhotoID:   r.PhotoID,
-			BannerID:  r.BannerID,
-			Address:   r.Address,
-			City:      r.City,
-			Country:   r.Country,
-			Website:   r.Website,
-			AboutUs:   r.AboutUs,
-			Name:      r.Name,
+			CreatedAt:   r.CreatedAt,
+			UpdatedAt:   r.UpdatedAt,
+			UserID:      r.UserID,
+			Email:       r.Email,
+			Phone:       r.Phone,
+			PhotoID:     r.PhotoID,
+			BannerID:    r.BannerID,
+			Address:     r.Address,
+			City:        r.City,
+			Country:     r.Country,
+			Website:     r.Website,
+			AboutUs:     r.AboutUs,
+			Name:        r.Name,
+			SuspendedAt: r.SuspendedAt,
 		}
 
 		// If the account is deactivated, anonymize the entry.
@@ -175,3 +180,75 @@ func (h *CompanyHandlers) GetCompanyListHandler(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, companies)
 }
+
+type CompanySuspensionInput struct {
+	Suspend bool   `json:"suspend"`
+	Reason  string `json:"reason" binding:"max=2000"`
+}
+
+// @Summary Suspend or reinstate a company (Admin only)
+// @Description Suspending a company blocks its account and closes all of its open jobs. Reinstating it unblocks the account, the jobs stay closed until the company reopens them. Both are written to the audit log.
+// @Tags Companies
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Company User ID"
+// @Param suspension body handlers.CompanySuspensionInput true "Suspension action"
+// @Success 200 {object} object{message=string} "ok"
+// @Failure 400 {object} object{error=string} "Bad Request"
+// @Failure 404 {object} object{error=string} "Not Found"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /company/{id}/suspension [post]
+func (h *CompanyHandlers) CompanySuspensionHandler(ctx *gin.Context) {
+	adminId := ctx.MustGet("userID").(string)
+	companyId := ctx.Param("id")
+
+	input := CompanySuspensionInput{}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		msg := "Failed to bind input"
+		slog.Debug(msg, "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	company := model.Company{}
+	if err := h.DB.Select("user_id").Take(&company, "user_id = ?", companyId).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Company not found"})
+		return
+	}
+
+	var closedJobIDs []uint
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if input.Suspend {
+			jobIDs, err := suspendCompany(tx, company.UserID, adminId, input.Reason)
+			closedJobIDs = jobIDs
+			return err
+		}
+		result := tx.Model(&model.Company{}).
+			Where("user_id = ? AND suspended_at IS NOT NULL", company.UserID).
+			Update("suspended_at", nil)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Create(&model.Audit{
+			ActorID:    adminId,
+			Action:     "reinstated",
+			Reason:     input.Reason,
+			ObjectName: "Company",
+			ObjectID:   company.UserID,
+		}).Error
+	})
+	if err != nil {
+		msg := "Failed to update company"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+	if len(closedJobIDs) != 0 {
+		if err := services.RemoveSavedJobsForClosedJobs(h.DB, closedJobIDs...); err != nil {
+			slog.Warn("Failed to remove saved jobs for closed jobs", "job_ids", closedJobIDs, "error", err)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "ok"})
+}
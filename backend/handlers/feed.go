@@ -0,0 +1,545 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"ku-work/backend/helper"
+	"ku-work/backend/model"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// feedTitle names the feeds in readers
+	feedTitle = "KU-Work open jobs"
+	// feedCacheMaxAge is how long clients and proxies may reuse a feed without asking again
+	feedCacheMaxAge = 5 * time.Minute
+	// jobPublishedAtExpr is when a job became publicly visible, the latest of its approval and scheduled publishing
+	jobPublishedAtExpr = "COALESCE(GREATEST(jobs.publish_at, jobs.approved_at), jobs.created_at)"
+	// jobUpdatedAtExpr is the last time a job was published or edited
+	jobUpdatedAtExpr = "GREATEST(" + jobPublishedAtExpr + ", (SELECT MAX(job_revisions.created_at) FROM job_revisions WHERE job_revisions.job_id = jobs.id))"
+	// sitemapMaxURLs is the most URLs a single sitemap file may hold
+	sitemapMaxURLs = 50000
+)
+
+// FeedHandlers serves the public syndication feeds of open jobs.
+type FeedHandlers struct {
+	DB           *gorm.DB
+	frontendURL  string
+	publicAPIURL string
+}
+
+func NewFeedHandlers(db *gorm.DB) *FeedHandlers {
+	return &FeedHandlers{
+		DB:           db,
+		frontendURL:  helper.GetFrontendURL(),
+		publicAPIURL: helper.GetPublicAPIURL(),
+	}
+}
+
+// feedJob is a job as listed in the feeds.
+type feedJob struct {
+	ID                  uint
+	Name                string
+	Position            string
+	Description         string
+	Location            string
+	Province            string
+	WorkMode            model.WorkMode
+	JobType             model.JobType
+	Experience          model.ExperienceType
+	MinSalary           uint
+	MaxSalary           uint
+	SalaryCurrency      string
+	SalaryPeriod        model.SalaryPeriod
+	SalaryUndisclosed   bool
+	ApplicationDeadline *time.Time
+	CompanyID           string
+	CompanyName         string
+	PublishedAt         time.Time
+	UpdatedAt           time.Time
+}
+
+// feed is the format-independent content of a feed response.
+type feed struct {
+	Jobs         []feedJob
+	SelfURL      string
+	HomeURL      string
+	LastModified time.Time
+	ETag         string
+}
+
+// @Summary RSS feed of open jobs
+// @Description Lists the newest approved, open jobs as RSS 2.0. This is a public endpoint, responses may be cached and support conditional GET with If-None-Match or If-Modified-Since.
+// @Tags Feeds
+// @Produce xml
+// @Param jobType query []string false "Filter by job type(s)"
+// @Param experience query []string false "Filter by experience level(s)"
+// @Param companyId query string false "Filter by company ID"
+// @Param limit query uint false "Number of jobs" default(50)
+// @Success 200 {string} string "RSS document"
+// @Success 304 "Not Modified"
+// @Failure 400 {object} object{error=string} "Bad Request"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /feeds/jobs.rss [get]
+func (h *FeedHandlers) GetJobsRSSHandler(ctx *gin.Context) {
+	f, ok := h.loadFeed(ctx, "rss")
+	if !ok {
+		return
+	}
+
+	type rssGUID struct {
+		IsPermaLink bool   `xml:"isPermaLink,attr"`
+		Value       string `xml:",chardata"`
+	}
+	type rssItem struct {
+		Title       string   `xml:"title"`
+		Link        string   `xml:"link"`
+		Description string   `xml:"description"`
+		GUID        rssGUID  `xml:"guid"`
+		PubDate     string   `xml:"pubDate"`
+		Categories  []string `xml:"category"`
+	}
+	type rssAtomLink struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+		Type string `xml:"type,attr"`
+	}
+	type rssChannel struct {
+		Title         string      `xml:"title"`
+		Link          string      `xml:"link"`
+		Description   string      `xml:"description"`
+		AtomLink      rssAtomLink `xml:"atom:link"`
+		LastBuildDate string      `xml:"lastBuildDate,omitempty"`
+		TTL           int         `xml:"ttl"`
+		Items         []rssItem   `xml:"item"`
+	}
+	type rssFeed struct {
+		XMLName xml.Name   `xml:"rss"`
+		Version string     `xml:"version,attr"`
+		AtomNS  string     `xml:"xmlns:atom,attr"`
+		Channel rssChannel `xml:"channel"`
+	}
+
+	doc := rssFeed{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:       feedTitle,
+			Link:        f.HomeURL,
+			Description: "Approved job openings that are currently accepting applications",
+			AtomLink:    rssAtomLink{Href: f.SelfURL, Rel: "self", Type: "application/rss+xml"},
+			TTL:         int(feedCacheMaxAge.Minutes()),
+			Items:       make([]rssItem, 0, len(f.Jobs)),
+		},
+	}
+	if !f.LastModified.IsZero() {
+		doc.Channel.LastBuildDate = f.LastModified.UTC().Format(time.RFC1123Z)
+	}
+	for _, job := range f.Jobs {
+		link := h.jobURL(job.ID)
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       feedJobTitle(job),
+			Link:        link,
+			Description: feedJobSummary(job) + "\n\n" + job.Description,
+			GUID:        rssGUID{IsPermaLink: true, Value: link},
+			PubDate:     job.PublishedAt.UTC().Format(time.RFC1123Z),
+			Categories:  feedJobTags(job),
+		})
+	}
+	h.writeXML(ctx, "application/rss+xml; charset=utf-8", doc)
+}
+
+// @Summary Atom feed of open jobs
+// @Description Lists the newest approved, open jobs as Atom. This is a public endpoint, responses may be cached and support conditional GET with If-None-Match or If-Modified-Since.
+// @Tags Feeds
+// @Produce xml
+// @Param jobType query []string false "Filter by job type(s)"
+// @Param experience query []string false "Filter by experience level(s)"
+// @Param companyId query string false "Filter by company ID"
+// @Param limit query uint false "Number of jobs" default(50)
+// @Success 200 {string} string "Atom document"
+// @Success 304 "Not Modified"
+// @Failure 400 {object} object{error=string} "Bad Request"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /feeds/jobs.atom [get]
+func (h *FeedHandlers) GetJobsAtomHandler(ctx *gin.Context) {
+	f, ok := h.loadFeed(ctx, "atom")
+	if !ok {
+		return
+	}
+
+	type atomLink struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr,omitempty"`
+		Type string `xml:"type,attr,omitempty"`
+	}
+	type atomPerson struct {
+		Name string `xml:"name"`
+		URI  string `xml:"uri,omitempty"`
+	}
+	type atomText struct {
+		Type  string `xml:"type,attr"`
+		Value string `xml:",chardata"`
+	}
+	type atomCategory struct {
+		Term string `xml:"term,attr"`
+	}
+	type atomEntry struct {
+		Title      string         `xml:"title"`
+		ID         string         `xml:"id"`
+		Link       atomLink       `xml:"link"`
+		Published  string         `xml:"published"`
+		Updated    string         `xml:"updated"`
+		Author     atomPerson     `xml:"author"`
+		Summary    atomText       `xml:"summary"`
+		Content    atomText       `xml:"content"`
+		Categories []atomCategory `xml:"category"`
+	}
+	type atomFeed struct {
+		XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+		Title   string      `xml:"title"`
+		ID      string      `xml:"id"`
+		Updated string      `xml:"updated"`
+		Links   []atomLink  `xml:"link"`
+		Entries []atomEntry `xml:"entry"`
+	}
+
+	// Atom requires an update time even for an empty feed
+	updated := f.LastModified
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+	doc := atomFeed{
+		Title:   feedTitle,
+		ID:      f.SelfURL,
+		Updated: updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: f.SelfURL, Rel: "self", Type: "application/atom+xml"},
+			{Href: f.HomeURL, Rel: "alternate", Type: "text/html"},
+		},
+		Entries: make([]atomEntry, 0, len(f.Jobs)),
+	}
+	for _, job := range f.Jobs {
+		link := h.jobURL(job.ID)
+		categories := []atomCategory{}
+		for _, tag := range feedJobTags(job) {
+			categories = append(categories, atomCategory{Term: tag})
+		}
+		doc.Entries = append(doc.Entries, atomEntry{
+			Title:      feedJobTitle(job),
+			ID:         link,
+			Link:       atomLink{Href: link, Rel: "alternate", Type: "text/html"},
+			Published:  job.PublishedAt.UTC().Format(time.RFC3339),
+			Updated:    job.UpdatedAt.UTC().Format(time.RFC3339),
+			Author:     atomPerson{Name: job.CompanyName, URI: h.frontendURL + "/jobs/" + job.CompanyID},
+			Summary:    atomText{Type: "text", Value: feedJobSummary(job)},
+			Content:    atomText{Type: "text", Value: job.Description},
+			Categories: categories,
+		})
+	}
+	h.writeXML(ctx, "application/atom+xml; charset=utf-8", doc)
+}
+
+// @Summary JSON Feed of open jobs
+// @Description Lists the newest approved, open jobs as JSON Feed 1.1. This is a public endpoint, responses may be cached and support conditional GET with If-None-Match or If-Modified-Since.
+// @Tags Feeds
+// @Produce json
+// @Param jobType query []string false "Filter by job type(s)"
+// @Param experience query []string false "Filter by experience level(s)"
+// @Param companyId query string false "Filter by company ID"
+// @Param limit query uint false "Number of jobs" default(50)
+// @Success 200 {object} object "JSON Feed document"
+// @Success 304 "Not Modified"
+// @Failure 400 {object} object{error=string} "Bad Request"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /feeds/jobs.json [get]
+func (h *FeedHandlers) GetJobsJSONFeedHandler(ctx *gin.Context) {
+	f, ok := h.loadFeed(ctx, "json")
+	if !ok {
+		return
+	}
+
+	type jsonFeedAuthor struct {
+		Name string `json:"name"`
+		URL  string `json:"url,omitempty"`
+	}
+	type jsonFeedItem struct {
+		ID            string           `json:"id"`
+		URL           string           `json:"url"`
+		Title         string           `json:"title"`
+		Summary       string           `json:"summary"`
+		ContentText   string           `json:"content_text"`
+		DatePublished string           `json:"date_published"`
+		DateModified  string           `json:"date_modified"`
+		Authors       []jsonFeedAuthor `json:"authors"`
+		Tags          []string         `json:"tags"`
+	}
+	type jsonFeed struct {
+		Version     string         `json:"version"`
+		Title       string         `json:"title"`
+		HomePageURL string         `json:"home_page_url"`
+		FeedURL     string         `json:"feed_url"`
+		Description string         `json:"description"`
+		Items       []jsonFeedItem `json:"items"`
+	}
+
+	doc := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       feedTitle,
+		HomePageURL: f.HomeURL,
+		FeedURL:     f.SelfURL,
+		Description: "Approved job openings that are currently accepting applications",
+		Items:       make([]jsonFeedItem, 0, len(f.Jobs)),
+	}
+	for _, job := range f.Jobs {
+		doc.Items = append(doc.Items, jsonFeedItem{
+			ID:            strconv.FormatUint(uint64(job.ID), 10),
+			URL:           h.jobURL(job.ID),
+			Title:         feedJobTitle(job),
+			Summary:       feedJobSummary(job),
+			ContentText:   job.Description,
+			DatePublished: job.PublishedAt.UTC().Format(time.RFC3339),
+			DateModified:  job.UpdatedAt.UTC().Format(time.RFC3339),
+			Authors:       []jsonFeedAuthor{{Name: job.CompanyName, URL: h.frontendURL + "/jobs/" + job.CompanyID}},
+			Tags:          feedJobTags(job),
+		})
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		msg := "Failed to render feed"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+	ctx.Data(http.StatusOK, "application/feed+json; charset=utf-8", data)
+}
+
+// @Summary Sitemap of open jobs
+// @Description Lists the job board URLs of every approved, open job as a sitemap for search engines. This is a public endpoint, responses may be cached and support conditional GET with If-None-Match or If-Modified-Since.
+// @Tags Feeds
+// @Produce xml
+// @Success 200 {object} object "Sitemap document"
+// @Success 304 "Not Modified"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /feeds/sitemap.xml [get]
+func (h *FeedHandlers) GetJobsSitemapHandler(ctx *gin.Context) {
+	type sitemapJob struct {
+		ID        uint
+		UpdatedAt time.Time
+	}
+	jobs := []sitemapJob{}
+	if err := h.DB.Model(&model.Job{}).
+		Select("jobs.id, " + jobUpdatedAtExpr + " AS updated_at").
+		Scopes(listedJobsScope(time.Now())).
+		Order("jobs.id DESC").
+		Limit(sitemapMaxURLs).
+		Scan(&jobs).Error; err != nil {
+		msg := "Failed to fetch jobs"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	type sitemapURL struct {
+		Loc     string `xml:"loc"`
+		LastMod string `xml:"lastmod"`
+	}
+	type urlSet struct {
+		XMLName xml.Name     `xml:"urlset"`
+		XMLNS   string       `xml:"xmlns,attr"`
+		URLs    []sitemapURL `xml:"url"`
+	}
+
+	hash := sha256.New()
+	fmt.Fprint(hash, "sitemap\n")
+	doc := urlSet{
+		XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9",
+		URLs:  make([]sitemapURL, 0, len(jobs)),
+	}
+	lastModified := time.Time{}
+	for _, job := range jobs {
+		fmt.Fprintf(hash, "%d:%d\n", job.ID, job.UpdatedAt.UnixNano())
+		if job.UpdatedAt.After(lastModified) {
+			lastModified = job.UpdatedAt
+		}
+		doc.URLs = append(doc.URLs, sitemapURL{
+			Loc:     h.jobURL(job.ID),
+			LastMod: job.UpdatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+	if writeCacheValidators(ctx, `"`+hex.EncodeToString(hash.Sum(nil)[:16])+`"`, lastModified) {
+		return
+	}
+	h.writeXML(ctx, "application/xml; charset=utf-8", doc)
+}
+
+// loadFeed fetches the jobs of a feed request and sets the caching headers.
+// It writes the response itself when the input is invalid, the query fails or the client's copy is still fresh,
+// and reports whether the handler should render the feed.
+func (h *FeedHandlers) loadFeed(ctx *gin.Context, format string) (feed, bool) {
+	type FeedInput struct {
+		JobType    []string `form:"jobType" binding:"max=5,dive,max=32"`
+		Experience []string `form:"experience" binding:"max=5,dive,max=32"`
+		CompanyID  string   `form:"companyId" binding:"max=64"`
+		Limit      uint     `form:"limit" binding:"min=1,max=100"`
+	}
+	input := FeedInput{
+		Limit: 50,
+	}
+	if err := ctx.ShouldBindQuery(&input); err != nil {
+		msg := "Failed to bind input"
+		slog.Debug(msg, "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return feed{}, false
+	}
+
+	query := h.DB.Model(&model.Job{}).
+		Select("jobs.id, jobs.name, jobs.position, jobs.description, jobs.location, jobs.province, jobs.work_mode, jobs.job_type, jobs.experience, jobs.min_salary, jobs.max_salary, jobs.salary_currency, jobs.salary_period, jobs.salary_undisclosed, jobs.application_deadline, jobs.company_id, users.username AS company_name, "+
+			jobPublishedAtExpr+" AS published_at, "+jobUpdatedAtExpr+" AS updated_at").
+		Joins("INNER JOIN users ON users.id = jobs.company_id").
+		Scopes(listedJobsScope(time.Now()), helper.JobFilterScope(helper.JobFilter{
+			JobType:    input.JobType,
+			Experience: input.Experience,
+		}))
+	if input.CompanyID != "" {
+		query = query.Where("jobs.company_id = ?", input.CompanyID)
+	}
+
+	jobs := []feedJob{}
+	if err := query.Order("published_at DESC").Order("jobs.id DESC").Limit(int(input.Limit)).Scan(&jobs).Error; err != nil {
+		msg := "Failed to fetch jobs"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return feed{}, false
+	}
+
+	// The tag changes whenever a job enters, leaves or is edited in the listing
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\n", format)
+	f := feed{
+		Jobs:    jobs,
+		SelfURL: h.publicAPIURL + ctx.Request.URL.RequestURI(),
+		HomeURL: h.frontendURL + "/jobs",
+	}
+	for _, job := range jobs {
+		fmt.Fprintf(hash, "%d:%d\n", job.ID, job.UpdatedAt.UnixNano())
+		if job.UpdatedAt.After(f.LastModified) {
+			f.LastModified = job.UpdatedAt
+		}
+	}
+	f.ETag = `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+
+	if writeCacheValidators(ctx, f.ETag, f.LastModified) {
+		return feed{}, false
+	}
+	return f, true
+}
+
+// listedJobsScope limits a jobs query to the approved, open jobs anyone may see at the given time.
+func listedJobsScope(now time.Time) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(&model.Job{ApprovalStatus: model.JobApprovalAccepted}).
+			Where("jobs.is_open = ?", true).
+			Where("(jobs.application_deadline IS NULL OR jobs.application_deadline > ?)", now).
+			Scopes(helper.PublishedJobsScope(now))
+	}
+}
+
+// writeCacheValidators sets the caching headers of a public response and answers 304 if the client's copy is still fresh.
+// A zero lastModified leaves out Last-Modified. Reports whether the response has been written.
+func writeCacheValidators(ctx *gin.Context, etag string, lastModified time.Time) bool {
+	ctx.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(feedCacheMaxAge.Seconds())))
+	ctx.Header("ETag", etag)
+	if !lastModified.IsZero() {
+		ctx.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	if notModified(ctx.Request, etag, lastModified) {
+		ctx.Status(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+// notModified reports whether the client's cached copy matches the response.
+// If-None-Match takes precedence over If-Modified-Since, as required by RFC 9110.
+func notModified(req *http.Request, etag string, lastModified time.Time) bool {
+	if ifNoneMatch := req.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		for _, tag := range strings.Split(ifNoneMatch, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag {
+				return true
+			}
+		}
+		return false
+	}
+	if ifModifiedSince := req.Header.Get("If-Modified-Since"); ifModifiedSince != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(ifModifiedSince)
+		return err == nil && !lastModified.Truncate(time.Second).After(since)
+	}
+	return false
+}
+
+func (h *FeedHandlers) writeXML(ctx *gin.Context, contentType string, doc any) {
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		msg := "Failed to render feed"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+	ctx.Data(http.StatusOK, contentType, append([]byte(xml.Header), data...))
+}
+
+// jobURL links to the job on the job board.
+func (h *FeedHandlers) jobURL(jobID uint) string {
+	return frontendJobURL(h.frontendURL, jobID)
+}
+
+// frontendJobURL links to a job on the job board of the frontend at the given base URL.
+func frontendJobURL(frontendURL string, jobID uint) string {
+	return fmt.Sprintf("%s/jobs?id=%d", frontendURL, jobID)
+}
+
+func feedJobTitle(job feedJob) string {
+	return fmt.Sprintf("%s - %s at %s", job.Name, job.Position, job.CompanyName)
+}
+
+// feedJobSummary is a one line overview of where, how and for how much the job is.
+func feedJobSummary(job feedJob) string {
+	parts := []string{}
+	where := job.Location
+	if job.Province != "" && !strings.Contains(where, job.Province) {
+		where = strings.TrimPrefix(where+", "+job.Province, ", ")
+	}
+	if where != "" {
+		parts = append(parts, where)
+	}
+	parts = append(parts, string(job.WorkMode), string(job.JobType), string(job.Experience))
+	if job.SalaryUndisclosed {
+		parts = append(parts, "salary negotiable")
+	} else {
+		parts = append(parts, fmt.Sprintf("%d - %d %s per %s", job.MinSalary, job.MaxSalary, job.SalaryCurrency, job.SalaryPeriod))
+	}
+	if job.ApplicationDeadline != nil {
+		parts = append(parts, "apply by "+job.ApplicationDeadline.UTC().Format(time.DateOnly))
+	}
+	return strings.Join(parts, " | ")
+}
+
+func feedJobTags(job feedJob) []string {
+	tags := []string{string(job.JobType), string(job.Experience), string(job.WorkMode)}
+	if job.Province != "" {
+		tags = append(tags, job.Province)
+	}
+	return tags
+}
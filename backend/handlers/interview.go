@@ -0,0 +1,792 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"ku-work/backend/helper"
+	"ku-work/backend/model"
+	"ku-work/backend/services"
+	"ku-work/backend/services/email"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// MAX_INTERVIEW_SLOTS is the most slots a company can propose for one interview
+const MAX_INTERVIEW_SLOTS = 10
+
+type InterviewHandlers struct {
+	DB                              *gorm.DB
+	emailService                    *services.EmailService
+	interviewProposedEmailTemplate  *template.Template
+	interviewScheduledEmailTemplate *template.Template
+	interviewCancelledEmailTemplate *template.Template
+}
+
+func NewInterviewHandlers(db *gorm.DB, emailService *services.EmailService) (*InterviewHandlers, error) {
+	interviewProposedEmailTemplate, err := template.New("interview_proposed.tmpl").ParseFiles("email_templates/interview_proposed.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	interviewScheduledEmailTemplate, err := template.New("interview_scheduled.tmpl").ParseFiles("email_templates/interview_scheduled.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	interviewCancelledEmailTemplate, err := template.New("interview_cancelled.tmpl").ParseFiles("email_templates/interview_cancelled.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	return &InterviewHandlers{
+		DB:                              db,
+		emailService:                    emailService,
+		interviewProposedEmailTemplate:  interviewProposedEmailTemplate,
+		interviewScheduledEmailTemplate: interviewScheduledEmailTemplate,
+		interviewCancelledEmailTemplate: interviewCancelledEmailTemplate,
+	}, nil
+}
+
+// InterviewSlotInput is a time proposed for an interview. At least one of MeetingURL and Location is required.
+type InterviewSlotInput struct {
+	StartsAt        time.Time `json:"startsAt" binding:"required"`
+	DurationMinutes uint      `json:"durationMinutes" binding:"required,min=15,max=480"`
+	MeetingURL      string    `json:"meetingUrl" binding:"omitempty,http_url,max=512"`
+	Location        string    `json:"location" binding:"max=256"`
+}
+
+// ProposeInterviewInput is sent by companies to arrange an interview with an applicant.
+type ProposeInterviewInput struct {
+	Note  string               `json:"note" binding:"max=2048"`
+	Slots []InterviewSlotInput `json:"slots" binding:"required,min=1,dive"`
+}
+
+// CancelInterviewInput optionally tells the other party why an interview was cancelled.
+type CancelInterviewInput struct {
+	Reason string `json:"reason" binding:"max=1024"`
+}
+
+// interviewEmail is what is sent to the parties of an interview when it changes
+type interviewEmail int
+
+const (
+	interviewProposedEmail interviewEmail = iota
+	interviewScheduledEmail
+	interviewCancelledEmail
+)
+
+// newInterviewSlots validates proposed slots and builds them in the order given.
+// Returns an error message suitable for the client, or an empty string if the input is valid.
+func newInterviewSlots(inputs []InterviewSlotInput, now time.Time) ([]model.InterviewSlot, string) {
+	if len(inputs) > MAX_INTERVIEW_SLOTS {
+		return nil, fmt.Sprintf("at most %d interview slots can be proposed", MAX_INTERVIEW_SLOTS)
+	}
+	slots := make([]model.InterviewSlot, 0, len(inputs))
+	for i, input := range inputs {
+		slot := model.InterviewSlot{
+			StartsAt:        input.StartsAt.UTC(),
+			DurationMinutes: input.DurationMinutes,
+			MeetingURL:      strings.TrimSpace(input.MeetingURL),
+			Location:        strings.TrimSpace(input.Location),
+		}
+		if slot.MeetingURL == "" && slot.Location == "" {
+			return nil, fmt.Sprintf("slots[%d] needs a meeting link or a location", i)
+		}
+		if !slot.StartsAt.After(now) {
+			return nil, fmt.Sprintf("slots[%d] must start in the future", i)
+		}
+		for _, other := range slots {
+			if other.StartsAt.Equal(slot.StartsAt) {
+				return nil, fmt.Sprintf("slots[%d] starts at the same time as another slot", i)
+			}
+		}
+		slots = append(slots, slot)
+	}
+	return slots, ""
+}
+
+// markInvited records that a calendar invitation is sent for the interview.
+// The first invitation keeps sequence 0, every later one raises it so calendars replace the event.
+func markInvited(tx *gorm.DB, interview *model.Interview, now time.Time) error {
+	if interview.InvitedAt != nil {
+		interview.Sequence++
+	} else {
+		interview.InvitedAt = &now
+	}
+	return tx.Model(&model.Interview{}).Where("id = ?", interview.ID).
+		Updates(map[string]any{"sequence": interview.Sequence, "invited_at": interview.InvitedAt}).Error
+}
+
+// loadInterviews lists the interviews of a job application, newest first, with their slots in time order.
+func loadInterviews(db *gorm.DB, jobID uint, userID string) ([]model.Interview, error) {
+	interviews := []model.Interview{}
+	err := db.Preload("Slots", func(db *gorm.DB) *gorm.DB {
+		return db.Order("starts_at ASC")
+	}).
+		Where("job_id = ? AND user_id = ?", jobID, userID).
+		Order("created_at DESC").
+		Find(&interviews).Error
+	return interviews, err
+}
+
+// loadInterview gets an interview of a job with its slots, writing the error response if it cannot.
+func (h *InterviewHandlers) loadInterview(ctx *gin.Context, jobID uint) (*model.Interview, bool) {
+	interviewId64, err := strconv.ParseUint(ctx.Param("interviewId"), 10, 64)
+	if err != nil || interviewId64 <= 0 || interviewId64 > math.MaxUint32 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid interview ID"})
+		return nil, false
+	}
+	interview := model.Interview{}
+	if err := h.DB.Preload("Slots", func(db *gorm.DB) *gorm.DB {
+		return db.Order("starts_at ASC")
+	}).
+		Where("id = ? AND job_id = ?", interviewId64, jobID).
+		Take(&interview).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "interview not found"})
+		} else {
+			slog.Error("Failed to get interview", "error", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get interview"})
+		}
+		return nil, false
+	}
+	return &interview, true
+}
+
+// loadOwnedJob gets the job in the "id" parameter and checks that the user posted it, writing the error response if not.
+func (h *InterviewHandlers) loadOwnedJob(ctx *gin.Context, userId string) (*model.Job, bool) {
+	jobId64, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil || jobId64 <= 0 || jobId64 > math.MaxUint32 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid job ID"})
+		return nil, false
+	}
+	job := model.Job{}
+	if err := h.DB.Select("id", "company_id").Take(&job, jobId64).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+		} else {
+			slog.Error("Failed to get job", "error", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get job"})
+		}
+		return nil, false
+	}
+	if job.CompanyID != userId {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "forbidden: only the company that posted this job"})
+		return nil, false
+	}
+	return &job, true
+}
+
+// loadActiveApplication gets a job application that interviews can still be arranged for, writing the error response if there is none.
+func (h *InterviewHandlers) loadActiveApplication(ctx *gin.Context, jobID uint, userID string) (*model.JobApplication, bool) {
+	jobApplication := model.JobApplication{JobID: jobID, UserID: userID}
+	if err := h.DB.Take(&jobApplication).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "job application not found"})
+		} else {
+			slog.Error("Failed to get job application", "error", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get job application"})
+		}
+		return nil, false
+	}
+	switch jobApplication.Status {
+	case model.JobApplicationWithdrawn:
+		ctx.JSON(http.StatusConflict, gin.H{"error": "the application has been withdrawn"})
+		return nil, false
+	case model.JobApplicationRejected:
+		ctx.JSON(http.StatusConflict, gin.H{"error": "the application has been rejected"})
+		return nil, false
+	}
+	return &jobApplication, true
+}
+
+// @Summary Propose an interview to an applicant
+// @Description Lets the company that posted a job propose one or more interview slots to an applicant. Each slot has a start time, a duration and a meeting link, a location or both.
+// @Description The student is emailed the slots and picks one. If a single slot is proposed the interview is scheduled in it straight away, and both parties are emailed a calendar invitation.
+// @Tags Interviews
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path uint true "Job ID"
+// @Param studentUserId path string true "Student User ID"
+// @Param interview body handlers.ProposeInterviewInput true "Proposed slots"
+// @Success 201 {object} model.Interview
+// @Failure 400 {object} object{error=string} "Bad Request: Invalid ID or input"
+// @Failure 401 {object} object{error=string} "Unauthorized"
+// @Failure 403 {object} object{error=string} "Forbidden: Only the company that posted the job"
+// @Failure 404 {object} object{error=string} "Not Found: Job or application not found"
+// @Failure 409 {object} object{error=string} "Conflict: The application was withdrawn or rejected"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /jobs/{id}/applicants/{studentUserId}/interviews [post]
+func (h *InterviewHandlers) ProposeInterviewHandler(ctx *gin.Context) {
+	userId := ctx.MustGet("userID").(string)
+
+	job, ok := h.loadOwnedJob(ctx, userId)
+	if !ok {
+		return
+	}
+
+	input := ProposeInterviewInput{}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		slog.Debug("Failed to bind interview request", "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	now := time.Now()
+	slots, msg := newInterviewSlots(input.Slots, now)
+	if msg != "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	jobApplication, ok := h.loadActiveApplication(ctx, job.ID, ctx.Param("studentUserId"))
+	if !ok {
+		return
+	}
+
+	interview := model.Interview{
+		JobID:  jobApplication.JobID,
+		UserID: jobApplication.UserID,
+		Status: model.InterviewProposed,
+		Note:   strings.TrimSpace(input.Note),
+		Slots:  slots,
+	}
+	if err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&interview).Error; err != nil {
+			return err
+		}
+		if len(interview.Slots) != 1 {
+			return nil
+		}
+		interview.Status = model.InterviewScheduled
+		interview.SelectedSlotID = &interview.Slots[0].ID
+		if err := tx.Model(&interview).Updates(map[string]any{
+			"status":           interview.Status,
+			"selected_slot_id": interview.SelectedSlotID,
+		}).Error; err != nil {
+			return err
+		}
+		return markInvited(tx, &interview, now)
+	}); err != nil {
+		msg := "Failed to create interview"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, interview)
+
+	if interview.Status == model.InterviewScheduled {
+		go h.sendInterviewEmails(interview.ID, interviewScheduledEmail, "")
+	} else {
+		go h.sendInterviewEmails(interview.ID, interviewProposedEmail, "")
+	}
+}
+
+// @Summary List the interviews of a job application
+// @Description Lists the interviews arranged for an application, newest first, with their slots. Only the company that posted the job can list them.
+// @Tags Interviews
+// @Security BearerAuth
+// @Produce json
+// @Param id path uint true "Job ID"
+// @Param studentUserId path string true "Student User ID"
+// @Success 200 {object} object{interviews=[]model.Interview}
+// @Failure 400 {object} object{error=string} "Bad Request: Invalid job ID"
+// @Failure 401 {object} object{error=string} "Unauthorized"
+// @Failure 403 {object} object{error=string} "Forbidden: Only the company that posted the job"
+// @Failure 404 {object} object{error=string} "Not Found: Job not found"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /jobs/{id}/applicants/{studentUserId}/interviews [get]
+func (h *InterviewHandlers) GetJobApplicationInterviewsHandler(ctx *gin.Context) {
+	userId := ctx.MustGet("userID").(string)
+
+	job, ok := h.loadOwnedJob(ctx, userId)
+	if !ok {
+		return
+	}
+
+	interviews, err := loadInterviews(h.DB, job.ID, ctx.Param("studentUserId"))
+	if err != nil {
+		msg := "Failed to get interviews"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"interviews": interviews})
+}
+
+// @Summary Reschedule an interview
+// @Description Lets the company that posted a job move an interview to a new time. The new slot replaces the proposed ones and the interview is scheduled in it.
+// @Description Both parties are emailed an updated calendar invitation that replaces the one they had, or a first one if the interview was not scheduled yet.
+// @Tags Interviews
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path uint true "Job ID"
+// @Param interviewId path uint true "Interview ID"
+// @Param slot body handlers.InterviewSlotInput true "New slot"
+// @Success 200 {object} model.Interview
+// @Failure 400 {object} object{error=string} "Bad Request: Invalid ID or input"
+// @Failure 401 {object} object{error=string} "Unauthorized"
+// @Failure 403 {object} object{error=string} "Forbidden: Only the company that posted the job"
+// @Failure 404 {object} object{error=string} "Not Found: Job or interview not found"
+// @Failure 409 {object} object{error=string} "Conflict: The interview was cancelled, or the application withdrawn or rejected"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /jobs/{id}/interviews/{interviewId}/reschedule [post]
+func (h *InterviewHandlers) RescheduleInterviewHandler(ctx *gin.Context) {
+	userId := ctx.MustGet("userID").(string)
+
+	job, ok := h.loadOwnedJob(ctx, userId)
+	if !ok {
+		return
+	}
+
+	input := InterviewSlotInput{}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		slog.Debug("Failed to bind interview request", "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	now := time.Now()
+	slots, msg := newInterviewSlots([]InterviewSlotInput{input}, now)
+	if msg != "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": strings.TrimPrefix(msg, "slots[0] ")})
+		return
+	}
+
+	interview, ok := h.loadInterview(ctx, job.ID)
+	if !ok {
+		return
+	}
+	if interview.Status == model.InterviewCancelled {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "the interview has been cancelled"})
+		return
+	}
+	if _, ok := h.loadActiveApplication(ctx, interview.JobID, interview.UserID); !ok {
+		return
+	}
+
+	if err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(interview).Update("selected_slot_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("interview_id = ?", interview.ID).Delete(&model.InterviewSlot{}).Error; err != nil {
+			return err
+		}
+		slot := slots[0]
+		slot.InterviewID = interview.ID
+		if err := tx.Create(&slot).Error; err != nil {
+			return err
+		}
+		interview.Slots = []model.InterviewSlot{slot}
+		interview.Status = model.InterviewScheduled
+		interview.SelectedSlotID = &slot.ID
+		if err := tx.Model(interview).Updates(map[string]any{
+			"status":           interview.Status,
+			"selected_slot_id": interview.SelectedSlotID,
+		}).Error; err != nil {
+			return err
+		}
+		return markInvited(tx, interview, now)
+	}); err != nil {
+		msg := "Failed to reschedule interview"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, interview)
+
+	go h.sendInterviewEmails(interview.ID, interviewScheduledEmail, "")
+}
+
+// @Summary Cancel an interview as the company
+// @Description Lets the company that posted a job cancel an interview. The student is emailed about it, and if the interview was scheduled both parties are emailed a calendar cancellation.
+// @Tags Interviews
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path uint true "Job ID"
+// @Param interviewId path uint true "Interview ID"
+// @Param reason body handlers.CancelInterviewInput false "Why the interview is cancelled"
+// @Success 200 {object} model.Interview
+// @Failure 400 {object} object{error=string} "Bad Request: Invalid ID or input"
+// @Failure 401 {object} object{error=string} "Unauthorized"
+// @Failure 403 {object} object{error=string} "Forbidden: Only the company that posted the job"
+// @Failure 404 {object} object{error=string} "Not Found: Job or interview not found"
+// @Failure 409 {object} object{error=string} "Conflict: The interview was already cancelled"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /jobs/{id}/interviews/{interviewId}/cancel [post]
+func (h *InterviewHandlers) CompanyCancelInterviewHandler(ctx *gin.Context) {
+	userId := ctx.MustGet("userID").(string)
+
+	job, ok := h.loadOwnedJob(ctx, userId)
+	if !ok {
+		return
+	}
+	interview, ok := h.loadInterview(ctx, job.ID)
+	if !ok {
+		return
+	}
+	h.cancelInterview(ctx, interview, "the company")
+}
+
+// @Summary List the interviews of an own job application
+// @Description Lets a student list the interviews arranged for their application to a job, newest first, with the proposed slots.
+// @Tags Interviews
+// @Security BearerAuth
+// @Produce json
+// @Param jobId path uint true "Job ID"
+// @Success 200 {object} object{interviews=[]model.Interview}
+// @Failure 400 {object} object{error=string} "Bad Request: Invalid job ID"
+// @Failure 401 {object} object{error=string} "Unauthorized"
+// @Failure 404 {object} object{error=string} "Not Found: Job application not found"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /applications/{jobId}/interviews [get]
+func (h *InterviewHandlers) GetOwnInterviewsHandler(ctx *gin.Context) {
+	userId := ctx.MustGet("userID").(string)
+
+	jobId64, err := strconv.ParseUint(ctx.Param("jobId"), 10, 64)
+	if err != nil || jobId64 <= 0 || jobId64 > math.MaxUint32 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid job ID"})
+		return
+	}
+
+	var count int64
+	if err := h.DB.Model(&model.JobApplication{}).
+		Where("job_id = ? AND user_id = ?", jobId64, userId).
+		Count(&count).Error; err != nil {
+		slog.Error("Failed to get job application", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get job application"})
+		return
+	}
+	if count == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "job application not found"})
+		return
+	}
+
+	interviews, err := loadInterviews(h.DB, uint(jobId64), userId)
+	if err != nil {
+		msg := "Failed to get interviews"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"interviews": interviews})
+}
+
+// @Summary Pick an interview slot
+// @Description Lets a student pick one of the slots proposed for an interview. The interview is scheduled in it and both parties are emailed a calendar invitation.
+// @Description Students can pick another of the proposed slots later, in which case the invitation is updated.
+// @Tags Interviews
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param jobId path uint true "Job ID"
+// @Param interviewId path uint true "Interview ID"
+// @Param slot body object{slotId=uint} true "Picked slot"
+// @Success 200 {object} model.Interview
+// @Failure 400 {object} object{error=string} "Bad Request: Invalid ID or input, unknown or past slot"
+// @Failure 401 {object} object{error=string} "Unauthorized"
+// @Failure 404 {object} object{error=string} "Not Found: Interview not found"
+// @Failure 409 {object} object{error=string} "Conflict: The interview was cancelled, or the application withdrawn or rejected"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /applications/{jobId}/interviews/{interviewId}/select [post]
+func (h *InterviewHandlers) SelectInterviewSlotHandler(ctx *gin.Context) {
+	userId := ctx.MustGet("userID").(string)
+
+	jobId64, err := strconv.ParseUint(ctx.Param("jobId"), 10, 64)
+	if err != nil || jobId64 <= 0 || jobId64 > math.MaxUint32 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid job ID"})
+		return
+	}
+
+	type SelectSlotInput struct {
+		SlotID uint `json:"slotId" binding:"required"`
+	}
+	input := SelectSlotInput{}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		slog.Debug("Failed to bind interview request", "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	interview, ok := h.loadInterview(ctx, uint(jobId64))
+	if !ok {
+		return
+	}
+	if interview.UserID != userId {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "interview not found"})
+		return
+	}
+	if interview.Status == model.InterviewCancelled {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "the interview has been cancelled"})
+		return
+	}
+	var slot *model.InterviewSlot
+	for i := range interview.Slots {
+		if interview.Slots[i].ID == input.SlotID {
+			slot = &interview.Slots[i]
+		}
+	}
+	if slot == nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "the interview has no such slot"})
+		return
+	}
+	if interview.SelectedSlotID != nil && *interview.SelectedSlotID == slot.ID {
+		ctx.JSON(http.StatusOK, interview)
+		return
+	}
+	now := time.Now()
+	if !slot.StartsAt.After(now) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "the slot has already started"})
+		return
+	}
+	if _, ok := h.loadActiveApplication(ctx, interview.JobID, interview.UserID); !ok {
+		return
+	}
+
+	// Only update if the interview was not cancelled meanwhile
+	errCancelled := errors.New("interview cancelled")
+	if err := h.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Interview{}).
+			Where("id = ? AND status <> ?", interview.ID, model.InterviewCancelled).
+			Updates(map[string]any{"status": model.InterviewScheduled, "selected_slot_id": slot.ID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errCancelled
+		}
+		interview.Status = model.InterviewScheduled
+		interview.SelectedSlotID = &slot.ID
+		return markInvited(tx, interview, now)
+	}); err != nil {
+		if err == errCancelled {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "the interview has been cancelled"})
+			return
+		}
+		msg := "Failed to schedule interview"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, interview)
+
+	go h.sendInterviewEmails(interview.ID, interviewScheduledEmail, "")
+}
+
+// @Summary Cancel an interview as the student
+// @Description Lets a student cancel an interview arranged for their application. The company is emailed about it, and if the interview was scheduled both parties are emailed a calendar cancellation.
+// @Tags Interviews
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param jobId path uint true "Job ID"
+// @Param interviewId path uint true "Interview ID"
+// @Param reason body handlers.CancelInterviewInput false "Why the interview is cancelled"
+// @Success 200 {object} model.Interview
+// @Failure 400 {object} object{error=string} "Bad Request: Invalid ID or input"
+// @Failure 401 {object} object{error=string} "Unauthorized"
+// @Failure 404 {object} object{error=string} "Not Found: Interview not found"
+// @Failure 409 {object} object{error=string} "Conflict: The interview was already cancelled"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /applications/{jobId}/interviews/{interviewId}/cancel [post]
+func (h *InterviewHandlers) StudentCancelInterviewHandler(ctx *gin.Context) {
+	userId := ctx.MustGet("userID").(string)
+
+	jobId64, err := strconv.ParseUint(ctx.Param("jobId"), 10, 64)
+	if err != nil || jobId64 <= 0 || jobId64 > math.MaxUint32 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid job ID"})
+		return
+	}
+	interview, ok := h.loadInterview(ctx, uint(jobId64))
+	if !ok {
+		return
+	}
+	if interview.UserID != userId {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "interview not found"})
+		return
+	}
+	h.cancelInterview(ctx, interview, "the applicant")
+}
+
+// cancelInterview cancels an interview for either party and writes the response.
+// cancelledBy names the party in the emails.
+func (h *InterviewHandlers) cancelInterview(ctx *gin.Context, interview *model.Interview, cancelledBy string) {
+	// The reason is optional, and so is the body
+	input := CancelInterviewInput{}
+	if err := ctx.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		slog.Debug("Failed to bind interview request", "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if interview.Status == model.InterviewCancelled {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "the interview has already been cancelled"})
+		return
+	}
+
+	errCancelled := errors.New("interview cancelled")
+	if err := h.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Interview{}).
+			Where("id = ? AND status <> ?", interview.ID, model.InterviewCancelled).
+			Updates(map[string]any{"status": model.InterviewCancelled, "cancel_reason": strings.TrimSpace(input.Reason)})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errCancelled
+		}
+		interview.Status = model.InterviewCancelled
+		interview.CancelReason = strings.TrimSpace(input.Reason)
+		// Only interviews the parties were invited to are in their calendars
+		if interview.InvitedAt == nil {
+			return nil
+		}
+		return markInvited(tx, interview, time.Now())
+	}); err != nil {
+		if err == errCancelled {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "the interview has already been cancelled"})
+			return
+		}
+		msg := "Failed to cancel interview"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, interview)
+
+	go h.sendInterviewEmails(interview.ID, interviewCancelledEmail, cancelledBy)
+}
+
+// sendInterviewEmails tells the parties of an interview what changed.
+// Proposals only go to the student, who has to pick a slot. Scheduled and cancelled interviews go to both,
+// with a calendar invitation or cancellation if the interview is in their calendars.
+func (h *InterviewHandlers) sendInterviewEmails(interviewID uint, kind interviewEmail, cancelledBy string) {
+	interview := model.Interview{}
+	if err := h.DB.Preload("Slots", func(db *gorm.DB) *gorm.DB {
+		return db.Order("starts_at ASC")
+	}).Take(&interview, interviewID).Error; err != nil {
+		slog.Warn("Failed to fetch interview for emails", "interview_id", interviewID, "error", err)
+		return
+	}
+
+	type Party struct {
+		Name  string
+		Email string
+	}
+	type Context struct {
+		Recipient   Party
+		Company     Party
+		Student     Party
+		Job         model.Job
+		Interview   model.Interview
+		Slots       []model.InterviewSlot
+		Slot        *model.InterviewSlot
+		Rescheduled bool
+		CancelledBy string
+	}
+	context := Context{Interview: interview, CancelledBy: cancelledBy}
+	if err := h.DB.Select("id", "name", "position", "company_id").Take(&context.Job, interview.JobID).Error; err != nil {
+		slog.Warn("Failed to fetch job for interview emails", "interview_id", interviewID, "error", err)
+		return
+	}
+	if err := h.DB.Model(&model.Company{}).
+		Joins("INNER JOIN users ON users.id = companies.user_id").
+		Select("users.username AS name, companies.email").
+		Where("companies.user_id = ?", context.Job.CompanyID).
+		Take(&context.Company).Error; err != nil {
+		slog.Warn("Failed to fetch company for interview emails", "interview_id", interviewID, "error", err)
+		return
+	}
+	if err := h.DB.Model(&model.GoogleOAuthDetails{}).
+		Select("CONCAT(first_name, ' ', last_name) AS name, email").
+		Where("user_id = ?", interview.UserID).
+		Take(&context.Student).Error; err != nil {
+		slog.Warn("Failed to fetch applicant for interview emails", "interview_id", interviewID, "error", err)
+		return
+	}
+
+	// Convert to Bangkok timezone (GMT+7)
+	bangkokLocation, _ := time.LoadLocation("Asia/Bangkok")
+	for _, slot := range interview.Slots {
+		slot.StartsAt = slot.StartsAt.In(bangkokLocation)
+		context.Slots = append(context.Slots, slot)
+	}
+	if selected := interview.SelectedSlot(); selected != nil {
+		slot := *selected
+		slot.StartsAt = slot.StartsAt.In(bangkokLocation)
+		context.Slot = &slot
+	}
+	context.Rescheduled = interview.Sequence > 0 && kind == interviewScheduledEmail
+
+	var tpl *template.Template
+	var subject string
+	recipients := []Party{context.Student, context.Company}
+	switch kind {
+	case interviewProposedEmail:
+		tpl = h.interviewProposedEmailTemplate
+		subject = fmt.Sprintf("[KU-Work] Interview Invitation for %s - %s", context.Job.Name, context.Job.Position)
+		recipients = []Party{context.Student}
+	case interviewScheduledEmail:
+		tpl = h.interviewScheduledEmailTemplate
+		subject = fmt.Sprintf("[KU-Work] Interview Scheduled for %s - %s", context.Job.Name, context.Job.Position)
+		if context.Rescheduled {
+			subject = fmt.Sprintf("[KU-Work] Interview Rescheduled for %s - %s", context.Job.Name, context.Job.Position)
+		}
+	case interviewCancelledEmail:
+		tpl = h.interviewCancelledEmailTemplate
+		subject = fmt.Sprintf("[KU-Work] Interview Cancelled for %s - %s", context.Job.Name, context.Job.Position)
+	}
+
+	var attachments []email.Attachment
+	if selected := interview.SelectedSlot(); selected != nil && interview.InvitedAt != nil && kind != interviewProposedEmail {
+		method := helper.CalendarRequest
+		if kind == interviewCancelledEmail {
+			method = helper.CalendarCancel
+		}
+		event := helper.CalendarEvent{
+			UID:       interview.CalendarUID(),
+			Sequence:  interview.Sequence,
+			Method:    method,
+			Stamp:     time.Now(),
+			Start:     selected.StartsAt,
+			End:       selected.EndsAt(),
+			Summary:   fmt.Sprintf("Interview: %s - %s at %s", context.Job.Name, context.Job.Position, context.Company.Name),
+			Location:  selected.Location,
+			URL:       selected.MeetingURL,
+			Organizer: helper.CalendarAttendee{Name: context.Company.Name, Email: context.Company.Email},
+			Attendees: []helper.CalendarAttendee{{Name: context.Student.Name, Email: context.Student.Email}},
+		}
+		if selected.MeetingURL != "" {
+			event.Description = "Join online: " + selected.MeetingURL
+		}
+		if selected.Location == "" {
+			event.Location = selected.MeetingURL
+		}
+		attachments = append(attachments, email.Attachment{
+			Filename:    "interview.ics",
+			ContentType: fmt.Sprintf("text/calendar; charset=UTF-8; method=%s", method),
+			Content:     event.ICS(),
+		})
+	}
+
+	for _, recipient := range recipients {
+		context.Recipient = recipient
+		var body bytes.Buffer
+		if err := tpl.Execute(&body, context); err != nil {
+			slog.Warn("Failed to render interview email", "interview_id", interviewID, "error", err)
+			return
+		}
+		_ = h.emailService.SendTo(recipient.Email, subject, body.String(), attachments...)
+	}
+}
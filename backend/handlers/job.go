@@ -5,17 +5,21 @@ import (
 	"fmt"
 	"html/template"
 	"io"
+	"ku-work/backend/database"
 	"ku-work/backend/helper"
 	"ku-work/backend/model"
 	"ku-work/backend/services"
 	"log/slog"
 	"math"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -24,10 +28,11 @@ type JobHandlers struct {
 	FileHandlers                         *FileHandlers
 	aiService                            *services.AIService
 	emailService                         *services.EmailService
+	analyticsService                     *services.JobAnalyticsService
 	jobApprovalStatusUpdateEmailTemplate *template.Template
 }
 
-func NewJobHandlers(db *gorm.DB, aiService *services.AIService, emailService *services.EmailService) (*JobHandlers, error) {
+func NewJobHandlers(db *gorm.DB, redisClient *redis.Client, aiService *services.AIService, emailService *services.EmailService) (*JobHandlers, error) {
 	jobApprovalStatusUpdateEmailTemplate, err := template.New("job_approval_status_update.tmpl").ParseFiles("email_templates/job_approval_status_update.tmpl")
 	if err != nil {
 		return nil, err
@@ -37,38 +42,75 @@ func NewJobHandlers(db *gorm.DB, aiService *services.AIService, emailService *se
 		FileHandlers:                         NewFileHandlers(db),
 		aiService:                            aiService,
 		emailService:                         emailService,
+		analyticsService:                     services.NewJobAnalyticsService(db, redisClient),
 		jobApprovalStatusUpdateEmailTemplate: jobApprovalStatusUpdateEmailTemplate,
 	}, nil
 }
 
 // CreateJobInput defines the request body for creating a new job.
 type CreateJobInput struct {
-	Name                string `json:"name" binding:"required,max=128"`
-	Position            string `json:"position" binding:"required,max=128"`
-	Duration            string `json:"duration" binding:"required,max=128"`
-	Description         string `json:"description" binding:"required,max=16384"`
-	Location            string `json:"location" binding:"required,max=128"`
-	JobType             string `json:"jobType" binding:"required,oneof='fulltime' 'parttime' 'contract' 'casual' 'internship'"`
-	Experience          string `json:"experience" binding:"required,oneof='newgrad' 'junior' 'senior' 'manager' 'internship'"`
-	MinSalary           *uint  `json:"minSalary" binding:"required"`
-	MaxSalary           *uint  `json:"maxSalary" binding:"required"`
-	Open                bool   `json:"open"`
-	NotifyOnApplication *bool  `json:"notifyOnApplication"`
+	Name                    string             `json:"name" binding:"required,max=128"`
+	Position                string             `json:"position" binding:"required,max=128"`
+	Duration                string             `json:"duration" binding:"required,max=128"`
+	Description             string             `json:"description" binding:"required,max=16384"`
+	Location                string             `json:"location" binding:"required,max=128"`
+	WorkMode                string             `json:"workMode" binding:"omitempty,oneof=onsite hybrid remote"`
+	Province                string             `json:"province" binding:"max=64"`
+	District                string             `json:"district" binding:"max=64"`
+	JobType                 string             `json:"jobType" binding:"required,oneof='fulltime' 'parttime' 'contract' 'casual' 'internship'"`
+	Experience              string             `json:"experience" binding:"required,oneof='newgrad' 'junior' 'senior' 'manager' 'internship'"`
+	MinSalary               *uint              `json:"minSalary" binding:"required_unless=SalaryUndisclosed true"`
+	MaxSalary               *uint              `json:"maxSalary" binding:"required_unless=SalaryUndisclosed true"`
+	SalaryCurrency          string             `json:"salaryCurrency" binding:"omitempty,iso4217"`
+	SalaryPeriod            string             `json:"salaryPeriod" binding:"omitempty,oneof=hour day month year"`
+	SalaryUndisclosed       bool               `json:"salaryUndisclosed"`
+	Open                    bool               `json:"open"`
+	Openings                *uint              `json:"openings" binding:"omitempty,min=1,max=1000"`
+	NotifyPendingWhenFilled bool               `json:"notifyPendingWhenFilled"`
+	NotifyOnApplication     *bool              `json:"notifyOnApplication"`
+	ApplicationDeadline     *time.Time         `json:"applicationDeadline"`
+	PublishAt               *time.Time         `json:"publishAt"`
+	UnpublishAt             *time.Time         `json:"unpublishAt"`
+	RequiredSkills          []string           `json:"requiredSkills" binding:"max=32,dive,max=64"`
+	NiceToHaveSkills        []string           `json:"niceToHaveSkills" binding:"max=32,dive,max=64"`
+	Questions               []JobQuestionInput `json:"questions" binding:"max=20,dive"`
+	// Pipeline is the job's hiring stages in order, the default pipeline is used if it is empty
+	Pipeline []PipelineStageInput `json:"pipeline" binding:"max=16,dive"`
 }
 
 // EditJobInput defines the request body for editing an existing job.
 type EditJobInput struct {
-	Name                *string `json:"name" binding:"omitempty,max=128"`
-	Position            *string `json:"position" binding:"omitempty,max=128"`
-	Duration            *string `json:"duration" binding:"omitempty,max=128"`
-	Description         *string `json:"description" binding:"omitempty,max=16384"`
-	Location            *string `json:"location" binding:"omitempty,max=128"`
-	JobType             *string `json:"jobType" binding:"omitempty,oneof='fulltime' 'parttime' 'contract' 'casual' 'internship'"`
-	Experience          *string `json:"experience" binding:"omitempty,oneof='newgrad' 'junior' 'senior' 'manager' 'internship'"`
-	MinSalary           *uint   `json:"minSalary" binding:"omitempty"`
-	MaxSalary           *uint   `json:"maxSalary" binding:"omitempty"`
-	Open                *bool   `json:"open" binding:"omitempty"`
-	NotifyOnApplication *bool   `json:"notifyOnApplication" binding:"omitempty"`
+	Name              *string `json:"name" binding:"omitempty,max=128"`
+	Position          *string `json:"position" binding:"omitempty,max=128"`
+	Duration          *string `json:"duration" binding:"omitempty,max=128"`
+	Description       *string `json:"description" binding:"omitempty,max=16384"`
+	Location          *string `json:"location" binding:"omitempty,max=128"`
+	WorkMode          *string `json:"workMode" binding:"omitempty,oneof=onsite hybrid remote"`
+	Province          *string `json:"province" binding:"omitempty,max=64"`
+	District          *string `json:"district" binding:"omitempty,max=64"`
+	JobType           *string `json:"jobType" binding:"omitempty,oneof='fulltime' 'parttime' 'contract' 'casual' 'internship'"`
+	Experience        *string `json:"experience" binding:"omitempty,oneof='newgrad' 'junior' 'senior' 'manager' 'internship'"`
+	MinSalary         *uint   `json:"minSalary" binding:"omitempty"`
+	MaxSalary         *uint   `json:"maxSalary" binding:"omitempty"`
+	SalaryCurrency    *string `json:"salaryCurrency" binding:"omitempty,iso4217"`
+	SalaryPeriod      *string `json:"salaryPeriod" binding:"omitempty,oneof=hour day month year"`
+	SalaryUndisclosed *bool   `json:"salaryUndisclosed"`
+	Open              *bool   `json:"open" binding:"omitempty"`
+	// Openings of 0 removes the hiring target
+	Openings                *uint      `json:"openings" binding:"omitempty,max=1000"`
+	NotifyPendingWhenFilled *bool      `json:"notifyPendingWhenFilled"`
+	NotifyOnApplication     *bool      `json:"notifyOnApplication" binding:"omitempty"`
+	ApplicationDeadline     *time.Time `json:"applicationDeadline" binding:"omitempty"`
+	// ClearApplicationDeadline removes the deadline, so the job stays open until it is closed
+	ClearApplicationDeadline bool       `json:"clearApplicationDeadline"`
+	PublishAt                *time.Time `json:"publishAt" binding:"omitempty"`
+	UnpublishAt              *time.Time `json:"unpublishAt" binding:"omitempty"`
+	RequiredSkills           *[]string  `json:"requiredSkills" binding:"omitempty,max=32,dive,max=64"`
+	NiceToHaveSkills         *[]string  `json:"niceToHaveSkills" binding:"omitempty,max=32,dive,max=64"`
+	// Questions replaces all of the job's screening questions
+	Questions *[]JobQuestionInput `json:"questions" binding:"omitempty,max=20,dive"`
+	// Pipeline replaces the job's hiring stages, an empty list goes back to the default pipeline
+	Pipeline *[]PipelineStageInput `json:"pipeline" binding:"omitempty,max=16,dive"`
 }
 
 // ApproveJobInput defines the request body for approving a job.
@@ -79,34 +121,58 @@ type ApproveJobInput struct {
 
 // JobResponse defines the structure for a single job listing in API responses.
 type JobResponse struct {
-	ID                  uint      `json:"id"`
-	CreatedAt           time.Time `json:"createdAt"`
-	UpdatedAt           time.Time `json:"updatedAt"`
-	Name                string    `json:"name"`
-	CompanyID           string    `json:"companyId"`
-	PhotoID             string    `json:"photoId"`
-	BannerID            string    `json:"bannerId"`
-	CompanyName         string    `json:"companyName"`
-	Position            string    `json:"position"`
-	Duration            string    `json:"duration"`
-	Description         string    `json:"description"`
-	Location            string    `json:"location"`
-	JobType             string    `json:"jobType"`
-	Experience          string    `json:"experience"`
-	MinSalary           uint      `json:"minSalary"`
-	MaxSalary           uint      `json:"maxSalary"`
-	ApprovalStatus      string    `json:"approvalStatus"`
-	IsOpen              bool      `json:"open"`
-	Applied             bool      `json:"applied"`
-	NotifyOnApplication bool      `json:"notifyOnApplication"`
+	ID                      uint                `json:"id"`
+	CreatedAt               time.Time           `json:"createdAt"`
+	UpdatedAt               time.Time           `json:"updatedAt"`
+	Name                    string              `json:"name"`
+	CompanyID               string              `json:"companyId"`
+	PhotoID                 string              `json:"photoId"`
+	BannerID                string              `json:"bannerId"`
+	CompanyName             string              `json:"companyName"`
+	Position                string              `json:"position"`
+	Duration                string              `json:"duration"`
+	Description             string              `json:"description"`
+	Location                string              `json:"location"`
+	WorkMode                string              `json:"workMode"`
+	Province                string              `json:"province"`
+	District                string              `json:"district"`
+	Latitude                *float64            `json:"latitude"`
+	Longitude               *float64            `json:"longitude"`
+	JobType                 string              `json:"jobType"`
+	Experience              string              `json:"experience"`
+	MinSalary               uint                `json:"minSalary"`
+	MaxSalary               uint                `json:"maxSalary"`
+	SalaryCurrency          string              `json:"salaryCurrency"`
+	SalaryPeriod            string              `json:"salaryPeriod"`
+	SalaryUndisclosed       bool                `json:"salaryUndisclosed"`
+	ApprovalStatus          string              `json:"approvalStatus"`
+	IsOpen                  bool                `json:"open"`
+	Openings                *uint               `json:"openings"`
+	NotifyPendingWhenFilled bool                `json:"notifyPendingWhenFilled"`
+	ApplicationDeadline     *time.Time          `json:"applicationDeadline"`
+	PublishAt               *time.Time          `json:"publishAt"`
+	UnpublishAt             *time.Time          `json:"unpublishAt"`
+	Applied                 bool                `json:"applied"`
+	Saved                   bool                `json:"saved"`
+	NotifyOnApplication     bool                `json:"notifyOnApplication"`
+	Relevance               *float64            `json:"relevance,omitempty"`
+	ApplicationCount        int64               `json:"-"`
+	Skills                  []JobSkillTag       `gorm:"-" json:"skills"`
+	Questions               []model.JobQuestion `gorm:"-" json:"questions,omitempty"`
+	// Pipeline is only shown to the owning company
+	Pipeline []model.PipelineStage `gorm:"-" json:"pipeline,omitempty"`
 }
 
 // JobWithStatsResponse extends JobResponse with application statistics.
 type JobWithStatsResponse struct {
 	JobResponse
-	Pending  int64 `json:"pending"`
-	Accepted int64 `json:"accepted"`
-	Rejected int64 `json:"rejected"`
+	// Pending, Accepted and Rejected count applications by the status students see, Stages by pipeline stage.
+	// Withdrawn applications are not counted.
+	Pending     int64                                    `json:"pending"`
+	Accepted    int64                                    `json:"accepted"`
+	Rejected    int64                                    `json:"rejected"`
+	Stages      []StageCount                             `gorm:"-" json:"stages"`
+	OwnPipeline datatypes.JSONSlice[model.PipelineStage] `gorm:"column:pipeline" json:"-"`
 }
 
 // @Summary Create a new job listing
@@ -132,13 +198,9 @@ func (h *JobHandlers) CreateJobHandler(ctx *gin.Context) {
 		return
 	}
 
-	// Validate input data
-	if input.MinSalary == nil || input.MaxSalary == nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "minSalary and maxSalary are required"})
-		return
-	}
-	if *input.MaxSalary < *input.MinSalary {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "minSalary must be lower than or equal to maxSalary"})
+	job, msg := newJobFromInput(userid, &input)
+	if msg != "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
 		return
 	}
 
@@ -150,28 +212,11 @@ func (h *JobHandlers) CreateJobHandler(ctx *gin.Context) {
 		return
 	}
 
-	if input.NotifyOnApplication == nil {
-		defaultNotify := true
-		input.NotifyOnApplication = &defaultNotify
-	}
-
-	job := model.Job{
-		Name:                input.Name,
-		CompanyID:           company.UserID,
-		Position:            input.Position,
-		Duration:            input.Duration,
-		Description:         input.Description,
-		Location:            input.Location,
-		JobType:             model.JobType(input.JobType),
-		Experience:          model.ExperienceType(input.Experience),
-		MinSalary:           *input.MinSalary,
-		MaxSalary:           *input.MaxSalary,
-		ApprovalStatus:      model.JobApprovalPending,
-		IsOpen:              input.Open,
-		NotifyOnApplication: *input.NotifyOnApplication,
-	}
-
-	if err := h.DB.Create(&job).Error; err != nil {
+	if unknownSkills, err := insertJobWithSkills(h.DB, &job, input.RequiredSkills, input.NiceToHaveSkills); err != nil {
+		if err == errUnknownSkills {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "unknown skills", "skills": unknownSkills})
+			return
+		}
 		msg := "Failed to create job"
 		slog.Error(msg, "error", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
@@ -184,24 +229,36 @@ func (h *JobHandlers) CreateJobHandler(ctx *gin.Context) {
 }
 
 // @Summary Fetch job listings
-// @Description Retrieves a list of job postings with extensive filtering options. Behavior changes based on user role. Companies see their own jobs with application stats. Admins can see all jobs. Others see only open, approved jobs.
+// @Description Retrieves a list of job postings with extensive filtering options. Behavior changes based on user role. Companies see their own jobs with application stats. Admins can see all jobs. Others see only open, approved jobs inside their publishing window.
 // @Tags Jobs
 // @Security BearerAuth
 // @Produce json
 // @Param limit query uint false "Pagination limit" default(32)
 // @Param offset query uint false "Pagination offset"
+// @Param cursor query string false "Opaque cursor from nextCursor or prevCursor of a previous page, used instead of offset"
 // @Param location query string false "Filter by location"
-// @Param keyword query string false "Search keyword for name and description"
+// @Param workMode query []string false "Filter by work mode(s)" Enums(onsite, hybrid, remote)
+// @Param province query string false "Filter by province, in English or Thai as listed by /locations/provinces"
+// @Param nearLat query number false "Latitude of the point for a radius search, requires nearLon and radiusKm"
+// @Param nearLon query number false "Longitude of the point for a radius search, requires nearLat and radiusKm"
+// @Param radiusKm query number false "Only jobs located within this many kilometres of the point, jobs without a province never match"
+// @Param keyword query string false "Full-text search over name, position, company, duration and description"
+// @Param deadlineFrom query string false "Only jobs whose application deadline is at or after this time (RFC3339)"
+// @Param deadlineTo query string false "Only jobs whose application deadline is at or before this time (RFC3339)"
+// @Param sortBy query string false "Sort order. relevance requires keyword, salaries are compared as monthly figures, deadline puts the closest deadline first, mostApplied puts the jobs with the most applications first, pending and accepted order by application status counts (company only)" Enums(relevance, newest, oldest, minSalary, maxSalary, deadline, mostApplied, pending, accepted)
+// @Param sort query string false "Deprecated alias of sortBy, used when sortBy is not given" Enums(relevance, deadline)
 // @Param jobType query []string false "Filter by job type(s)"
 // @Param experience query []string false "Filter by experience level(s)"
-// @Param minSalary query uint false "Minimum salary filter"
-// @Param maxSalary query uint false "Maximum salary filter"
+// @Param skills query []string false "Filter by skill names or aliases, matching jobs that list any of them"
+// @Param minSalary query uint false "Minimum monthly salary filter, jobs paid per hour, day or year are converted to a monthly figure"
+// @Param maxSalary query uint false "Maximum monthly salary filter, jobs paid per hour, day or year are converted to a monthly figure"
+// @Param currency query string false "ISO 4217 currency of the salary filters, only jobs paying in it match" default(THB)
 // @Param open query bool false "Filter by open status (company only)"
 // @Param companyId query string false "Filter by company ID"
 // @Param id query uint false "Filter by specific job ID"
 // @Param approvalStatus query string false "Filter by approval status (admin/company only)"
-// @Success 200 {object} object{jobs=[]JobWithStatsResponse} "List of jobs for a company user (includes stats)"
-// @Success 200 {object} object{jobs=[]JobResponse} "List of jobs for a non-company user"
+// @Success 200 {object} object{jobs=[]JobWithStatsResponse,total=int,nextCursor=string,prevCursor=string} "List of jobs for a company user (includes stats)"
+// @Success 200 {object} object{jobs=[]JobResponse,total=int,nextCursor=string,prevCursor=string} "List of jobs for a non-company user"
 // @Failure 400 {object} object{error=string} "Bad Request"
 // @Failure 500 {object} object{error=string} "Internal Server Error"
 // @Router /jobs [get]
@@ -209,18 +266,30 @@ func (h *JobHandlers) FetchJobsHandler(ctx *gin.Context) {
 	userId := ctx.MustGet("userID").(string)
 
 	type FetchJobsInput struct {
-		Limit          uint     `json:"limit" form:"limit" binding:"max=128"`
-		Offset         uint     `json:"offset" form:"offset"`
-		Location       string   `json:"location" form:"location" binding:"max=128"`
-		Keyword        string   `json:"keyword" form:"keyword" binding:"max=256"`
-		JobType        []string `json:"jobType" form:"jobType" binding:"max=5,dive,max=32"`
-		Experience     []string `json:"experience" form:"experience" binding:"max=5,dive,max=32"`
-		MinSalary      uint     `json:"minSalary" form:"minSalary"`
-		MaxSalary      uint     `json:"maxSalary" form:"maxSalary"`
-		Open           *bool    `json:"open" form:"open"`
-		CompanyID      string   `json:"companyId" form:"companyId" binding:"max=64"`
-		JobID          *uint    `json:"id" form:"id" binding:"omitempty,max=64"`
-		ApprovalStatus *string  `json:"approvalStatus" form:"approvalStatus" binding:"omitempty,oneof=pending accepted rejected"`
+		Limit          uint       `json:"limit" form:"limit" binding:"max=128"`
+		Offset         uint       `json:"offset" form:"offset"`
+		Location       string     `json:"location" form:"location" binding:"max=128"`
+		WorkMode       []string   `json:"workMode" form:"workMode" binding:"max=3,dive,oneof=onsite hybrid remote"`
+		Province       string     `json:"province" form:"province" binding:"max=64"`
+		NearLat        *float64   `json:"nearLat" form:"nearLat" binding:"omitempty,min=-90,max=90"`
+		NearLon        *float64   `json:"nearLon" form:"nearLon" binding:"omitempty,min=-180,max=180"`
+		RadiusKm       *float64   `json:"radiusKm" form:"radiusKm" binding:"omitempty,gt=0,max=2000"`
+		Keyword        string     `json:"keyword" form:"keyword" binding:"max=256"`
+		JobType        []string   `json:"jobType" form:"jobType" binding:"max=5,dive,max=32"`
+		Experience     []string   `json:"experience" form:"experience" binding:"max=5,dive,max=32"`
+		MinSalary      uint       `json:"minSalary" form:"minSalary"`
+		MaxSalary      uint       `json:"maxSalary" form:"maxSalary"`
+		Currency       string     `json:"currency" form:"currency" binding:"omitempty,iso4217"`
+		Open           *bool      `json:"open" form:"open"`
+		CompanyID      string     `json:"companyId" form:"companyId" binding:"max=64"`
+		JobID          *uint      `json:"id" form:"id" binding:"omitempty,max=64"`
+		ApprovalStatus *string    `json:"approvalStatus" form:"approvalStatus" binding:"omitempty,oneof=pending accepted rejected"`
+		DeadlineFrom   *time.Time `json:"deadlineFrom" form:"deadlineFrom"`
+		DeadlineTo     *time.Time `json:"deadlineTo" form:"deadlineTo"`
+		Sort           string     `json:"sort" form:"sort" binding:"omitempty,oneof=relevance deadline"`
+		SortBy         string     `json:"sortBy" form:"sortBy" binding:"omitempty,oneof=relevance newest oldest minSalary maxSalary deadline mostApplied pending accepted"`
+		Skills         []string   `json:"skills" form:"skills" binding:"max=16,dive,max=64"`
+		Cursor         string     `json:"cursor" form:"cursor" binding:"max=512"`
 	}
 
 	input := FetchJobsInput{
@@ -236,7 +305,35 @@ func (h *JobHandlers) FetchJobsHandler(ctx *gin.Context) {
 		return
 	}
 
+	// sort is the older name of sortBy
+	sortName := input.SortBy
+	if sortName == "" {
+		sortName = input.Sort
+	}
+
 	role := helper.GetRole(userId, h.DB)
+	if (sortName == "pending" || sortName == "accepted") && role != helper.Company {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "only companies can sort by application status counts"})
+		return
+	}
+
+	province := ""
+	if input.Province != "" {
+		p, ok := helper.FindProvince(input.Province)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": helper.ErrUnknownProvince.Error()})
+			return
+		}
+		province = p.Name
+	}
+	var near *helper.GeoRadius
+	if input.NearLat != nil || input.NearLon != nil || input.RadiusKm != nil {
+		if input.NearLat == nil || input.NearLon == nil || input.RadiusKm == nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "nearLat, nearLon and radiusKm must be given together"})
+			return
+		}
+		near = &helper.GeoRadius{Latitude: *input.NearLat, Longitude: *input.NearLon, RadiusKm: *input.RadiusKm}
+	}
 
 	query := h.DB.Model(&model.Job{}).
 		Joins("INNER JOIN users ON users.id = jobs.company_id").
@@ -246,22 +343,19 @@ func (h *JobHandlers) FetchJobsHandler(ctx *gin.Context) {
 		query = query.Where(&model.Job{ID: *input.JobID})
 	}
 
-	if input.Keyword != "" {
-		keywords := strings.FieldsSeq(input.Keyword) // Split by whitespace
-		for keyword := range keywords {
-			keywordPattern := fmt.Sprintf("%%%s%%", keyword)
-			searchGroup := h.DB.Where("name ILIKE ?", keywordPattern).
-				Or("description ILIKE ?", keywordPattern).
-				Or("position ILIKE ?", keywordPattern).
-				Or("duration ILIKE ?", keywordPattern).
-				Or("users.username ILIKE ?", keywordPattern)
-
-			query = query.Where(searchGroup)
-		}
-	}
-
-	query = query.Where("min_salary >= ?", input.MinSalary)
-	query = query.Where("max_salary <= ?", input.MaxSalary)
+	query = query.Scopes(helper.JobFilterScope(helper.JobFilter{
+		Keyword:    input.Keyword,
+		Location:   input.Location,
+		WorkMode:   input.WorkMode,
+		Province:   province,
+		Near:       near,
+		JobType:    input.JobType,
+		Experience: input.Experience,
+		MinSalary:  input.MinSalary,
+		MaxSalary:  input.MaxSalary,
+		Currency:   input.Currency,
+		Skills:     input.Skills,
+	}))
 
 	if role == helper.Company {
 		query = query.Where("company_id = ?", userId)
@@ -273,18 +367,16 @@ func (h *JobHandlers) FetchJobsHandler(ctx *gin.Context) {
 		query = query.Where("is_open = ?", *input.Open)
 	} else if role == helper.Viewer || role == helper.Student || role == helper.Unknown {
 		query = query.Where("is_open = ?", true)
+		// Hide jobs whose deadline passed even if the closing task has not run yet
+		query = query.Where("(jobs.application_deadline IS NULL OR jobs.application_deadline > ?)", time.Now())
+		query = query.Scopes(helper.PublishedJobsScope(time.Now()))
 	}
 
-	if len(input.Location) != 0 {
-		query = query.Where("location ILIKE ?", input.Location)
+	if input.DeadlineFrom != nil {
+		query = query.Where("jobs.application_deadline >= ?", *input.DeadlineFrom)
 	}
-
-	if len(input.JobType) != 0 {
-		query = query.Where("job_type IN ?", input.JobType)
-	}
-
-	if len(input.Experience) != 0 {
-		query = query.Where("experience IN ?", input.Experience)
+	if input.DeadlineTo != nil {
+		query = query.Where("jobs.application_deadline <= ?", *input.DeadlineTo)
 	}
 
 	if role == helper.Admin || role == helper.Company {
@@ -303,12 +395,44 @@ func (h *JobHandlers) FetchJobsHandler(ctx *gin.Context) {
 		return
 	}
 
-	query = query.Offset(int(input.Offset)).Limit(int(input.Limit))
+	// Score each hit when searching so clients can show or sort by relevance
+	tsQuery := helper.BuildPrefixTSQuery(input.Keyword)
+	extraSelect := ""
+	relevanceArgs := []any{}
+	if tsQuery != "" {
+		extraSelect = ", ts_rank_cd(jobs.search_document, to_tsquery(?, ?)) AS relevance"
+		relevanceArgs = append(relevanceArgs, database.JobSearchConfig, tsQuery)
+	}
+
+	if sortName == "relevance" && tsQuery == "" {
+		sortName = ""
+	}
+	sortKeys := jobSortKeys(sortName, relevanceArgs)
+	var cursor *helper.Cursor
+	if input.Cursor != "" {
+		if input.Offset != 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "cursor cannot be combined with offset"})
+			return
+		}
+		var err error
+		if cursor, err = helper.DecodeCursor(input.Cursor, sortName, sortKeys); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	} else {
+		query = query.Offset(int(input.Offset))
+	}
+	query = query.Scopes(helper.KeysetScope(sortKeys, cursor, int(input.Limit)))
+
+	// The application count is only needed to build cursors when sorting by it
+	if sortName == "mostApplied" {
+		extraSelect += ", " + jobApplicationCountExpr + " AS application_count"
+	}
 
 	if role == helper.Company {
 		var jobsWithStats []JobWithStatsResponse
 		result := query.
-			Select("jobs.*, users.username as company_name, companies.photo_id, companies.banner_id, COUNT(CASE WHEN job_applications.status = 'pending' THEN 1 END) AS pending, COUNT(CASE WHEN job_applications.status = 'accepted' THEN 1 END) AS accepted, COUNT(CASE WHEN job_applications.status = 'rejected' THEN 1 END) AS rejected").
+			Select("jobs.*, users.username as company_name, companies.photo_id, companies.banner_id, COUNT(CASE WHEN job_applications.status = 'pending' THEN 1 END) AS pending, COUNT(CASE WHEN job_applications.status = 'accepted' THEN 1 END) AS accepted, COUNT(CASE WHEN job_applications.status = 'rejected' THEN 1 END) AS rejected"+extraSelect, relevanceArgs...).
 			Joins("LEFT JOIN job_applications ON job_applications.job_id = jobs.id").
 			Group("jobs.id, users.username, companies.photo_id, companies.banner_id").
 			Find(&jobsWithStats)
@@ -319,30 +443,149 @@ func (h *JobHandlers) FetchJobsHandler(ctx *gin.Context) {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
 			return
 		}
+		jobsWithStats, hasMore := helper.KeysetPage(jobsWithStats, int(input.Limit), cursor)
+		nextCursor, prevCursor := helper.PageCursors(sortName, jobsWithStats, func(job JobWithStatsResponse) []any {
+			return jobSortValues(sortName, &job)
+		}, hasMore, cursor, input.Offset)
+		jobIDs := make([]uint, 0, len(jobsWithStats))
+		for _, job := range jobsWithStats {
+			jobIDs = append(jobIDs, job.ID)
+		}
+		skillTags, err := loadJobSkillTags(h.DB, jobIDs)
+		if err != nil {
+			msg := "Failed to fetch job skills"
+			slog.Error(msg, "error", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+			return
+		}
+		stagedCounts, err := loadStagedApplicationCounts(h.DB, jobIDs)
+		if err != nil {
+			msg := "Failed to count job applications"
+			slog.Error(msg, "error", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+			return
+		}
+		for i := range jobsWithStats {
+			jobsWithStats[i].Skills = skillTags[jobsWithStats[i].ID]
+			stages := (&model.Job{Pipeline: jobsWithStats[i].OwnPipeline}).Stages()
+			jobsWithStats[i].Stages = stageCounts(stages, jobsWithStats[i].ID, stagedCounts)
+		}
 		ctx.JSON(http.StatusOK, gin.H{
-			"jobs":  jobsWithStats,
-			"total": totalCount,
+			"jobs":       jobsWithStats,
+			"total":      totalCount,
+			"nextCursor": nextCursor,
+			"prevCursor": prevCursor,
 		})
 		return
 	}
 
-	// return Job posts with company info if not company (include whether current user has applied)
+	// return Job posts with company info if not company (include whether current user has applied or saved the job)
 	var jobs []JobResponse
 	result := query.
-		Select("jobs.*, users.username as company_name, companies.photo_id, companies.banner_id, EXISTS (SELECT 1 FROM job_applications WHERE job_applications.job_id = jobs.id AND job_applications.user_id = ?) AS applied", userId).
+		Select("jobs.*, users.username as company_name, companies.photo_id, companies.banner_id, EXISTS (SELECT 1 FROM job_applications WHERE job_applications.job_id = jobs.id AND job_applications.user_id = ?) AS applied, EXISTS (SELECT 1 FROM saved_jobs WHERE saved_jobs.job_id = jobs.id AND saved_jobs.user_id = ?) AS saved"+extraSelect, append([]any{userId, userId}, relevanceArgs...)...).
 		Find(&jobs)
 	if result.Error != nil {
 		slog.Error("Failed to fetch jobs", "error", result.Error)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch jobs"})
 		return
 	}
+	jobs, hasMore := helper.KeysetPage(jobs, int(input.Limit), cursor)
+	nextCursor, prevCursor := helper.PageCursors(sortName, jobs, func(job JobResponse) []any {
+		return jobSortValues(sortName, &JobWithStatsResponse{JobResponse: job})
+	}, hasMore, cursor, input.Offset)
+	jobIDs := make([]uint, 0, len(jobs))
+	for _, job := range jobs {
+		jobIDs = append(jobIDs, job.ID)
+	}
+	skillTags, err := loadJobSkillTags(h.DB, jobIDs)
+	if err != nil {
+		slog.Error("Failed to fetch job skills", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch job skills"})
+		return
+	}
+	for i := range jobs {
+		jobs[i].Skills = skillTags[jobs[i].ID]
+	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"jobs":  jobs,
-		"total": totalCount,
+		"jobs":       jobs,
+		"total":      totalCount,
+		"nextCursor": nextCursor,
+		"prevCursor": prevCursor,
 	})
 }
 
+// noDeadlineSortValue stands in for a missing application deadline when sorting, so those jobs come last.
+var noDeadlineSortValue = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+// jobApplicationCountExpr counts the applications to the job in the current row that were not withdrawn.
+const jobApplicationCountExpr = "(SELECT COUNT(*) FROM job_applications WHERE job_applications.job_id = jobs.id AND job_applications.status <> 'withdrawn')"
+
+// jobSortKeys lists the keyset ordering of a job listing sort option.
+// Every order ends with the job ID so pages never skip or repeat jobs.
+// Application counts are subqueries rather than the aggregates of the company listing so cursors can filter on them.
+func jobSortKeys(sort string, relevanceArgs []any) []helper.SortKey {
+	idDesc := true
+	keys := []helper.SortKey{}
+	switch sort {
+	case "relevance":
+		keys = append(keys, helper.SortKey{Expr: "ts_rank_cd(jobs.search_document, to_tsquery(?, ?))", Args: relevanceArgs, Desc: true, Kind: helper.CursorFloat})
+	case "newest":
+		keys = append(keys, helper.SortKey{Expr: "jobs.created_at", Desc: true, Kind: helper.CursorTime})
+	case "oldest":
+		keys = append(keys, helper.SortKey{Expr: "jobs.created_at", Kind: helper.CursorTime})
+		idDesc = false
+	case "minSalary":
+		keys = append(keys, helper.SortKey{Expr: helper.YearlySalaryExpr("jobs.min_salary"), Desc: true, Kind: helper.CursorInt})
+	case "maxSalary":
+		keys = append(keys, helper.SortKey{Expr: helper.YearlySalaryExpr("jobs.max_salary"), Desc: true, Kind: helper.CursorInt})
+	case "deadline":
+		keys = append(keys, helper.SortKey{Expr: "COALESCE(jobs.application_deadline, ?)", Args: []any{noDeadlineSortValue}, Kind: helper.CursorTime})
+	case "mostApplied":
+		keys = append(keys, helper.SortKey{Expr: jobApplicationCountExpr, Desc: true, Kind: helper.CursorInt})
+	case "pending", "accepted":
+		keys = append(keys, helper.SortKey{
+			Expr: "(SELECT COUNT(*) FROM job_applications WHERE job_applications.job_id = jobs.id AND job_applications.status = ?)",
+			Args: []any{sort},
+			Desc: true,
+			Kind: helper.CursorInt,
+		})
+	}
+	return append(keys, helper.SortKey{Expr: "jobs.id", Desc: idDesc, Kind: helper.CursorInt})
+}
+
+// jobSortValues returns a job's values for the keys from jobSortKeys.
+func jobSortValues(sort string, job *JobWithStatsResponse) []any {
+	values := []any{}
+	switch sort {
+	case "relevance":
+		relevance := 0.0
+		if job.Relevance != nil {
+			relevance = *job.Relevance
+		}
+		values = append(values, relevance)
+	case "newest", "oldest":
+		values = append(values, job.CreatedAt)
+	case "minSalary":
+		values = append(values, model.YearlySalary(job.MinSalary, model.SalaryPeriod(job.SalaryPeriod)))
+	case "maxSalary":
+		values = append(values, model.YearlySalary(job.MaxSalary, model.SalaryPeriod(job.SalaryPeriod)))
+	case "deadline":
+		deadline := noDeadlineSortValue
+		if job.ApplicationDeadline != nil {
+			deadline = *job.ApplicationDeadline
+		}
+		values = append(values, deadline)
+	case "mostApplied":
+		values = append(values, job.ApplicationCount)
+	case "pending":
+		values = append(values, job.Pending)
+	case "accepted":
+		values = append(values, job.Accepted)
+	}
+	return append(values, job.ID)
+}
+
 // @Summary Edit a job listing
 // @Description Allows a company to edit one of their own job postings. Supports partial updates.
 // @Tags Jobs
@@ -371,11 +614,11 @@ func (h *JobHandlers) EditJobHandler(ctx *gin.Context) {
 		return
 	}
 
-	needReapproval := input.Name != nil || input.Position != nil || input.Duration != nil || input.Description != nil || input.Location != nil || input.JobType != nil || input.Experience != nil || input.MinSalary != nil || input.MaxSalary != nil
+	changesContent := input.Name != nil || input.Position != nil || input.Duration != nil || input.Description != nil || input.Location != nil || input.WorkMode != nil || input.Province != nil || input.District != nil || input.JobType != nil || input.Experience != nil || input.MinSalary != nil || input.MaxSalary != nil || input.SalaryCurrency != nil || input.SalaryPeriod != nil || input.SalaryUndisclosed != nil || input.Questions != nil
 
 	if ctx.GetBool("ShouldCF") {
 		ctx.Request.Body = io.NopCloser(bytes.NewReader(b.Bytes()))
-		ctx.Set("DoCF", needReapproval)
+		ctx.Set("DoCF", changesContent)
 		ctx.Next()
 		return
 	}
@@ -404,58 +647,138 @@ func (h *JobHandlers) EditJobHandler(ctx *gin.Context) {
 		ctx.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
 		return
 	}
+	before := *job
 
 	// Update job post with new data
-	if input.Name != nil {
-		job.Name = *input.Name
-	}
-	if input.Position != nil {
-		job.Position = *input.Position
-	}
-	if input.Duration != nil {
-		job.Duration = *input.Duration
-	}
-	if input.Description != nil {
-		job.Description = *input.Description
-	}
-	if input.Location != nil {
-		job.Location = *input.Location
-	}
-	if input.JobType != nil {
-		job.JobType = model.JobType(*input.JobType)
-	}
-	if input.Open != nil {
-		job.IsOpen = *input.Open
-	}
-	if input.Experience != nil {
-		job.Experience = model.ExperienceType(*input.Experience)
-	}
-	if input.MinSalary != nil {
-		job.MinSalary = *input.MinSalary
-	}
-	if input.MaxSalary != nil {
-		job.MaxSalary = *input.MaxSalary
-	}
-	// Check for invalid salary range
-	if job.MinSalary > job.MaxSalary {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "minSalary cannot exceed maxSalary"})
+	if msg := applyEditJobInput(job, &input); msg != "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
 		return
 	}
-	if input.NotifyOnApplication != nil {
-		job.NotifyOnApplication = *input.NotifyOnApplication
+
+	// Stages can only be dropped once no application is left in them
+	if input.Pipeline != nil {
+		pipeline, msg := newPipeline(*input.Pipeline)
+		if msg != "" {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+		orphaned, err := orphanedStages(h.DB, job.ID, before.Stages(), (&model.Job{Pipeline: pipeline}).Stages())
+		if err != nil {
+			msg := "Failed to count job applications"
+			slog.Error(msg, "error", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+			return
+		}
+		if len(orphaned) != 0 {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "applications are still in stages missing from the new pipeline", "stages": orphaned})
+			return
+		}
+		job.Pipeline = pipeline
 	}
 
-	if needReapproval {
+	// Material changes could turn an approved post into something else, so they are reviewed again.
+	// A rejected post goes back to review on any edit so the company can fix it.
+	changes := model.DiffJobs(&before, job)
+
+	// Questions are replaced as a whole, and only if they actually changed
+	var questions []model.JobQuestion
+	questionsChanged := false
+	if input.Questions != nil {
+		var msg string
+		if questions, msg = newJobQuestions(*input.Questions); msg != "" {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+		currentQuestions, err := loadJobQuestions(h.DB, job.ID)
+		if err != nil {
+			msg := "Failed to fetch job questions"
+			slog.Error(msg, "error", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+			return
+		}
+		if questionsChanged = !sameJobQuestions(currentQuestions, questions); questionsChanged {
+			changes = append(changes, model.JobFieldChange{Field: "questions", Old: jobQuestionPrompts(currentQuestions), New: jobQuestionPrompts(questions)})
+		}
+	}
+
+	requiresReapproval := model.HasMaterialChange(changes) || (before.ApprovalStatus == model.JobApprovalRejected && len(changes) != 0)
+	if requiresReapproval {
 		job.ApprovalStatus = model.JobApprovalPending
+		job.ApprovedAt = nil
 	}
 
-	if err := h.DB.Save(&job).Error; err != nil {
+	var unknownSkills []string
+	if err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&job).Error; err != nil {
+			return err
+		}
+		if questionsChanged {
+			if err := replaceJobQuestions(tx, job.ID, questions); err != nil {
+				return err
+			}
+		}
+		if input.RequiredSkills != nil || input.NiceToHaveSkills != nil {
+			// Only the lists that were sent are replaced, the other keeps its current skills
+			required, niceToHave, err := currentJobSkillNames(tx, job.ID)
+			if err != nil {
+				return err
+			}
+			newRequired, newNiceToHave := required, niceToHave
+			if input.RequiredSkills != nil {
+				newRequired = *input.RequiredSkills
+			}
+			if input.NiceToHaveSkills != nil {
+				newNiceToHave = *input.NiceToHaveSkills
+			}
+			unknownSkills, err = replaceJobSkills(tx, job.ID, newRequired, newNiceToHave)
+			if err != nil {
+				return err
+			}
+			if len(unknownSkills) != 0 {
+				return errUnknownSkills
+			}
+			// Record the normalized skill names rather than what was typed
+			newRequired, newNiceToHave, err = currentJobSkillNames(tx, job.ID)
+			if err != nil {
+				return err
+			}
+			if !slices.Equal(required, newRequired) {
+				changes = append(changes, model.JobFieldChange{Field: "requiredSkills", Old: required, New: newRequired})
+			}
+			if !slices.Equal(niceToHave, newNiceToHave) {
+				changes = append(changes, model.JobFieldChange{Field: "niceToHaveSkills", Old: niceToHave, New: newNiceToHave})
+			}
+		}
+
+		if len(changes) == 0 {
+			return nil
+		}
+		return tx.Create(&model.JobRevision{
+			JobID:              job.ID,
+			EditorID:           userid,
+			Changes:            changes,
+			RequiresReapproval: requiresReapproval,
+		}).Error
+	}); err != nil {
+		if err == errUnknownSkills {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "unknown skills", "skills": unknownSkills})
+			return
+		}
 		msg := "Failed to update job"
 		slog.Error(msg, "error", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
 		return
 	}
+	if !job.IsOpen {
+		if err := services.RemoveSavedJobsForClosedJobs(h.DB, job.ID); err != nil {
+			slog.Warn("Failed to remove saved jobs for closed job", "job_id", job.ID, "error", err)
+		}
+	}
 	ctx.JSON(http.StatusOK, gin.H{"message": "job updated successfully"})
+
+	if requiresReapproval {
+		go h.aiService.AutoApproveJob(job)
+	}
 }
 
 // @Summary Approve or reject a job listing (Admin only)
@@ -491,7 +814,9 @@ func (h *JobHandlers) JobApprovalHandler(ctx *gin.Context) {
 	tx := h.DB.Begin()
 
 	if input.Approve {
+		now := time.Now()
 		job.ApprovalStatus = model.JobApprovalAccepted
+		job.ApprovedAt = &now
 	} else {
 		job.ApprovalStatus = model.JobApprovalRejected
 	}
@@ -559,13 +884,16 @@ func (h *JobHandlers) JobApprovalHandler(ctx *gin.Context) {
 }
 
 // @Summary Get job details
-// @Description Retrieves the detailed information for a single job posting by its ID.
+// @Description Retrieves the detailed information for a single job posting by its ID. Jobs outside their publishing window are only visible to the owning company and admins.
+// @Description Requests with "Accept: application/ld+json" get the job as schema.org JobPosting structured data instead.
 // @Tags Jobs
 // @Security BearerAuth
 // @Produce json
+// @Produce application/ld+json
 // @Param id path uint true "Job ID"
 // @Success 200 {object} handlers.JobResponse "Job details retrieved successfully"
 // @Failure 400 {object} object{error=string} "Bad Request: Invalid job ID"
+// @Failure 404 {object} object{error=string} "Not Found: Job is not published"
 // @Failure 500 {object} object{error=string} "Internal Server Error"
 // @Router /jobs/{id} [get]
 func (h *JobHandlers) GetJobDetailHandler(ctx *gin.Context) {
@@ -589,7 +917,38 @@ func (h *JobHandlers) GetJobDetailHandler(ctx *gin.Context) {
 		return
 	}
 
+	// Scheduled or expired jobs are hidden from everyone except the owner and admins
+	userId := ctx.GetString("userID")
+	if job.CompanyID != userId && helper.GetRole(userId, h.DB) != helper.Admin {
+		window := model.Job{PublishAt: job.PublishAt, UnpublishAt: job.UnpublishAt}
+		if !window.IsPublishedAt(time.Now()) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+			return
+		}
+
+		// Owners and admins looking at a job are not counted as views
+		if userId != "" {
+			if err := h.analyticsService.RecordJobView(ctx.Request.Context(), job.ID, userId); err != nil {
+				slog.Warn("Failed to record job view", "error", err)
+			}
+		}
+	}
+
+	ctx.Header("Vary", "Accept")
+	if ctx.NegotiateFormat(gin.MIMEJSON, MIMEJSONLD) == MIMEJSONLD {
+		row, err := loadJobPostingRow(h.DB, job.ID)
+		if err != nil {
+			msg := "Failed to retrieve job details"
+			slog.Error(msg, "error", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+			return
+		}
+		writeJSONLD(ctx, newJobPosting(row, helper.GetFrontendURL(), helper.GetPublicAPIURL()))
+		return
+	}
+
 	applied := false
+	saved := false
 	if uidVal, ok := ctx.Get("userID"); ok {
 		if uidStr, ok2 := uidVal.(string); ok2 && uidStr != "" {
 			var count int64
@@ -598,9 +957,42 @@ func (h *JobHandlers) GetJobDetailHandler(ctx *gin.Context) {
 				Count(&count).Error; err == nil {
 				applied = count > 0
 			}
+			if err := h.DB.Model(&model.SavedJob{}).
+				Where("job_id = ? AND user_id = ?", jobId, uidStr).
+				Count(&count).Error; err == nil {
+				saved = count > 0
+			}
 		}
 	}
 	job.Applied = applied
+	job.Saved = saved
+
+	skillTags, err := loadJobSkillTags(h.DB, []uint{job.ID})
+	if err != nil {
+		msg := "Failed to retrieve job skills"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+	job.Skills = skillTags[job.ID]
+
+	if job.Questions, err = loadJobQuestions(h.DB, job.ID); err != nil {
+		msg := "Failed to retrieve job questions"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	if job.CompanyID == userId {
+		owned := model.Job{}
+		if err := h.DB.Select("pipeline").Take(&owned, job.ID).Error; err != nil {
+			msg := "Failed to retrieve job pipeline"
+			slog.Error(msg, "error", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+			return
+		}
+		job.Pipeline = owned.Stages()
+	}
 
 	ctx.JSON(http.StatusOK, job)
 }
@@ -649,3 +1041,348 @@ func (h *JobHandlers) AcceptJobApplication(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, gin.H{"message": "ok"})
 }
+
+// @Summary List job revisions
+// @Description Retrieves the edit history of a job as field-level diffs, newest first. Only the owning company and admins can see it.
+// @Tags Jobs
+// @Security BearerAuth
+// @Produce json
+// @Param id path uint true "Job ID"
+// @Param limit query uint false "Pagination limit" default(32)
+// @Param offset query uint false "Pagination offset"
+// @Success 200 {object} object{revisions=[]model.JobRevision,total=int} "List of revisions with total count"
+// @Failure 400 {object} object{error=string} "Bad Request"
+// @Failure 403 {object} object{error=string} "Forbidden"
+// @Failure 404 {object} object{error=string} "Not Found"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /jobs/{id}/revisions [get]
+func (h *JobHandlers) GetJobRevisionsHandler(ctx *gin.Context) {
+	userId := ctx.MustGet("userID").(string)
+
+	type FetchJobRevisionsInput struct {
+		Limit  uint `json:"limit" form:"limit" binding:"max=64"`
+		Offset uint `json:"offset" form:"offset"`
+	}
+	input := FetchJobRevisionsInput{
+		Limit: 32,
+	}
+	if err := ctx.ShouldBind(&input); err != nil {
+		msg := "Failed to bind input"
+		slog.Debug(msg, "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	jobIdStr := ctx.Param("id")
+	jobId64, err := strconv.ParseUint(jobIdStr, 10, 64)
+	if err != nil || jobId64 <= 0 || jobId64 > math.MaxUint32 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid job id"})
+		return
+	}
+	jobId := uint(jobId64)
+
+	job := model.Job{}
+	if err := h.DB.Select("id", "company_id").Take(&job, jobId).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+		} else {
+			msg := "Failed to fetch job"
+			slog.Error(msg, "error", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		}
+		return
+	}
+	if job.CompanyID != userId && helper.GetRole(userId, h.DB) != helper.Admin {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		return
+	}
+
+	query := h.DB.Model(&model.JobRevision{}).Where("job_id = ?", jobId)
+
+	var totalCount int64
+	if err := query.Count(&totalCount).Error; err != nil {
+		msg := "Failed to count job revisions"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	revisions := []model.JobRevision{}
+	if err := query.Order("created_at DESC").Order("id DESC").Offset(int(input.Offset)).Limit(int(input.Limit)).Find(&revisions).Error; err != nil {
+		msg := "Failed to fetch job revisions"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"revisions": revisions,
+		"total":     totalCount,
+	})
+}
+
+// newJobFromInput validates a job creation input and builds the pending job it describes.
+// Returns an error message suitable for the client, or an empty string if the input is valid.
+func newJobFromInput(companyID string, input *CreateJobInput) (model.Job, string) {
+	// An undisclosed salary is shown as negotiable, any figures sent with it are dropped
+	if input.SalaryUndisclosed {
+		noSalary := uint(0)
+		input.MinSalary = &noSalary
+		input.MaxSalary = &noSalary
+	}
+	if input.MinSalary == nil || input.MaxSalary == nil {
+		return model.Job{}, "minSalary and maxSalary are required"
+	}
+	if *input.MaxSalary < *input.MinSalary {
+		return model.Job{}, "minSalary must be lower than or equal to maxSalary"
+	}
+	if input.ApplicationDeadline != nil && !input.ApplicationDeadline.After(time.Now()) {
+		return model.Job{}, "applicationDeadline must be in the future"
+	}
+	if msg := validatePublishWindow(input.PublishAt, input.UnpublishAt); msg != "" {
+		return model.Job{}, msg
+	}
+
+	if input.NotifyOnApplication == nil {
+		defaultNotify := true
+		input.NotifyOnApplication = &defaultNotify
+	}
+	if input.SalaryCurrency == "" {
+		input.SalaryCurrency = model.DefaultSalaryCurrency
+	}
+	if input.SalaryPeriod == "" {
+		input.SalaryPeriod = string(model.SalaryPeriodMonth)
+	}
+	if input.WorkMode == "" {
+		input.WorkMode = string(model.WorkModeOnsite)
+	}
+
+	job := model.Job{
+		Name:                    input.Name,
+		CompanyID:               companyID,
+		Position:                input.Position,
+		Duration:                input.Duration,
+		Description:             input.Description,
+		Location:                input.Location,
+		WorkMode:                model.WorkMode(input.WorkMode),
+		JobType:                 model.JobType(input.JobType),
+		Experience:              model.ExperienceType(input.Experience),
+		MinSalary:               *input.MinSalary,
+		MaxSalary:               *input.MaxSalary,
+		SalaryCurrency:          input.SalaryCurrency,
+		SalaryPeriod:            model.SalaryPeriod(input.SalaryPeriod),
+		SalaryUndisclosed:       input.SalaryUndisclosed,
+		ApprovalStatus:          model.JobApprovalPending,
+		IsOpen:                  input.Open,
+		Openings:                input.Openings,
+		NotifyPendingWhenFilled: input.NotifyPendingWhenFilled,
+		ApplicationDeadline:     input.ApplicationDeadline,
+		PublishAt:               input.PublishAt,
+		UnpublishAt:             input.UnpublishAt,
+		NotifyOnApplication:     *input.NotifyOnApplication,
+	}
+	if msg := setJobPlace(&job, input.Province, input.District); msg != "" {
+		return model.Job{}, msg
+	}
+	questions, msg := newJobQuestions(input.Questions)
+	if msg != "" {
+		return model.Job{}, msg
+	}
+	job.Questions = questions
+	if job.Pipeline, msg = newPipeline(input.Pipeline); msg != "" {
+		return model.Job{}, msg
+	}
+	return job, ""
+}
+
+// insertJobWithSkills creates a job and links its skills in one transaction.
+// If some skill names match no skill nothing is created, and errUnknownSkills is returned with the unknown names.
+func insertJobWithSkills(db *gorm.DB, job *model.Job, required []string, niceToHave []string) ([]string, error) {
+	var unknownSkills []string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(job).Error; err != nil {
+			return err
+		}
+		var err error
+		unknownSkills, err = replaceJobSkills(tx, job.ID, required, niceToHave)
+		if err != nil {
+			return err
+		}
+		if len(unknownSkills) != 0 {
+			return errUnknownSkills
+		}
+		return nil
+	})
+	return unknownSkills, err
+}
+
+// applyEditJobInput copies the fields set in the input onto the job and validates the result.
+// Returns an error message suitable for the client, or an empty string if the job is valid.
+func applyEditJobInput(job *model.Job, input *EditJobInput) string {
+	if input.Name != nil {
+		job.Name = *input.Name
+	}
+	if input.Position != nil {
+		job.Position = *input.Position
+	}
+	if input.Duration != nil {
+		job.Duration = *input.Duration
+	}
+	if input.Description != nil {
+		job.Description = *input.Description
+	}
+	if input.Location != nil {
+		job.Location = *input.Location
+	}
+	if input.WorkMode != nil {
+		job.WorkMode = model.WorkMode(*input.WorkMode)
+	}
+	if input.Province != nil || input.District != nil {
+		// A new province drops the old district unless a district is given with it
+		province, district := job.Province, job.District
+		if input.Province != nil {
+			province, district = *input.Province, ""
+		}
+		if input.District != nil {
+			district = *input.District
+		}
+		if msg := setJobPlace(job, province, district); msg != "" {
+			return msg
+		}
+	}
+	if input.JobType != nil {
+		job.JobType = model.JobType(*input.JobType)
+	}
+	if input.Open != nil {
+		job.IsOpen = *input.Open
+	}
+	if input.Experience != nil {
+		job.Experience = model.ExperienceType(*input.Experience)
+	}
+	if input.MinSalary != nil {
+		job.MinSalary = *input.MinSalary
+	}
+	if input.MaxSalary != nil {
+		job.MaxSalary = *input.MaxSalary
+	}
+	if input.SalaryCurrency != nil {
+		job.SalaryCurrency = *input.SalaryCurrency
+	}
+	if input.SalaryPeriod != nil {
+		job.SalaryPeriod = model.SalaryPeriod(*input.SalaryPeriod)
+	}
+	if input.SalaryUndisclosed != nil {
+		job.SalaryUndisclosed = *input.SalaryUndisclosed
+	}
+	if job.SalaryUndisclosed {
+		job.MinSalary = 0
+		job.MaxSalary = 0
+	}
+	// Check for invalid salary range
+	if job.MinSalary > job.MaxSalary {
+		return "minSalary cannot exceed maxSalary"
+	}
+	if input.NotifyOnApplication != nil {
+		job.NotifyOnApplication = *input.NotifyOnApplication
+	}
+	if input.Openings != nil {
+		if *input.Openings == 0 {
+			job.Openings = nil
+		} else {
+			openings := *input.Openings
+			job.Openings = &openings
+		}
+	}
+	if input.NotifyPendingWhenFilled != nil {
+		job.NotifyPendingWhenFilled = *input.NotifyPendingWhenFilled
+	}
+	if input.ClearApplicationDeadline {
+		if input.ApplicationDeadline != nil {
+			return "applicationDeadline cannot be set and cleared at once"
+		}
+		job.ApplicationDeadline = nil
+	}
+	if input.ApplicationDeadline != nil {
+		if !input.ApplicationDeadline.After(time.Now()) {
+			return "applicationDeadline must be in the future"
+		}
+		job.ApplicationDeadline = input.ApplicationDeadline
+	}
+	if input.PublishAt != nil {
+		job.PublishAt = input.PublishAt
+		// A rescheduled job should announce itself again when it goes live
+		job.PublishNotifiedAt = nil
+	}
+	if input.UnpublishAt != nil {
+		job.UnpublishAt = input.UnpublishAt
+	}
+	if input.PublishAt != nil || input.UnpublishAt != nil {
+		if msg := validatePublishWindow(job.PublishAt, job.UnpublishAt); msg != "" {
+			return msg
+		}
+	}
+	return ""
+}
+
+// validateJobContent checks that a job built from a clone or template has every field a new job requires.
+// Returns an error message suitable for the client, or an empty string if the job is complete.
+func validateJobContent(job *model.Job) string {
+	required := []struct {
+		field string
+		value string
+	}{
+		{"name", job.Name},
+		{"position", job.Position},
+		{"duration", job.Duration},
+		{"description", job.Description},
+		{"location", job.Location},
+		{"jobType", string(job.JobType)},
+		{"experience", string(job.Experience)},
+	}
+	for _, r := range required {
+		if strings.TrimSpace(r.value) == "" {
+			return r.field + " is required"
+		}
+	}
+	return ""
+}
+
+// setJobPlace resolves a province and optional district against the bundled location dataset and stores them on the job with their coordinates.
+// An empty province clears the structured location.
+// Returns an error message suitable for the client, or an empty string on success.
+func setJobPlace(job *model.Job, province string, district string) string {
+	if strings.TrimSpace(province) == "" {
+		if strings.TrimSpace(district) != "" {
+			return "district requires a province"
+		}
+		job.Province = ""
+		job.District = ""
+		job.Latitude = nil
+		job.Longitude = nil
+		return ""
+	}
+	place, err := helper.ResolvePlace(province, district)
+	if err != nil {
+		return err.Error()
+	}
+	job.Province = place.Province
+	job.District = place.District
+	job.Latitude = &place.Latitude
+	job.Longitude = &place.Longitude
+	return ""
+}
+
+// validatePublishWindow checks that a job's publishing window ends in the future and after it starts.
+// Returns an error message suitable for the client, or an empty string if the window is valid.
+func validatePublishWindow(publishAt *time.Time, unpublishAt *time.Time) string {
+	if unpublishAt == nil {
+		return ""
+	}
+	if !unpublishAt.After(time.Now()) {
+		return "unpublishAt must be in the future"
+	}
+	if publishAt != nil && !unpublishAt.After(*publishAt) {
+		return "unpublishAt must be after publishAt"
+	}
+	return ""
+}
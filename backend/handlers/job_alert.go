@@ -0,0 +1,368 @@
+package handlers
+
+import (
+	"bytes"
+	"html/template"
+	"ku-work/backend/helper"
+	"ku-work/backend/model"
+	"ku-work/backend/services"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxJobAlertsPerStudent limits how many saved searches a single student can keep.
+const maxJobAlertsPerStudent = 10
+
+type JobAlertHandlers struct {
+	DB *gorm.DB
+	// unsubscribePageTemplate is the page opened by the unsubscribe link of digest emails.
+	// Opening the link only asks for confirmation, so mail scanners and link prefetchers do not unsubscribe anyone.
+	unsubscribePageTemplate *template.Template
+}
+
+func NewJobAlertHandlers(db *gorm.DB) (*JobAlertHandlers, error) {
+	unsubscribePageTemplate, err := template.New("job_alert_unsubscribe.tmpl").ParseFiles("email_templates/job_alert_unsubscribe.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	return &JobAlertHandlers{
+		DB:                      db,
+		unsubscribePageTemplate: unsubscribePageTemplate,
+	}, nil
+}
+
+// CreateJobAlertInput defines the request body for saving a job search as an alert.
+type CreateJobAlertInput struct {
+	Name       string   `json:"name" binding:"required,max=128"`
+	Keyword    string   `json:"keyword" binding:"max=256"`
+	Location   string   `json:"location" binding:"max=128"`
+	JobType    []string `json:"jobType" binding:"max=5,dive,oneof='fulltime' 'parttime' 'contract' 'casual' 'internship'"`
+	Experience []string `json:"experience" binding:"max=5,dive,oneof='newgrad' 'junior' 'senior' 'manager' 'internship'"`
+	MinSalary  uint     `json:"minSalary"`
+	MaxSalary  uint     `json:"maxSalary"`
+	Frequency  string   `json:"frequency" binding:"omitempty,oneof=daily weekly"`
+}
+
+// EditJobAlertInput defines the request body for editing a job alert. Supports partial updates.
+type EditJobAlertInput struct {
+	Name       *string   `json:"name" binding:"omitempty,max=128"`
+	Keyword    *string   `json:"keyword" binding:"omitempty,max=256"`
+	Location   *string   `json:"location" binding:"omitempty,max=128"`
+	JobType    *[]string `json:"jobType" binding:"omitempty,max=5,dive,oneof='fulltime' 'parttime' 'contract' 'casual' 'internship'"`
+	Experience *[]string `json:"experience" binding:"omitempty,max=5,dive,oneof='newgrad' 'junior' 'senior' 'manager' 'internship'"`
+	MinSalary  *uint     `json:"minSalary"`
+	MaxSalary  *uint     `json:"maxSalary"`
+	Frequency  *string   `json:"frequency" binding:"omitempty,oneof=daily weekly"`
+}
+
+// @Summary List job alerts
+// @Description Retrieves the authenticated student's saved job searches.
+// @Tags Job Alerts
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} object{alerts=[]model.JobAlert} "List of job alerts"
+// @Failure 403 {object} object{error=string} "Forbidden: Only students can manage job alerts"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /me/job-alerts [get]
+func (h *JobAlertHandlers) GetJobAlertsHandler(ctx *gin.Context) {
+	userId := ctx.MustGet("userID").(string)
+	if helper.GetRole(userId, h.DB) != helper.Student {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "only students can manage job alerts"})
+		return
+	}
+
+	alerts := []model.JobAlert{}
+	if err := h.DB.Where("user_id = ?", userId).Order("created_at DESC").Find(&alerts).Error; err != nil {
+		slog.Error("Failed to get job alerts", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get job alerts"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"alerts": alerts})
+}
+
+// @Summary Create a job alert
+// @Description Saves a job search filter set as a named alert. Jobs approved after the alert is created are emailed to the student as a daily or weekly digest.
+// @Tags Job Alerts
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param alert body handlers.CreateJobAlertInput true "Job alert data"
+// @Success 200 {object} model.JobAlert "Created job alert"
+// @Failure 400 {object} object{error=string} "Bad Request"
+// @Failure 403 {object} object{error=string} "Forbidden: Only students can manage job alerts"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /me/job-alerts [post]
+func (h *JobAlertHandlers) CreateJobAlertHandler(ctx *gin.Context) {
+	userId := ctx.MustGet("userID").(string)
+	if helper.GetRole(userId, h.DB) != helper.Student {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "only students can manage job alerts"})
+		return
+	}
+
+	input := CreateJobAlertInput{}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		msg := "Failed to bind input"
+		slog.Debug(msg, "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if input.MaxSalary != 0 && input.MinSalary > input.MaxSalary {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "minSalary must not be greater than maxSalary"})
+		return
+	}
+	if input.Frequency == "" {
+		input.Frequency = string(model.JobAlertDaily)
+	}
+
+	var alertCount int64
+	if err := h.DB.Model(&model.JobAlert{}).Where("user_id = ?", userId).Count(&alertCount).Error; err != nil {
+		slog.Error("Failed to count job alerts", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create job alert"})
+		return
+	}
+	if alertCount >= maxJobAlertsPerStudent {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "too many job alerts"})
+		return
+	}
+
+	token, err := services.GenerateJobAlertUnsubscribeToken()
+	if err != nil {
+		slog.Error("Failed to generate unsubscribe token", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create job alert"})
+		return
+	}
+
+	alert := model.JobAlert{
+		UserID:           userId,
+		Name:             input.Name,
+		Keyword:          input.Keyword,
+		Location:         input.Location,
+		JobType:          datatypes.JSONSlice[string](input.JobType),
+		Experience:       datatypes.JSONSlice[string](input.Experience),
+		MinSalary:        input.MinSalary,
+		MaxSalary:        input.MaxSalary,
+		Frequency:        model.JobAlertFrequency(input.Frequency),
+		LastSentAt:       time.Now(),
+		UnsubscribeToken: token,
+	}
+	if err := h.DB.Create(&alert).Error; err != nil {
+		slog.Error("Failed to create job alert", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create job alert"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, alert)
+}
+
+// @Summary Edit a job alert
+// @Description Updates one of the authenticated student's job alerts. Supports partial updates.
+// @Tags Job Alerts
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path uint true "Job alert ID"
+// @Param alert body handlers.EditJobAlertInput true "Job alert update data"
+// @Success 200 {object} model.JobAlert "Updated job alert"
+// @Failure 400 {object} object{error=string} "Bad Request"
+// @Failure 404 {object} object{error=string} "Not Found"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /me/job-alerts/{id} [patch]
+func (h *JobAlertHandlers) EditJobAlertHandler(ctx *gin.Context) {
+	userId := ctx.MustGet("userID").(string)
+
+	alert, ok := h.findOwnJobAlert(ctx, userId)
+	if !ok {
+		return
+	}
+
+	input := EditJobAlertInput{}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		msg := "Failed to bind input"
+		slog.Debug(msg, "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	if input.Name != nil {
+		alert.Name = *input.Name
+	}
+	if input.Keyword != nil {
+		alert.Keyword = *input.Keyword
+	}
+	if input.Location != nil {
+		alert.Location = *input.Location
+	}
+	if input.JobType != nil {
+		alert.JobType = datatypes.JSONSlice[string](*input.JobType)
+	}
+	if input.Experience != nil {
+		alert.Experience = datatypes.JSONSlice[string](*input.Experience)
+	}
+	if input.MinSalary != nil {
+		alert.MinSalary = *input.MinSalary
+	}
+	if input.MaxSalary != nil {
+		alert.MaxSalary = *input.MaxSalary
+	}
+	if input.Frequency != nil {
+		alert.Frequency = model.JobAlertFrequency(*input.Frequency)
+	}
+	if alert.MaxSalary != 0 && alert.MinSalary > alert.MaxSalary {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "minSalary must not be greater than maxSalary"})
+		return
+	}
+
+	if err := h.DB.Save(&alert).Error; err != nil {
+		slog.Error("Failed to update job alert", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update job alert"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, alert)
+}
+
+// @Summary Delete a job alert
+// @Description Deletes one of the authenticated student's job alerts.
+// @Tags Job Alerts
+// @Security BearerAuth
+// @Produce json
+// @Param id path uint true "Job alert ID"
+// @Success 200 {object} object{message=string} "ok"
+// @Failure 400 {object} object{error=string} "Bad Request"
+// @Failure 404 {object} object{error=string} "Not Found"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /me/job-alerts/{id} [delete]
+func (h *JobAlertHandlers) DeleteJobAlertHandler(ctx *gin.Context) {
+	userId := ctx.MustGet("userID").(string)
+
+	alert, ok := h.findOwnJobAlert(ctx, userId)
+	if !ok {
+		return
+	}
+
+	if err := h.DB.Delete(&alert).Error; err != nil {
+		slog.Error("Failed to delete job alert", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete job alert"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "ok"})
+}
+
+// @Summary Confirm unsubscribing from a job alert
+// @Description Shows the page opened by the unsubscribe link included in every digest email, asking the student to confirm. Nothing is deleted until the confirmation is posted. Does not require authentication.
+// @Tags Job Alerts
+// @Produce html
+// @Param token path string true "Unsubscribe token"
+// @Success 200 {string} string "Confirmation page"
+// @Failure 404 {string} string "Not Found"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /job-alerts/unsubscribe/{token} [get]
+func (h *JobAlertHandlers) ConfirmUnsubscribeJobAlertHandler(ctx *gin.Context) {
+	alert := model.JobAlert{}
+	token := ctx.Param("token")
+	if token != "" && len(token) <= 128 {
+		if err := h.DB.Select("name").Where("unsubscribe_token = ?", token).Take(&alert).Error; err != nil && err != gorm.ErrRecordNotFound {
+			slog.Error("Failed to get job alert", "error", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get job alert"})
+			return
+		}
+	}
+
+	status := http.StatusOK
+	if alert.Name == "" {
+		status = http.StatusNotFound
+	}
+	h.writeUnsubscribePage(ctx, status, alert.Name, false)
+}
+
+// @Summary Unsubscribe from a job alert
+// @Description Deletes the job alert identified by the unsubscribe token included in every digest email. Does not require authentication.
+// @Description Browsers posting the confirmation page get a page back, other clients get JSON.
+// @Tags Job Alerts
+// @Produce json,html
+// @Param token path string true "Unsubscribe token"
+// @Success 200 {object} object{message=string} "ok"
+// @Failure 404 {object} object{error=string} "Not Found"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /job-alerts/unsubscribe/{token} [post]
+func (h *JobAlertHandlers) UnsubscribeJobAlertHandler(ctx *gin.Context) {
+	wantsPage := ctx.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML
+	token := ctx.Param("token")
+	if token == "" || len(token) > 128 {
+		h.writeUnsubscribeNotFound(ctx, wantsPage)
+		return
+	}
+
+	alerts := []model.JobAlert{}
+	result := h.DB.Clauses(clause.Returning{Columns: []clause.Column{{Name: "name"}}}).
+		Where("unsubscribe_token = ?", token).
+		Delete(&alerts)
+	if result.Error != nil {
+		slog.Error("Failed to unsubscribe from job alert", "error", result.Error)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unsubscribe from job alert"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		h.writeUnsubscribeNotFound(ctx, wantsPage)
+		return
+	}
+
+	if wantsPage {
+		h.writeUnsubscribePage(ctx, http.StatusOK, alerts[0].Name, true)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "ok"})
+}
+
+func (h *JobAlertHandlers) writeUnsubscribeNotFound(ctx *gin.Context, wantsPage bool) {
+	if wantsPage {
+		h.writeUnsubscribePage(ctx, http.StatusNotFound, "", false)
+		return
+	}
+	ctx.JSON(http.StatusNotFound, gin.H{"error": "job alert not found"})
+}
+
+// writeUnsubscribePage renders the unsubscribe page for the alert with the given name, or the not found page if it is empty.
+func (h *JobAlertHandlers) writeUnsubscribePage(ctx *gin.Context, status int, name string, unsubscribed bool) {
+	var page bytes.Buffer
+	if err := h.unsubscribePageTemplate.Execute(&page, gin.H{"Name": name, "Unsubscribed": unsubscribed}); err != nil {
+		slog.Error("Failed to render unsubscribe page", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render unsubscribe page"})
+		return
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Data(status, "text/html; charset=utf-8", page.Bytes())
+}
+
+// findOwnJobAlert loads the alert named by the id path parameter if it belongs to the user.
+// It writes the error response itself and reports whether the handler should continue.
+func (h *JobAlertHandlers) findOwnJobAlert(ctx *gin.Context, userId string) (model.JobAlert, bool) {
+	alert := model.JobAlert{}
+
+	alertId, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil || alertId <= 0 || alertId > math.MaxUint32 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid job alert id"})
+		return alert, false
+	}
+
+	if err := h.DB.Where("id = ? AND user_id = ?", uint(alertId), userId).Take(&alert).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "job alert not found"})
+		} else {
+			slog.Error("Failed to get job alert", "error", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get job alert"})
+		}
+		return alert, false
+	}
+	return alert, true
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"ku-work/backend/helper"
+	"ku-work/backend/model"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// JobAnalyticsDay holds one day of a job's engagement numbers.
+type JobAnalyticsDay struct {
+	Date          string `json:"date"`
+	Views         int64  `json:"views"`
+	UniqueViewers int64  `json:"uniqueViewers"`
+	Applications  int64  `json:"applications"`
+}
+
+// JobAnalyticsResponse summarises how a job has been viewed and applied to over a range of days.
+type JobAnalyticsResponse struct {
+	JobID          uint              `json:"jobId"`
+	From           string            `json:"from"`
+	To             string            `json:"to"`
+	Views          int64             `json:"views"`
+	UniqueViewers  int64             `json:"uniqueViewers"`
+	Applications   int64             `json:"applications"`
+	ConversionRate float64           `json:"conversionRate"`
+	Daily          []JobAnalyticsDay `json:"daily"`
+}
+
+// @Summary Get job analytics
+// @Description Returns detail page views, unique viewers, applications and the view-to-apply conversion rate of a job, with a daily series. Days are in UTC. Views are collected in batches, so the latest few minutes may be missing. Only the owning company and admins can see them.
+// @Tags Jobs
+// @Security BearerAuth
+// @Produce json
+// @Param id path uint true "Job ID"
+// @Param days query uint false "Number of days to report, ending today" default(30)
+// @Success 200 {object} handlers.JobAnalyticsResponse "Job analytics"
+// @Failure 400 {object} object{error=string} "Bad Request"
+// @Failure 403 {object} object{error=string} "Forbidden"
+// @Failure 404 {object} object{error=string} "Not Found"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /jobs/{id}/analytics [get]
+func (h *JobHandlers) GetJobAnalyticsHandler(ctx *gin.Context) {
+	userId := ctx.MustGet("userID").(string)
+
+	type GetJobAnalyticsInput struct {
+		Days uint `json:"days" form:"days" binding:"min=1,max=365"`
+	}
+	input := GetJobAnalyticsInput{
+		Days: 30,
+	}
+	if err := ctx.ShouldBind(&input); err != nil {
+		msg := "Failed to bind input"
+		slog.Debug(msg, "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	jobId64, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil || jobId64 <= 0 || jobId64 > math.MaxUint32 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid job id"})
+		return
+	}
+
+	job := model.Job{}
+	if err := h.DB.Select("id", "company_id").Take(&job, uint(jobId64)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+		} else {
+			msg := "Failed to fetch job"
+			slog.Error(msg, "error", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		}
+		return
+	}
+	if job.CompanyID != userId && helper.GetRole(userId, h.DB) != helper.Admin {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		return
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	from := today.AddDate(0, 0, -int(input.Days-1))
+
+	type dailyRow struct {
+		Date          time.Time
+		Views         int64
+		UniqueViewers int64
+	}
+	viewRows := []dailyRow{}
+	if err := h.DB.Model(&model.JobView{}).
+		Select("date, SUM(views) AS views, COUNT(*) AS unique_viewers").
+		Where("job_id = ? AND date >= ?", job.ID, from).
+		Group("date").
+		Scan(&viewRows).Error; err != nil {
+		msg := "Failed to fetch job views"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	// The same student viewing on several days is still one unique viewer overall
+	type totalsRow struct {
+		Views         int64
+		UniqueViewers int64
+	}
+	totals := totalsRow{}
+	if err := h.DB.Model(&model.JobView{}).
+		Select("COALESCE(SUM(views), 0) AS views, COUNT(DISTINCT viewer_id) AS unique_viewers").
+		Where("job_id = ? AND date >= ?", job.ID, from).
+		Scan(&totals).Error; err != nil {
+		msg := "Failed to fetch job views"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+	response := JobAnalyticsResponse{
+		JobID:         job.ID,
+		From:          from.Format(time.DateOnly),
+		To:            today.Format(time.DateOnly),
+		Views:         totals.Views,
+		UniqueViewers: totals.UniqueViewers,
+	}
+
+	type applicationRow struct {
+		Date         time.Time
+		Applications int64
+	}
+	applicationRows := []applicationRow{}
+	if err := h.DB.Model(&model.JobApplication{}).
+		Select("DATE(created_at AT TIME ZONE 'UTC') AS date, COUNT(*) AS applications").
+		Where("job_id = ? AND created_at >= ?", job.ID, from).
+		Group("1").
+		Scan(&applicationRows).Error; err != nil {
+		msg := "Failed to fetch job applications"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	// Every day in the range is listed, including days without activity
+	days := make(map[string]*JobAnalyticsDay, input.Days)
+	response.Daily = make([]JobAnalyticsDay, 0, input.Days)
+	for day := from; !day.After(today); day = day.AddDate(0, 0, 1) {
+		response.Daily = append(response.Daily, JobAnalyticsDay{Date: day.Format(time.DateOnly)})
+	}
+	for i := range response.Daily {
+		days[response.Daily[i].Date] = &response.Daily[i]
+	}
+	for _, row := range viewRows {
+		if day, ok := days[row.Date.Format(time.DateOnly)]; ok {
+			day.Views = row.Views
+			day.UniqueViewers = row.UniqueViewers
+		}
+	}
+	for _, row := range applicationRows {
+		if day, ok := days[row.Date.Format(time.DateOnly)]; ok {
+			day.Applications = row.Applications
+			response.Applications += row.Applications
+		}
+	}
+
+	if response.UniqueViewers > 0 {
+		response.ConversionRate = float64(response.Applications) / float64(response.UniqueViewers)
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,403 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"ku-work/backend/helper"
+	"ku-work/backend/model"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	MAX_JOB_IMPORT_SIZE = 2 * 1024 * 1024 // 2MB
+	MAX_JOB_IMPORT_ROWS = 200
+)
+
+// jobImportListSeparator separates the skill names inside a single CSV cell.
+const jobImportListSeparator = ";"
+
+// JobImportRowResult reports what happened to one row of a job import.
+// Rows are numbered from 1 in file order, not counting the CSV header.
+type JobImportRowResult struct {
+	Row    int      `json:"row"`
+	Name   string   `json:"name"`
+	Valid  bool     `json:"valid"`
+	JobID  *uint    `json:"jobId,omitempty"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// JobImportResponse is the per-row report of a job import.
+type JobImportResponse struct {
+	DryRun  bool                 `json:"dryRun"`
+	Total   int                  `json:"total"`
+	Valid   int                  `json:"valid"`
+	Invalid int                  `json:"invalid"`
+	Created int                  `json:"created"`
+	Rows    []JobImportRowResult `json:"rows"`
+}
+
+// jobImportRow is one parsed row of an import file, with the errors found while parsing it.
+type jobImportRow struct {
+	Input  CreateJobInput
+	Errors []string
+}
+
+// @Summary Import job listings
+// @Description Creates many jobs from a CSV or JSON file. Every row is checked with the same rules as creating a single job, and valid rows become jobs pending approval even when other rows are invalid. With dryRun nothing is created. A JSON file holds an array of job objects as accepted by POST /jobs. A CSV file has a header row naming the same fields, skill lists are separated by semicolons and times are RFC3339.
+// @Tags Jobs
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV or JSON file of jobs"
+// @Param format formData string false "File format, detected from the file extension when omitted" Enums(csv, json)
+// @Param dryRun formData bool false "Only validate the rows without creating jobs"
+// @Success 200 {object} handlers.JobImportResponse "Per-row import report"
+// @Failure 400 {object} object{error=string} "Bad Request"
+// @Failure 403 {object} object{error=string} "Forbidden"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /jobs/import [post]
+func (h *JobHandlers) ImportJobsHandler(ctx *gin.Context) {
+	userId := ctx.MustGet("userID").(string)
+	if helper.GetRole(userId, h.DB) != helper.Company {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "only companies can import jobs"})
+		return
+	}
+
+	type ImportJobsInput struct {
+		File   *multipart.FileHeader `form:"file" binding:"required"`
+		Format string                `form:"format" binding:"omitempty,oneof=csv json"`
+		DryRun bool                  `form:"dryRun"`
+	}
+	input := ImportJobsInput{}
+	if err := ctx.ShouldBind(&input); err != nil {
+		msg := "Failed to bind input"
+		slog.Debug(msg, "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if input.File.Size > MAX_JOB_IMPORT_SIZE {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("file must be at most %d bytes", MAX_JOB_IMPORT_SIZE)})
+		return
+	}
+	format := input.Format
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(input.File.Filename)), ".")
+	}
+
+	file, err := input.File.Open()
+	if err != nil {
+		msg := "Failed to read file"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	var rows []jobImportRow
+	switch format {
+	case "csv":
+		rows, err = parseJobImportCSV(file)
+	case "json":
+		rows, err = parseJobImportJSON(file)
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "file must be csv or json"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(rows) == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "file contains no jobs"})
+		return
+	}
+	if len(rows) > MAX_JOB_IMPORT_ROWS {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("file must contain at most %d jobs", MAX_JOB_IMPORT_ROWS)})
+		return
+	}
+
+	response := JobImportResponse{
+		DryRun: input.DryRun,
+		Total:  len(rows),
+		Rows:   make([]JobImportRowResult, 0, len(rows)),
+	}
+	created := []*model.Job{}
+	for i := range rows {
+		row := &rows[i]
+		result := JobImportRowResult{Row: i + 1, Name: row.Input.Name, Errors: row.Errors}
+		var job model.Job
+		if len(result.Errors) == 0 {
+			job, result.Errors = h.validateJobImportRow(userId, &row.Input)
+		}
+		if len(result.Errors) == 0 && !input.DryRun {
+			if unknownSkills, err := insertJobWithSkills(h.DB, &job, row.Input.RequiredSkills, row.Input.NiceToHaveSkills); err != nil {
+				if err == errUnknownSkills {
+					result.Errors = []string{"unknown skills: " + strings.Join(unknownSkills, ", ")}
+				} else {
+					msg := "Failed to create job"
+					slog.Error(msg, "error", err, "row", result.Row)
+					result.Errors = []string{msg}
+				}
+			} else {
+				result.JobID = &job.ID
+				created = append(created, &job)
+			}
+		}
+		result.Valid = len(result.Errors) == 0
+		if result.Valid {
+			response.Valid++
+		} else {
+			response.Invalid++
+		}
+		response.Rows = append(response.Rows, result)
+	}
+	response.Created = len(created)
+
+	ctx.JSON(http.StatusOK, response)
+
+	if len(created) != 0 {
+		// One job at a time, so a large import does not flood the approval AI
+		go func() {
+			for _, job := range created {
+				h.aiService.AutoApproveJob(job)
+			}
+		}()
+	}
+}
+
+// validateJobImportRow applies the checks of CreateJobHandler to one imported row and builds its job.
+// It returns every problem found with the row.
+func (h *JobHandlers) validateJobImportRow(companyID string, input *CreateJobInput) (model.Job, []string) {
+	if err := binding.Validator.ValidateStruct(input); err != nil {
+		return model.Job{}, describeValidationErrors(err, input)
+	}
+	job, msg := newJobFromInput(companyID, input)
+	if msg != "" {
+		return model.Job{}, []string{msg}
+	}
+
+	// Checked up front so a dry run reports unknown skills too
+	unknown := []string{}
+	for _, names := range [][]string{input.RequiredSkills, input.NiceToHaveSkills} {
+		_, unknownNames, err := helper.ResolveSkillIDs(h.DB, names)
+		if err != nil {
+			slog.Error("Failed to check skills", "error", err)
+			return model.Job{}, []string{"Failed to check skills"}
+		}
+		unknown = append(unknown, unknownNames...)
+	}
+	if len(unknown) != 0 {
+		return model.Job{}, []string{"unknown skills: " + strings.Join(unknown, ", ")}
+	}
+	return job, nil
+}
+
+// parseJobImportJSON reads an array of job objects. Objects that do not decode are reported on their row.
+func parseJobImportJSON(r io.Reader) ([]jobImportRow, error) {
+	rawRows := []json.RawMessage{}
+	if err := json.NewDecoder(r).Decode(&rawRows); err != nil {
+		return nil, errors.New("file must be a JSON array of jobs")
+	}
+	rows := make([]jobImportRow, len(rawRows))
+	for i, raw := range rawRows {
+		if err := json.Unmarshal(raw, &rows[i].Input); err != nil {
+			rows[i].Errors = []string{describeJSONError(err)}
+		}
+	}
+	return rows, nil
+}
+
+// parseJobImportCSV reads a CSV file whose header names the fields of CreateJobInput.
+// Each row is converted to the JSON a client would send, so both formats decode the same way.
+func parseJobImportCSV(r io.Reader) ([]jobImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("file contains no jobs")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+
+	fields := jobImportFields()
+	columns := make([]reflect.StructField, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		field, ok := fields[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		columns[i] = field
+	}
+
+	rows := []jobImportRow{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		row := jobImportRow{}
+		if errors.Is(err, csv.ErrFieldCount) {
+			row.Errors = []string{fmt.Sprintf("row has %d columns, expected %d", len(record), len(header))}
+			rows = append(rows, row)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+
+		values := map[string]any{}
+		for i, cell := range record {
+			cell = strings.TrimSpace(cell)
+			// Empty cells are left out, as if the field was not sent
+			if cell == "" {
+				continue
+			}
+			name := jsonFieldName(columns[i])
+			value, err := parseJobImportCell(columns[i].Type, cell)
+			if err != nil {
+				row.Errors = append(row.Errors, fmt.Sprintf("%s: %s", name, err.Error()))
+				continue
+			}
+			values[name] = value
+		}
+		if len(row.Errors) == 0 {
+			data, err := json.Marshal(values)
+			if err == nil {
+				err = json.Unmarshal(data, &row.Input)
+			}
+			if err != nil {
+				row.Errors = []string{describeJSONError(err)}
+			}
+		}
+		if name, ok := values["name"].(string); ok {
+			row.Input.Name = name
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// parseJobImportCell converts a CSV cell to the JSON value of a field of the given type.
+func parseJobImportCell(fieldType reflect.Type, cell string) (any, error) {
+	if fieldType.Kind() == reflect.Pointer {
+		fieldType = fieldType.Elem()
+	}
+	if fieldType == reflect.TypeOf(time.Time{}) {
+		if _, err := time.Parse(time.RFC3339, cell); err != nil {
+			return nil, errors.New("must be an RFC3339 time")
+		}
+		return cell, nil
+	}
+	switch fieldType.Kind() {
+	case reflect.Uint:
+		value, err := strconv.ParseUint(cell, 10, 64)
+		if err != nil {
+			return nil, errors.New("must be a whole number")
+		}
+		return value, nil
+	case reflect.Bool:
+		value, err := strconv.ParseBool(cell)
+		if err != nil {
+			return nil, errors.New("must be true or false")
+		}
+		return value, nil
+	case reflect.Slice:
+		// Lists of objects, like screening questions, are given as JSON
+		if fieldType.Elem().Kind() == reflect.Struct {
+			values := []any{}
+			if err := json.Unmarshal([]byte(cell), &values); err != nil {
+				return nil, errors.New("must be a JSON list")
+			}
+			return values, nil
+		}
+		values := []string{}
+		for _, value := range strings.Split(cell, jobImportListSeparator) {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+		return values, nil
+	default:
+		return cell, nil
+	}
+}
+
+// jobImportFields maps the lower-cased JSON names of CreateJobInput to its fields.
+func jobImportFields() map[string]reflect.StructField {
+	inputType := reflect.TypeOf(CreateJobInput{})
+	fields := make(map[string]reflect.StructField, inputType.NumField())
+	for i := 0; i < inputType.NumField(); i++ {
+		field := inputType.Field(i)
+		fields[strings.ToLower(jsonFieldName(field))] = field
+	}
+	return fields
+}
+
+// jsonFieldName is the name a struct field has in JSON.
+func jsonFieldName(field reflect.StructField) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" {
+		return name
+	}
+	return field.Name
+}
+
+// describeValidationErrors turns binding errors into one message per failed rule, with fields named as in JSON.
+func describeValidationErrors(err error, input any) []string {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return []string{err.Error()}
+	}
+	inputType := reflect.TypeOf(input)
+	if inputType.Kind() == reflect.Pointer {
+		inputType = inputType.Elem()
+	}
+	messages := make([]string, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		// List elements are reported as "Field[i]"
+		structField, index, _ := strings.Cut(fieldErr.StructField(), "[")
+		name := fieldErr.Field()
+		if field, ok := inputType.FieldByName(structField); ok {
+			name = jsonFieldName(field)
+			if index != "" {
+				name += "[" + index
+			}
+		}
+		rule := fieldErr.Tag()
+		if fieldErr.Param() != "" {
+			rule += "=" + fieldErr.Param()
+		}
+		messages = append(messages, fmt.Sprintf("%s failed the %s rule", name, rule))
+	}
+	return messages
+}
+
+// describeJSONError words a decoding error without Go type names.
+func describeJSONError(err error) string {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return fmt.Sprintf("%s has the wrong type", typeErr.Field)
+	}
+	var timeErr *time.ParseError
+	if errors.As(err, &timeErr) {
+		return "times must be RFC3339"
+	}
+	return "invalid job object"
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"fmt"
+	"ku-work/backend/model"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// MAX_PIPELINE_STAGES is the most hiring stages a job can define
+const MAX_PIPELINE_STAGES = 16
+
+var stageKeyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// PipelineStageInput is a hiring stage as sent by companies when creating or editing a job.
+// Outcome is the status students see while their application is in the stage.
+type PipelineStageInput struct {
+	Key     string `json:"key" binding:"required,max=32"`
+	Name    string `json:"name" binding:"required,max=64"`
+	Outcome string `json:"outcome" binding:"required,oneof=pending accepted rejected"`
+}
+
+// StageCount is the number of active applications in one stage of a job's pipeline.
+type StageCount struct {
+	Key     string `json:"key"`
+	Name    string `json:"name"`
+	Outcome string `json:"outcome"`
+	Count   int64  `json:"count"`
+}
+
+// newPipeline validates pipeline stage inputs and builds the pipeline in the order given.
+// An empty list gives an empty pipeline, meaning the job uses the default one.
+// Returns an error message suitable for the client, or an empty string if the input is valid.
+func newPipeline(inputs []PipelineStageInput) (datatypes.JSONSlice[model.PipelineStage], string) {
+	if len(inputs) == 0 {
+		return nil, ""
+	}
+	if len(inputs) > MAX_PIPELINE_STAGES {
+		return nil, fmt.Sprintf("a pipeline can have at most %d stages", MAX_PIPELINE_STAGES)
+	}
+	stages := make(datatypes.JSONSlice[model.PipelineStage], 0, len(inputs))
+	for i, input := range inputs {
+		key := strings.ToLower(strings.TrimSpace(input.Key))
+		if !stageKeyPattern.MatchString(key) {
+			return nil, fmt.Sprintf("pipeline[%d] key may only contain letters, digits, - and _", i)
+		}
+		if model.FindStage(stages, key) != -1 {
+			return nil, fmt.Sprintf("pipeline has the stage %q more than once", key)
+		}
+		stages = append(stages, model.PipelineStage{
+			Key:     key,
+			Name:    strings.TrimSpace(input.Name),
+			Outcome: model.JobApplicationStatus(input.Outcome),
+		})
+	}
+	if stages[0].Outcome != model.JobApplicationPending {
+		return nil, "the first pipeline stage must be pending, it is where new applications start"
+	}
+	for _, outcome := range []model.JobApplicationStatus{model.JobApplicationAccepted, model.JobApplicationRejected} {
+		if model.FirstStageWithOutcome(stages, outcome) == -1 {
+			return nil, fmt.Sprintf("the pipeline needs a stage with the %s outcome", outcome)
+		}
+	}
+	return stages, ""
+}
+
+// stagedApplicationCount is the number of active applications of a job with a given stage and status.
+type stagedApplicationCount struct {
+	JobID  uint
+	Stage  string
+	Status model.JobApplicationStatus
+	Count  int64
+}
+
+// loadStagedApplicationCounts counts the applications of the jobs that were not withdrawn, by stage and status.
+func loadStagedApplicationCounts(db *gorm.DB, jobIDs []uint) ([]stagedApplicationCount, error) {
+	counts := []stagedApplicationCount{}
+	if len(jobIDs) == 0 {
+		return counts, nil
+	}
+	err := db.Model(&model.JobApplication{}).
+		Select("job_id, stage, status, COUNT(*) AS count").
+		Where("job_id IN ? AND status <> ?", jobIDs, model.JobApplicationWithdrawn).
+		Group("job_id, stage, status").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// stageCounts places the counted applications of a job in its pipeline stages.
+// Every stage is listed, including empty ones.
+func stageCounts(stages []model.PipelineStage, jobID uint, counts []stagedApplicationCount) []StageCount {
+	result := make([]StageCount, 0, len(stages))
+	for _, stage := range stages {
+		result = append(result, StageCount{Key: stage.Key, Name: stage.Name, Outcome: string(stage.Outcome)})
+	}
+	for _, count := range counts {
+		if count.JobID != jobID {
+			continue
+		}
+		if i := model.ApplicationStage(stages, count.Stage, count.Status); i != -1 {
+			result[i].Count += count.Count
+		}
+	}
+	return result
+}
+
+// orphanedStages lists the stages of the current pipeline that still hold applications
+// but have no stage with the same key and outcome in the new one.
+func orphanedStages(db *gorm.DB, jobID uint, current []model.PipelineStage, next []model.PipelineStage) ([]string, error) {
+	counts, err := loadStagedApplicationCounts(db, []uint{jobID})
+	if err != nil {
+		return nil, err
+	}
+	orphaned := []string{}
+	for _, count := range stageCounts(current, jobID, counts) {
+		if count.Count == 0 || slices.Contains(orphaned, count.Key) {
+			continue
+		}
+		if i := model.FindStage(next, count.Key); i == -1 || string(next[i].Outcome) != count.Outcome {
+			orphaned = append(orphaned, count.Key)
+		}
+	}
+	return orphaned, nil
+}
+
+// stageScope builds the condition matching the applications in a stage of the pipeline,
+// placing applications the same way as model.ApplicationStage. Reports false if there is no such stage.
+func stageScope(db *gorm.DB, stages []model.PipelineStage, key string) (*gorm.DB, bool) {
+	i := model.FindStage(stages, key)
+	if i == -1 {
+		return nil, false
+	}
+	outcome := stages[i].Outcome
+	condition := db.Where("job_applications.stage = ? AND job_applications.status = ?", key, outcome)
+	if i == model.FirstStageWithOutcome(stages, outcome) {
+		sameOutcome := []string{}
+		for _, stage := range stages {
+			if stage.Outcome == outcome {
+				sameOutcome = append(sameOutcome, stage.Key)
+			}
+		}
+		condition = condition.Or("job_applications.status = ? AND job_applications.stage NOT IN ?", outcome, sameOutcome)
+	}
+	return condition, true
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"ku-work/backend/model"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// MIMEJSONLD is the media type of JSON-LD documents.
+const MIMEJSONLD = "application/ld+json"
+
+// employmentTypes maps job types to the employmentType values understood by search engines.
+var employmentTypes = map[model.JobType]string{
+	model.JobTypeFullTime:   "FULL_TIME",
+	model.JobTypePartTime:   "PART_TIME",
+	model.JobTypeContract:   "CONTRACTOR",
+	model.JobTypeCasual:     "TEMPORARY",
+	model.JobTypeInternship: "INTERN",
+}
+
+// salaryUnits maps pay periods to the unitText of a schema.org QuantitativeValue.
+var salaryUnits = map[model.SalaryPeriod]string{
+	model.SalaryPeriodHour:  "HOUR",
+	model.SalaryPeriodDay:   "DAY",
+	model.SalaryPeriodMonth: "MONTH",
+	model.SalaryPeriodYear:  "YEAR",
+}
+
+// JobPosting is a job as a schema.org JobPosting, the structured data read by Google for Jobs.
+type JobPosting struct {
+	Context                       string               `json:"@context"`
+	Type                          string               `json:"@type"`
+	Title                         string               `json:"title"`
+	Description                   string               `json:"description"`
+	URL                           string               `json:"url"`
+	Identifier                    JobPostingIdentifier `json:"identifier"`
+	DatePosted                    string               `json:"datePosted"`
+	ValidThrough                  string               `json:"validThrough,omitempty"`
+	EmploymentType                string               `json:"employmentType,omitempty"`
+	HiringOrganization            JobPostingOrg        `json:"hiringOrganization"`
+	JobLocation                   *JobPostingPlace     `json:"jobLocation,omitempty"`
+	JobLocationType               string               `json:"jobLocationType,omitempty"`
+	ApplicantLocationRequirements *JobPostingCountry   `json:"applicantLocationRequirements,omitempty"`
+	BaseSalary                    *JobPostingSalary    `json:"baseSalary,omitempty"`
+	OccupationalCategory          string               `json:"occupationalCategory,omitempty"`
+}
+
+type JobPostingIdentifier struct {
+	Type  string `json:"@type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type JobPostingOrg struct {
+	Type   string `json:"@type"`
+	Name   string `json:"name"`
+	SameAs string `json:"sameAs,omitempty"`
+	Logo   string `json:"logo,omitempty"`
+}
+
+type JobPostingPlace struct {
+	Type    string              `json:"@type"`
+	Address JobPostingAddress   `json:"address"`
+	Geo     *JobPostingGeoPoint `json:"geo,omitempty"`
+}
+
+type JobPostingAddress struct {
+	Type            string `json:"@type"`
+	StreetAddress   string `json:"streetAddress,omitempty"`
+	AddressLocality string `json:"addressLocality,omitempty"`
+	AddressRegion   string `json:"addressRegion,omitempty"`
+	AddressCountry  string `json:"addressCountry,omitempty"`
+}
+
+type JobPostingGeoPoint struct {
+	Type      string  `json:"@type"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type JobPostingCountry struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+type JobPostingSalary struct {
+	Type     string                `json:"@type"`
+	Currency string                `json:"currency"`
+	Value    JobPostingSalaryValue `json:"value"`
+}
+
+type JobPostingSalaryValue struct {
+	Type     string `json:"@type"`
+	MinValue uint   `json:"minValue"`
+	MaxValue uint   `json:"maxValue"`
+	UnitText string `json:"unitText"`
+}
+
+// jobPostingRow is a job joined with the company fields a JobPosting needs.
+type jobPostingRow struct {
+	model.Job
+	CompanyName    string
+	CompanyWebsite string
+	CompanyPhotoID string
+	CompanyCountry string
+	PublishedAt    time.Time
+}
+
+// loadJobPostingRow fetches a job with its company for a JobPosting.
+func loadJobPostingRow(db *gorm.DB, jobID uint) (jobPostingRow, error) {
+	row := jobPostingRow{}
+	err := db.Model(&model.Job{}).
+		Select("jobs.*, users.username AS company_name, companies.website AS company_website, companies.photo_id AS company_photo_id, companies.country AS company_country, "+jobPublishedAtExpr+" AS published_at").
+		Joins("INNER JOIN users ON users.id = jobs.company_id").
+		Joins("INNER JOIN companies ON companies.user_id = jobs.company_id").
+		Where("jobs.id = ?", jobID).
+		Take(&row).Error
+	return row, err
+}
+
+// newJobPosting maps a job to a schema.org JobPosting.
+// Links point to the job board at frontendURL and files are served from publicAPIURL.
+func newJobPosting(row jobPostingRow, frontendURL string, publicAPIURL string) JobPosting {
+	posting := JobPosting{
+		Context:     "https://schema.org/",
+		Type:        "JobPosting",
+		Title:       row.Name,
+		Description: row.Description,
+		URL:         frontendJobURL(frontendURL, row.ID),
+		Identifier: JobPostingIdentifier{
+			Type:  "PropertyValue",
+			Name:  row.CompanyName,
+			Value: strconv.FormatUint(uint64(row.ID), 10),
+		},
+		DatePosted:           row.PublishedAt.UTC().Format(time.RFC3339),
+		EmploymentType:       employmentTypes[row.JobType],
+		OccupationalCategory: row.Position,
+		HiringOrganization: JobPostingOrg{
+			Type:   "Organization",
+			Name:   row.CompanyName,
+			SameAs: row.CompanyWebsite,
+		},
+	}
+	if row.CompanyPhotoID != "" {
+		posting.HiringOrganization.Logo = fmt.Sprintf("%s/files/%s", publicAPIURL, row.CompanyPhotoID)
+	}
+
+	// The posting stops being valid at whichever comes first, the deadline or the end of the publishing window
+	validThrough := row.ApplicationDeadline
+	if row.UnpublishAt != nil && (validThrough == nil || row.UnpublishAt.Before(*validThrough)) {
+		validThrough = row.UnpublishAt
+	}
+	if validThrough != nil {
+		posting.ValidThrough = validThrough.UTC().Format(time.RFC3339)
+	}
+
+	if row.WorkMode == model.WorkModeRemote {
+		posting.JobLocationType = "TELECOMMUTE"
+		posting.ApplicantLocationRequirements = &JobPostingCountry{Type: "Country", Name: "TH"}
+	} else {
+		address := JobPostingAddress{
+			Type:          "PostalAddress",
+			StreetAddress: row.Location,
+		}
+		if row.Province != "" {
+			// Provinces come from the bundled Thai dataset
+			address.AddressLocality = row.District
+			address.AddressRegion = row.Province
+			address.AddressCountry = "TH"
+		} else {
+			address.AddressCountry = row.CompanyCountry
+		}
+		posting.JobLocation = &JobPostingPlace{Type: "Place", Address: address}
+		if row.Latitude != nil && row.Longitude != nil {
+			posting.JobLocation.Geo = &JobPostingGeoPoint{Type: "GeoCoordinates", Latitude: *row.Latitude, Longitude: *row.Longitude}
+		}
+	}
+
+	if !row.SalaryUndisclosed {
+		posting.BaseSalary = &JobPostingSalary{
+			Type:     "MonetaryAmount",
+			Currency: row.SalaryCurrency,
+			Value: JobPostingSalaryValue{
+				Type:     "QuantitativeValue",
+				MinValue: row.MinSalary,
+				MaxValue: row.MaxSalary,
+				UnitText: salaryUnits[row.SalaryPeriod],
+			},
+		}
+	}
+	return posting
+}
+
+// @Summary Get a job as JobPosting JSON-LD
+// @Description Returns an approved, open job as schema.org JobPosting structured data for search engines. This is a public endpoint, responses may be cached and support conditional GET with If-None-Match or If-Modified-Since.
+// @Tags Feeds
+// @Produce json
+// @Param id path uint true "Job ID"
+// @Success 200 {object} handlers.JobPosting "JobPosting JSON-LD"
+// @Success 304 "Not Modified"
+// @Failure 400 {object} object{error=string} "Bad Request"
+// @Failure 404 {object} object{error=string} "Not Found"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /feeds/jobs/{id} [get]
+func (h *FeedHandlers) GetJobPostingHandler(ctx *gin.Context) {
+	jobId64, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil || jobId64 <= 0 || jobId64 > math.MaxUint32 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid job id"})
+		return
+	}
+
+	type listedJob struct {
+		ID        uint
+		UpdatedAt time.Time
+	}
+	listed := listedJob{}
+	if err := h.DB.Model(&model.Job{}).
+		Select("jobs.id, "+jobUpdatedAtExpr+" AS updated_at").
+		Scopes(listedJobsScope(time.Now())).
+		Where("jobs.id = ?", uint(jobId64)).
+		Take(&listed).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+		} else {
+			msg := "Failed to fetch job"
+			slog.Error(msg, "error", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		}
+		return
+	}
+	etag := fmt.Sprintf(`"%d-%d"`, listed.ID, listed.UpdatedAt.UnixNano())
+	if writeCacheValidators(ctx, etag, listed.UpdatedAt) {
+		return
+	}
+
+	row, err := loadJobPostingRow(h.DB, listed.ID)
+	if err != nil {
+		msg := "Failed to fetch job"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+	writeJSONLD(ctx, newJobPosting(row, h.frontendURL, h.publicAPIURL))
+}
+
+// writeJSONLD responds with a JSON-LD document.
+func writeJSONLD(ctx *gin.Context, doc any) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		msg := "Failed to render job posting"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+	ctx.Data(http.StatusOK, MIMEJSONLD+"; charset=utf-8", data)
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"ku-work/backend/model"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// MAX_JOB_QUESTIONS is the most screening questions a job can ask
+	MAX_JOB_QUESTIONS = 20
+	// MAX_SHORT_ANSWER_LENGTH and MAX_LONG_ANSWER_LENGTH limit text answers, in characters
+	MAX_SHORT_ANSWER_LENGTH = 256
+	MAX_LONG_ANSWER_LENGTH  = 4096
+)
+
+// JobQuestionInput is a screening question as sent by companies when creating or editing a job.
+// Choices are required for single_choice and multi_choice questions and not allowed for the other types.
+type JobQuestionInput struct {
+	Type     string   `json:"type" binding:"required,oneof=short_text long_text single_choice multi_choice date url yes_no"`
+	Prompt   string   `json:"prompt" binding:"required,max=512"`
+	Required bool     `json:"required"`
+	Choices  []string `json:"choices" binding:"max=32,dive,required,max=128"`
+}
+
+// ApplicationAnswerInput is a student's answer to one screening question.
+// Value is a JSON string, a list of strings for multi_choice questions or a bool for yes_no questions.
+type ApplicationAnswerInput struct {
+	QuestionID uint            `json:"questionId"`
+	Value      json.RawMessage `json:"value"`
+}
+
+// newJobQuestions validates screening question inputs and builds the questions in the order given.
+// Returns an error message suitable for the client, or an empty string if the input is valid.
+func newJobQuestions(inputs []JobQuestionInput) ([]model.JobQuestion, string) {
+	if len(inputs) > MAX_JOB_QUESTIONS {
+		return nil, fmt.Sprintf("a job can have at most %d questions", MAX_JOB_QUESTIONS)
+	}
+	questions := make([]model.JobQuestion, 0, len(inputs))
+	for i, input := range inputs {
+		questionType := model.JobQuestionType(input.Type)
+		hasChoices := questionType == model.JobQuestionSingleChoice || questionType == model.JobQuestionMultiChoice
+		if hasChoices && len(input.Choices) < 2 {
+			return nil, fmt.Sprintf("questions[%d] needs at least two choices", i)
+		}
+		if !hasChoices && len(input.Choices) != 0 {
+			return nil, fmt.Sprintf("questions[%d] of type %s cannot have choices", i, input.Type)
+		}
+		choices := make([]string, 0, len(input.Choices))
+		for _, choice := range input.Choices {
+			choice = strings.TrimSpace(choice)
+			if slices.Contains(choices, choice) {
+				return nil, fmt.Sprintf("questions[%d] has the choice %q more than once", i, choice)
+			}
+			choices = append(choices, choice)
+		}
+		questions = append(questions, model.JobQuestion{
+			Position: uint(i),
+			Type:     questionType,
+			Prompt:   strings.TrimSpace(input.Prompt),
+			Required: input.Required,
+			Choices:  choices,
+		})
+	}
+	return questions, ""
+}
+
+// loadJobQuestions fetches a job's screening questions in the order they are asked.
+func loadJobQuestions(db *gorm.DB, jobID uint) ([]model.JobQuestion, error) {
+	questions := []model.JobQuestion{}
+	err := db.Where("job_id = ?", jobID).Order("position ASC").Find(&questions).Error
+	return questions, err
+}
+
+// validateApplicationAnswers checks a student's answers against a job's questions and returns them in question order.
+// Optional questions may be left out. Returns an error message suitable for the client, or an empty string if the answers are valid.
+func validateApplicationAnswers(questions []model.JobQuestion, inputs []ApplicationAnswerInput) ([]model.ApplicationAnswer, string) {
+	values := make(map[uint]json.RawMessage, len(inputs))
+	for _, input := range inputs {
+		if !slices.ContainsFunc(questions, func(question model.JobQuestion) bool { return question.ID == input.QuestionID }) {
+			return nil, fmt.Sprintf("question %d is not asked by this job", input.QuestionID)
+		}
+		if _, ok := values[input.QuestionID]; ok {
+			return nil, fmt.Sprintf("question %d is answered more than once", input.QuestionID)
+		}
+		values[input.QuestionID] = input.Value
+	}
+
+	answers := make([]model.ApplicationAnswer, 0, len(values))
+	for _, question := range questions {
+		raw, ok := values[question.ID]
+		if ok && (len(raw) == 0 || string(raw) == "null") {
+			ok = false
+		}
+		var value any
+		if ok {
+			var msg string
+			if value, msg = parseAnswerValue(question, raw); msg != "" {
+				return nil, fmt.Sprintf("question %d %s", question.ID, msg)
+			}
+			// Blank text and empty selections count as unanswered
+			if text, isText := value.(string); isText && text == "" {
+				ok = false
+			} else if selected, isList := value.([]string); isList && len(selected) == 0 {
+				ok = false
+			}
+		}
+		if !ok {
+			if question.Required {
+				return nil, fmt.Sprintf("question %d is required", question.ID)
+			}
+			continue
+		}
+		answers = append(answers, model.ApplicationAnswer{
+			QuestionID: question.ID,
+			Prompt:     question.Prompt,
+			Type:       question.Type,
+			Value:      value,
+		})
+	}
+	return answers, ""
+}
+
+// parseAnswerValue decodes and checks an answer to a question of the given type.
+// Returns the value to store, or a message describing what is wrong with it.
+func parseAnswerValue(question model.JobQuestion, raw json.RawMessage) (any, string) {
+	switch question.Type {
+	case model.JobQuestionYesNo:
+		var value bool
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, "must be answered with true or false"
+		}
+		return value, ""
+	case model.JobQuestionMultiChoice:
+		var selected []string
+		if err := json.Unmarshal(raw, &selected); err != nil {
+			return nil, "must be answered with a list of choices"
+		}
+		for i, choice := range selected {
+			if !slices.Contains(question.Choices, choice) {
+				return nil, fmt.Sprintf("has no choice %q", choice)
+			}
+			if slices.Contains(selected[:i], choice) {
+				return nil, fmt.Sprintf("has the choice %q selected more than once", choice)
+			}
+		}
+		return selected, ""
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err != nil {
+		return nil, "must be answered with a string"
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return text, ""
+	}
+	switch question.Type {
+	case model.JobQuestionShortText:
+		if utf8.RuneCountInString(text) > MAX_SHORT_ANSWER_LENGTH {
+			return nil, fmt.Sprintf("must be at most %d characters", MAX_SHORT_ANSWER_LENGTH)
+		}
+	case model.JobQuestionLongText:
+		if utf8.RuneCountInString(text) > MAX_LONG_ANSWER_LENGTH {
+			return nil, fmt.Sprintf("must be at most %d characters", MAX_LONG_ANSWER_LENGTH)
+		}
+	case model.JobQuestionSingleChoice:
+		if !slices.Contains(question.Choices, text) {
+			return nil, fmt.Sprintf("has no choice %q", text)
+		}
+	case model.JobQuestionDate:
+		if _, err := time.Parse(time.DateOnly, text); err != nil {
+			return nil, "must be a date in YYYY-MM-DD format"
+		}
+	case model.JobQuestionURL:
+		link, err := url.ParseRequestURI(text)
+		if err != nil || (link.Scheme != "http" && link.Scheme != "https") || link.Host == "" || len(text) > 2048 {
+			return nil, "must be an http or https URL"
+		}
+	}
+	return text, ""
+}
+
+// replaceJobQuestions swaps all of a job's screening questions for new ones.
+// Answers already given keep their copy of the old prompts.
+func replaceJobQuestions(tx *gorm.DB, jobID uint, questions []model.JobQuestion) error {
+	if err := tx.Where("job_id = ?", jobID).Delete(&model.JobQuestion{}).Error; err != nil {
+		return err
+	}
+	if len(questions) == 0 {
+		return nil
+	}
+	for i := range questions {
+		questions[i].JobID = jobID
+	}
+	return tx.Create(&questions).Error
+}
+
+// sameJobQuestions reports whether two lists ask the same questions in the same order.
+func sameJobQuestions(a []model.JobQuestion, b []model.JobQuestion) bool {
+	return slices.EqualFunc(a, b, func(x model.JobQuestion, y model.JobQuestion) bool {
+		return x.Type == y.Type && x.Prompt == y.Prompt && x.Required == y.Required && slices.Equal(x.Choices, y.Choices)
+	})
+}
+
+// jobQuestionPrompts lists the prompts of the questions, to record question changes in a job's revisions.
+func jobQuestionPrompts(questions []model.JobQuestion) []string {
+	prompts := make([]string, 0, len(questions))
+	for _, question := range questions {
+		prompts = append(prompts, question.Prompt)
+	}
+	return prompts
+}
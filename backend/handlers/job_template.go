@@ -0,0 +1,490 @@
+package handlers
+
+import (
+	"io"
+	"ku-work/backend/helper"
+	"ku-work/backend/model"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// JobTemplateInput defines the request body for creating or replacing a job template.
+// Every job field is optional, missing ones must be filled in when the template is instantiated.
+type JobTemplateInput struct {
+	Title               string   `json:"title" binding:"required,max=128"`
+	Name                string   `json:"name" binding:"max=128"`
+	Position            string   `json:"position" binding:"max=128"`
+	Duration            string   `json:"duration" binding:"max=128"`
+	Description         string   `json:"description" binding:"max=16384"`
+	Location            string   `json:"location" binding:"max=128"`
+	WorkMode            string   `json:"workMode" binding:"omitempty,oneof=onsite hybrid remote"`
+	Province            string   `json:"province" binding:"max=64"`
+	District            string   `json:"district" binding:"max=64"`
+	JobType             string   `json:"jobType" binding:"omitempty,oneof='fulltime' 'parttime' 'contract' 'casual' 'internship'"`
+	Experience          string   `json:"experience" binding:"omitempty,oneof='newgrad' 'junior' 'senior' 'manager' 'internship'"`
+	MinSalary           uint     `json:"minSalary"`
+	MaxSalary           uint     `json:"maxSalary"`
+	SalaryCurrency      string   `json:"salaryCurrency" binding:"omitempty,iso4217"`
+	SalaryPeriod        string   `json:"salaryPeriod" binding:"omitempty,oneof=hour day month year"`
+	SalaryUndisclosed   bool     `json:"salaryUndisclosed"`
+	NotifyOnApplication *bool    `json:"notifyOnApplication"`
+	RequiredSkills      []string `json:"requiredSkills" binding:"max=32,dive,max=64"`
+	NiceToHaveSkills    []string `json:"niceToHaveSkills" binding:"max=32,dive,max=64"`
+}
+
+// @Summary Clone a job listing
+// @Description Copies one of the company's own jobs into a new closed draft that goes through the normal approval flow. Fields in the optional body override the copied ones. Deadlines and publishing windows are not copied.
+// @Tags Jobs
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path uint true "ID of the job to clone"
+// @Param overrides body handlers.EditJobInput false "Fields to change in the clone"
+// @Success 200 {object} object{id=uint} "ID of the new job"
+// @Failure 400 {object} object{error=string} "Bad Request"
+// @Failure 403 {object} object{error=string} "Forbidden"
+// @Failure 404 {object} object{error=string} "Not Found"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /jobs/{id}/clone [post]
+func (h *JobHandlers) CloneJobHandler(ctx *gin.Context) {
+	userId := ctx.MustGet("userID").(string)
+
+	input := EditJobInput{}
+	if err := ctx.ShouldBindJSON(&input); err != nil && err != io.EOF {
+		msg := "Failed to bind input"
+		slog.Debug(msg, "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	jobIdStr := ctx.Param("id")
+	jobId64, err := strconv.ParseUint(jobIdStr, 10, 64)
+	if err != nil || jobId64 <= 0 || jobId64 > math.MaxUint32 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid job id"})
+		return
+	}
+
+	source := model.Job{}
+	if err := h.DB.Take(&source, uint(jobId64)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+		} else {
+			msg := "Failed to fetch job"
+			slog.Error(msg, "error", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		}
+		return
+	}
+	if source.CompanyID != userId {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		return
+	}
+
+	required, niceToHave, err := currentJobSkillNames(h.DB, source.ID)
+	if err != nil {
+		msg := "Failed to fetch job skills"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+	questions, err := loadJobQuestions(h.DB, source.ID)
+	if err != nil {
+		msg := "Failed to fetch job questions"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+	for i := range questions {
+		questions[i].ID = 0
+		questions[i].JobID = 0
+	}
+
+	job := model.Job{
+		Name:                    source.Name,
+		CompanyID:               source.CompanyID,
+		Position:                source.Position,
+		Duration:                source.Duration,
+		Description:             source.Description,
+		Location:                source.Location,
+		WorkMode:                source.WorkMode,
+		Province:                source.Province,
+		District:                source.District,
+		Latitude:                source.Latitude,
+		Longitude:               source.Longitude,
+		JobType:                 source.JobType,
+		Experience:              source.Experience,
+		MinSalary:               source.MinSalary,
+		MaxSalary:               source.MaxSalary,
+		SalaryCurrency:          source.SalaryCurrency,
+		SalaryPeriod:            source.SalaryPeriod,
+		SalaryUndisclosed:       source.SalaryUndisclosed,
+		NotifyOnApplication:     source.NotifyOnApplication,
+		Openings:                source.Openings,
+		NotifyPendingWhenFilled: source.NotifyPendingWhenFilled,
+		ClonedFromID:            &source.ID,
+		Questions:               questions,
+		Pipeline:                source.Pipeline,
+	}
+	h.createJobFromBase(ctx, job, required, niceToHave, &input)
+}
+
+// @Summary List job templates
+// @Description Retrieves the authenticated company's job templates.
+// @Tags Job Templates
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} object{templates=[]model.JobTemplate} "List of job templates"
+// @Failure 403 {object} object{error=string} "Forbidden: Only companies can manage job templates"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /job-templates [get]
+func (h *JobHandlers) GetJobTemplatesHandler(ctx *gin.Context) {
+	userId := ctx.MustGet("userID").(string)
+	if helper.GetRole(userId, h.DB) != helper.Company {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "only companies can manage job templates"})
+		return
+	}
+
+	templates := []model.JobTemplate{}
+	if err := h.DB.Where("company_id = ?", userId).Order("title ASC").Find(&templates).Error; err != nil {
+		msg := "Failed to fetch job templates"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"templates": templates})
+}
+
+// @Summary Create a job template
+// @Description Saves a named, reusable job post for the authenticated company. Template titles are unique per company.
+// @Tags Job Templates
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param template body handlers.JobTemplateInput true "Job template data"
+// @Success 200 {object} model.JobTemplate "Created job template"
+// @Failure 400 {object} object{error=string} "Bad Request"
+// @Failure 403 {object} object{error=string} "Forbidden: Only companies can manage job templates"
+// @Failure 409 {object} object{error=string} "Conflict: Title already in use"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /job-templates [post]
+func (h *JobHandlers) CreateJobTemplateHandler(ctx *gin.Context) {
+	userId := ctx.MustGet("userID").(string)
+	if helper.GetRole(userId, h.DB) != helper.Company {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "only companies can manage job templates"})
+		return
+	}
+
+	template := model.JobTemplate{CompanyID: userId}
+	if !h.applyJobTemplateInput(ctx, &template) {
+		return
+	}
+
+	if err := h.DB.Create(&template).Error; err != nil {
+		msg := "Failed to create job template"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, template)
+}
+
+// @Summary Replace a job template
+// @Description Overwrites one of the authenticated company's job templates. Jobs created from it are not changed.
+// @Tags Job Templates
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path uint true "Job template ID"
+// @Param template body handlers.JobTemplateInput true "Job template data"
+// @Success 200 {object} model.JobTemplate "Updated job template"
+// @Failure 400 {object} object{error=string} "Bad Request"
+// @Failure 404 {object} object{error=string} "Not Found"
+// @Failure 409 {object} object{error=string} "Conflict: Title already in use"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /job-templates/{id} [put]
+func (h *JobHandlers) EditJobTemplateHandler(ctx *gin.Context) {
+	userId := ctx.MustGet("userID").(string)
+
+	template, ok := h.findOwnJobTemplate(ctx, userId)
+	if !ok {
+		return
+	}
+	if !h.applyJobTemplateInput(ctx, &template) {
+		return
+	}
+
+	if err := h.DB.Save(&template).Error; err != nil {
+		msg := "Failed to update job template"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, template)
+}
+
+// @Summary Delete a job template
+// @Description Deletes one of the authenticated company's job templates. Jobs created from it are kept.
+// @Tags Job Templates
+// @Security BearerAuth
+// @Produce json
+// @Param id path uint true "Job template ID"
+// @Success 200 {object} object{message=string} "ok"
+// @Failure 400 {object} object{error=string} "Bad Request"
+// @Failure 404 {object} object{error=string} "Not Found"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /job-templates/{id} [delete]
+func (h *JobHandlers) DeleteJobTemplateHandler(ctx *gin.Context) {
+	userId := ctx.MustGet("userID").(string)
+
+	template, ok := h.findOwnJobTemplate(ctx, userId)
+	if !ok {
+		return
+	}
+
+	if err := h.DB.Delete(&template).Error; err != nil {
+		msg := "Failed to delete job template"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "ok"})
+}
+
+// @Summary Create a job from a template
+// @Description Creates a new closed draft job from one of the company's templates. Fields in the optional body override the template. The job goes through the normal approval flow.
+// @Tags Job Templates
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path uint true "Job template ID"
+// @Param overrides body handlers.EditJobInput false "Fields to set on the new job"
+// @Success 200 {object} object{id=uint} "ID of the new job"
+// @Failure 400 {object} object{error=string} "Bad Request"
+// @Failure 404 {object} object{error=string} "Not Found"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /job-templates/{id}/jobs [post]
+func (h *JobHandlers) InstantiateJobTemplateHandler(ctx *gin.Context) {
+	userId := ctx.MustGet("userID").(string)
+
+	input := EditJobInput{}
+	if err := ctx.ShouldBindJSON(&input); err != nil && err != io.EOF {
+		msg := "Failed to bind input"
+		slog.Debug(msg, "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	template, ok := h.findOwnJobTemplate(ctx, userId)
+	if !ok {
+		return
+	}
+
+	job := model.Job{
+		Name:                template.Name,
+		CompanyID:           template.CompanyID,
+		Position:            template.Position,
+		Duration:            template.Duration,
+		Description:         template.Description,
+		Location:            template.Location,
+		WorkMode:            template.WorkMode,
+		JobType:             template.JobType,
+		Experience:          template.Experience,
+		MinSalary:           template.MinSalary,
+		MaxSalary:           template.MaxSalary,
+		SalaryCurrency:      template.SalaryCurrency,
+		SalaryPeriod:        template.SalaryPeriod,
+		SalaryUndisclosed:   template.SalaryUndisclosed,
+		NotifyOnApplication: template.NotifyOnApplication,
+		TemplateID:          &template.ID,
+	}
+	if msg := setJobPlace(&job, template.Province, template.District); msg != "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	h.createJobFromBase(ctx, job, template.RequiredSkills, template.NiceToHaveSkills, &input)
+}
+
+// createJobFromBase applies the overrides to a prefilled job, then creates it as a closed draft pending approval.
+// It writes the response itself.
+func (h *JobHandlers) createJobFromBase(ctx *gin.Context, job model.Job, required []string, niceToHave []string, input *EditJobInput) {
+	job.ApprovalStatus = model.JobApprovalPending
+	if msg := applyEditJobInput(&job, input); msg != "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if msg := validateJobContent(&job); msg != "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if input.Questions != nil {
+		questions, msg := newJobQuestions(*input.Questions)
+		if msg != "" {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+		job.Questions = questions
+	}
+	if input.Pipeline != nil {
+		pipeline, msg := newPipeline(*input.Pipeline)
+		if msg != "" {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+		job.Pipeline = pipeline
+	}
+	if input.RequiredSkills != nil {
+		required = *input.RequiredSkills
+	}
+	if input.NiceToHaveSkills != nil {
+		niceToHave = *input.NiceToHaveSkills
+	}
+
+	if unknownSkills, err := insertJobWithSkills(h.DB, &job, required, niceToHave); err != nil {
+		if err == errUnknownSkills {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "unknown skills", "skills": unknownSkills})
+			return
+		}
+		msg := "Failed to create job"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"id": job.ID})
+
+	go h.aiService.AutoApproveJob(&job)
+}
+
+// findOwnJobTemplate loads the template named by the id path parameter if it belongs to the company.
+// It writes the error response itself and reports whether the handler should continue.
+func (h *JobHandlers) findOwnJobTemplate(ctx *gin.Context, userId string) (model.JobTemplate, bool) {
+	template := model.JobTemplate{}
+
+	templateId, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil || templateId <= 0 || templateId > math.MaxUint32 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid job template id"})
+		return template, false
+	}
+
+	if err := h.DB.Where("id = ? AND company_id = ?", uint(templateId), userId).Take(&template).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "job template not found"})
+		} else {
+			msg := "Failed to fetch job template"
+			slog.Error(msg, "error", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		}
+		return template, false
+	}
+	return template, true
+}
+
+// applyJobTemplateInput binds and validates the request body and copies it onto the template.
+// It writes the error response itself and reports whether the handler should continue.
+func (h *JobHandlers) applyJobTemplateInput(ctx *gin.Context, template *model.JobTemplate) bool {
+	input := JobTemplateInput{}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		msg := "Failed to bind input"
+		slog.Debug(msg, "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return false
+	}
+	if input.MinSalary > input.MaxSalary {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "minSalary cannot exceed maxSalary"})
+		return false
+	}
+
+	var titleCount int64
+	if err := h.DB.Model(&model.JobTemplate{}).
+		Where("company_id = ? AND title = ? AND id <> ?", template.CompanyID, input.Title, template.ID).
+		Count(&titleCount).Error; err != nil {
+		msg := "Failed to check job template title"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return false
+	}
+	if titleCount != 0 {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "a job template with this title already exists"})
+		return false
+	}
+
+	// Skills are checked now so instantiating the template does not fail later
+	_, unknownRequired, err := helper.ResolveSkillIDs(h.DB, input.RequiredSkills)
+	if err != nil {
+		msg := "Failed to check skills"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return false
+	}
+	_, unknownNiceToHave, err := helper.ResolveSkillIDs(h.DB, input.NiceToHaveSkills)
+	if err != nil {
+		msg := "Failed to check skills"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return false
+	}
+	if unknown := append(unknownRequired, unknownNiceToHave...); len(unknown) != 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "unknown skills", "skills": unknown})
+		return false
+	}
+
+	notifyOnApplication := true
+	if input.NotifyOnApplication != nil {
+		notifyOnApplication = *input.NotifyOnApplication
+	}
+	if input.SalaryCurrency == "" {
+		input.SalaryCurrency = model.DefaultSalaryCurrency
+	}
+	if input.SalaryPeriod == "" {
+		input.SalaryPeriod = string(model.SalaryPeriodMonth)
+	}
+	if input.SalaryUndisclosed {
+		input.MinSalary = 0
+		input.MaxSalary = 0
+	}
+	if input.WorkMode == "" {
+		input.WorkMode = string(model.WorkModeOnsite)
+	}
+	place := helper.Place{}
+	if input.Province != "" || input.District != "" {
+		if input.Province == "" {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "district requires a province"})
+			return false
+		}
+		if place, err = helper.ResolvePlace(input.Province, input.District); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return false
+		}
+	}
+
+	template.Title = input.Title
+	template.Name = input.Name
+	template.Position = input.Position
+	template.Duration = input.Duration
+	template.Description = input.Description
+	template.Location = input.Location
+	template.WorkMode = model.WorkMode(input.WorkMode)
+	template.Province = place.Province
+	template.District = place.District
+	template.JobType = model.JobType(input.JobType)
+	template.Experience = model.ExperienceType(input.Experience)
+	template.MinSalary = input.MinSalary
+	template.MaxSalary = input.MaxSalary
+	template.SalaryCurrency = input.SalaryCurrency
+	template.SalaryPeriod = model.SalaryPeriod(input.SalaryPeriod)
+	template.SalaryUndisclosed = input.SalaryUndisclosed
+	template.NotifyOnApplication = notifyOnApplication
+	template.RequiredSkills = datatypes.JSONSlice[string](input.RequiredSkills)
+	template.NiceToHaveSkills = datatypes.JSONSlice[string](input.NiceToHaveSkills)
+	return true
+}
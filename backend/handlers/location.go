@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"ku-work/backend/helper"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LocationHandlers struct{}
+
+func NewLocationHandlers() *LocationHandlers {
+	return &LocationHandlers{}
+}
+
+// @Summary List provinces and districts
+// @Description Retrieves the bundled Thai location dataset that job provinces and districts are checked against, with approximate centre coordinates.
+// @Description Job districts that are not listed are accepted too, they are placed at the centre of their province.
+// @Tags Locations
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} object{provinces=[]helper.Province} "List of provinces with their districts"
+// @Router /locations/provinces [get]
+func (h *LocationHandlers) GetProvincesHandler(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"provinces": helper.Provinces()})
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"fmt"
+	"ku-work/backend/database"
+	"ku-work/backend/helper"
+	"ku-work/backend/model"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Weights of each signal in a recommendation score.
+// Skills the job requires count the most, past behaviour only nudges the order.
+const (
+	recommendRequiredSkillWeight = 3
+	recommendOptionalSkillWeight = 1
+	recommendMajorWeight         = 2
+	recommendHistorySkillWeight  = 1
+	recommendHistoryTypeWeight   = 1
+	recommendHistoryCompany      = 1
+)
+
+// RecommendedJobResponse is a job suggested to a student along with why it was suggested.
+type RecommendedJobResponse struct {
+	JobResponse
+	RequiredSkillMatches int      `json:"-"`
+	OptionalSkillMatches int      `json:"-"`
+	MajorMatch           bool     `json:"-"`
+	HistorySkillMatches  int      `json:"-"`
+	HistoryJobTypeMatch  bool     `json:"-"`
+	HistoryCompanyMatch  bool     `json:"-"`
+	Score                int      `json:"score"`
+	Reasons              []string `gorm:"-" json:"reasons"`
+}
+
+// @Summary Fetch recommended jobs
+// @Description Ranks open, approved jobs for the authenticated student using their major, skills, past applications and saved jobs. Each job lists the reasons it was suggested. Jobs the student already applied to are excluded.
+// @Tags Jobs
+// @Security BearerAuth
+// @Produce json
+// @Param limit query uint false "Pagination limit" default(32)
+// @Param offset query uint false "Pagination offset"
+// @Success 200 {object} object{jobs=[]handlers.RecommendedJobResponse,total=int} "Recommended jobs, best match first"
+// @Failure 400 {object} object{error=string} "Bad Request"
+// @Failure 403 {object} object{error=string} "Forbidden: Only students get recommendations"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /jobs/recommended [get]
+func (h *JobHandlers) FetchRecommendedJobsHandler(ctx *gin.Context) {
+	userId := ctx.MustGet("userID").(string)
+	if helper.GetRole(userId, h.DB) != helper.Student {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "only students get recommendations"})
+		return
+	}
+
+	type FetchRecommendedJobsInput struct {
+		Limit  uint `json:"limit" form:"limit" binding:"max=64"`
+		Offset uint `json:"offset" form:"offset"`
+	}
+	input := FetchRecommendedJobsInput{
+		Limit: 32,
+	}
+	if err := ctx.ShouldBind(&input); err != nil {
+		msg := "Failed to bind input"
+		slog.Debug(msg, "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	student := model.Student{}
+	if err := h.DB.Select("major").Take(&student, "user_id = ?", userId).Error; err != nil {
+		msg := "Failed to fetch student"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	// Only jobs a student could apply to right now are considered
+	now := time.Now()
+	candidates := h.DB.Model(&model.Job{}).
+		Joins("INNER JOIN users ON users.id = jobs.company_id").
+		Joins("INNER JOIN companies ON companies.user_id = jobs.company_id").
+		Where("jobs.is_open = ? AND jobs.approval_status = ?", true, model.JobApprovalAccepted).
+		Where("(jobs.application_deadline IS NULL OR jobs.application_deadline > ?)", now).
+		Scopes(helper.PublishedJobsScope(now)).
+		Where("NOT EXISTS (SELECT 1 FROM job_applications WHERE job_applications.job_id = jobs.id AND job_applications.user_id = ?)", userId)
+
+	var totalCount int64
+	if err := candidates.Count(&totalCount).Error; err != nil {
+		msg := "Failed to count jobs"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	// Jobs the student has shown interest in, used to find similar ones
+	historyJobs := "SELECT job_id FROM job_applications WHERE user_id = ? UNION SELECT job_id FROM saved_jobs WHERE user_id = ?"
+
+	majorSelect := "FALSE AS major_match"
+	majorArgs := []any{}
+	if majorQuery := helper.BuildAnyTSQuery(student.Major); majorQuery != "" {
+		majorSelect = "jobs.search_document @@ to_tsquery(?, ?) AS major_match"
+		majorArgs = append(majorArgs, database.JobSearchConfig, majorQuery)
+	}
+
+	args := []any{userId, userId, userId}
+	args = append(args, majorArgs...)
+	args = append(args, userId, userId, userId, userId, userId, userId)
+	signals := candidates.Select(`jobs.*, users.username AS company_name, companies.photo_id, companies.banner_id,
+		EXISTS (SELECT 1 FROM saved_jobs WHERE saved_jobs.job_id = jobs.id AND saved_jobs.user_id = ?) AS saved,
+		(SELECT COUNT(*) FROM job_skills INNER JOIN student_skills ON student_skills.skill_id = job_skills.skill_id AND student_skills.user_id = ? WHERE job_skills.job_id = jobs.id AND job_skills.required) AS required_skill_matches,
+		(SELECT COUNT(*) FROM job_skills INNER JOIN student_skills ON student_skills.skill_id = job_skills.skill_id AND student_skills.user_id = ? WHERE job_skills.job_id = jobs.id AND NOT job_skills.required) AS optional_skill_matches,
+		`+majorSelect+`,
+		(SELECT COUNT(DISTINCT job_skills.skill_id) FROM job_skills INNER JOIN job_skills AS history_skills ON history_skills.skill_id = job_skills.skill_id AND history_skills.job_id <> jobs.id AND history_skills.job_id IN (`+historyJobs+`) WHERE job_skills.job_id = jobs.id) AS history_skill_matches,
+		EXISTS (SELECT 1 FROM jobs AS history_jobs WHERE history_jobs.id <> jobs.id AND history_jobs.id IN (`+historyJobs+`) AND history_jobs.job_type = jobs.job_type) AS history_job_type_match,
+		EXISTS (SELECT 1 FROM jobs AS history_jobs WHERE history_jobs.id <> jobs.id AND history_jobs.id IN (`+historyJobs+`) AND history_jobs.company_id = jobs.company_id) AS history_company_match`,
+		args...)
+
+	score := fmt.Sprintf("%d * required_skill_matches + %d * optional_skill_matches + %d * major_match::int + %d * history_skill_matches + %d * history_job_type_match::int + %d * history_company_match::int",
+		recommendRequiredSkillWeight,
+		recommendOptionalSkillWeight,
+		recommendMajorWeight,
+		recommendHistorySkillWeight,
+		recommendHistoryTypeWeight,
+		recommendHistoryCompany,
+	)
+
+	jobs := []RecommendedJobResponse{}
+	if err := h.DB.Table("(?) AS recommendations", signals).
+		Select("*, " + score + " AS score").
+		Order("score DESC").
+		Order("created_at DESC").
+		// Unique tiebreak, so offset pages do not repeat or skip jobs with equal scores
+		Order("id DESC").
+		Offset(int(input.Offset)).
+		Limit(int(input.Limit)).
+		Find(&jobs).Error; err != nil {
+		msg := "Failed to fetch recommended jobs"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	jobIDs := make([]uint, 0, len(jobs))
+	for _, job := range jobs {
+		jobIDs = append(jobIDs, job.ID)
+	}
+	skillTags, err := loadJobSkillTags(h.DB, jobIDs)
+	if err != nil {
+		msg := "Failed to fetch job skills"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+	for i := range jobs {
+		jobs[i].Skills = skillTags[jobs[i].ID]
+		jobs[i].Reasons = recommendationReasons(&jobs[i], student.Major)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"jobs":  jobs,
+		"total": totalCount,
+	})
+}
+
+// recommendationReasons explains in plain words which signals contributed to a job's score.
+func recommendationReasons(job *RecommendedJobResponse, major string) []string {
+	reasons := []string{}
+	if job.RequiredSkillMatches > 0 {
+		reasons = append(reasons, fmt.Sprintf("You have %d of the skills this job requires", job.RequiredSkillMatches))
+	}
+	if job.OptionalSkillMatches > 0 {
+		reasons = append(reasons, fmt.Sprintf("You have %d of the skills this job would like", job.OptionalSkillMatches))
+	}
+	if job.MajorMatch {
+		reasons = append(reasons, fmt.Sprintf("Related to your major, %s", major))
+	}
+	if job.HistorySkillMatches > 0 {
+		reasons = append(reasons, "Asks for skills from jobs you applied to or saved")
+	}
+	if job.HistoryJobTypeMatch {
+		reasons = append(reasons, fmt.Sprintf("Same job type (%s) as jobs you applied to or saved", job.JobType))
+	}
+	if job.HistoryCompanyMatch {
+		reasons = append(reasons, fmt.Sprintf("From %s, a company you applied to or saved a job from", job.CompanyName))
+	}
+	return reasons
+}
@@ -0,0 +1,444 @@
+package handlers
+
+import (
+	"errors"
+	"ku-work/backend/model"
+	"ku-work/backend/services"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Actions an admin can take when resolving a report
+const (
+	ReportActionDismiss        = "dismiss"
+	ReportActionCloseJob       = "close_job"
+	ReportActionSuspendCompany = "suspend_company"
+)
+
+var errReportResolved = errors.New("report has already been resolved")
+
+type ReportHandlers struct {
+	DB *gorm.DB
+}
+
+func NewReportHandlers(db *gorm.DB) *ReportHandlers {
+	return &ReportHandlers{
+		DB: db,
+	}
+}
+
+type ReportInput struct {
+	Reason  string `json:"reason" binding:"required,oneof=scam misleading discriminatory inappropriate spam other"`
+	Details string `json:"details" binding:"required_if=Reason other,max=2000"`
+}
+
+type ResolveReportInput struct {
+	Action     string `json:"action" binding:"required,oneof=dismiss close_job suspend_company"`
+	Resolution string `json:"resolution" binding:"max=2000"`
+}
+
+// ReportResponse is a report in the admin queue with the names of the people and job involved.
+type ReportResponse struct {
+	model.Report
+	ReporterName string  `json:"reporterName"`
+	CompanyName  string  `json:"companyName"`
+	JobName      *string `json:"jobName"`
+	// TargetOpenReports is how many open reports there are against the same job or company, this one included
+	TargetOpenReports int64 `json:"targetOpenReports"`
+}
+
+// @Summary Report a job
+// @Description Flags an approved job as a scam, misleading or otherwise inappropriate for review by the admins. A user can only have one open report per job and reports are rate limited per user.
+// @Tags Reports
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path uint true "Job ID"
+// @Param report body handlers.ReportInput true "Report details"
+// @Success 200 {object} model.Report "Report filed"
+// @Failure 400 {object} object{error=string} "Bad Request"
+// @Failure 404 {object} object{error=string} "Not Found"
+// @Failure 409 {object} object{error=string} "Conflict: Already reported"
+// @Failure 429 {object} object{error=string} "Too Many Requests"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /jobs/{id}/report [post]
+func (h *ReportHandlers) ReportJobHandler(ctx *gin.Context) {
+	userId := ctx.MustGet("userID").(string)
+
+	jobId64, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil || jobId64 <= 0 || jobId64 > math.MaxUint32 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid job ID"})
+		return
+	}
+	jobId := uint(jobId64)
+
+	input := ReportInput{}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		msg := "Failed to bind input"
+		slog.Debug(msg, "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	// Only jobs that went through approval are visible to be reported
+	job := model.Job{}
+	if err := h.DB.Select("id", "company_id").
+		Where("id = ? AND approval_status = ?", jobId, model.JobApprovalAccepted).
+		Take(&job).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+			return
+		}
+		msg := "Failed to fetch job"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+	if job.CompanyID == userId {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "you cannot report your own job"})
+		return
+	}
+
+	h.fileReport(ctx, model.Report{
+		ReporterID: userId,
+		TargetType: model.ReportTargetJob,
+		JobID:      &job.ID,
+		CompanyID:  job.CompanyID,
+		Reason:     model.ReportReason(input.Reason),
+		Details:    input.Details,
+	})
+}
+
+// @Summary Report a company
+// @Description Flags a company as fraudulent or otherwise inappropriate for review by the admins. A user can only have one open report per company and reports are rate limited per user.
+// @Tags Reports
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Company User ID"
+// @Param report body handlers.ReportInput true "Report details"
+// @Success 200 {object} model.Report "Report filed"
+// @Failure 400 {object} object{error=string} "Bad Request"
+// @Failure 404 {object} object{error=string} "Not Found"
+// @Failure 409 {object} object{error=string} "Conflict: Already reported"
+// @Failure 429 {object} object{error=string} "Too Many Requests"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /company/{id}/report [post]
+func (h *ReportHandlers) ReportCompanyHandler(ctx *gin.Context) {
+	userId := ctx.MustGet("userID").(string)
+
+	companyId := ctx.Param("id")
+	if _, err := uuid.Parse(companyId); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid company ID"})
+		return
+	}
+	if companyId == userId {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "you cannot report your own company"})
+		return
+	}
+
+	input := ReportInput{}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		msg := "Failed to bind input"
+		slog.Debug(msg, "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	var count int64
+	if err := h.DB.Model(&model.Company{}).Where("user_id = ?", companyId).Count(&count).Error; err != nil {
+		msg := "Failed to fetch company"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+	if count == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "company not found"})
+		return
+	}
+
+	h.fileReport(ctx, model.Report{
+		ReporterID: userId,
+		TargetType: model.ReportTargetCompany,
+		CompanyID:  companyId,
+		Reason:     model.ReportReason(input.Reason),
+		Details:    input.Details,
+	})
+}
+
+// fileReport saves a new report unless the reporter already has an open one against the same target.
+func (h *ReportHandlers) fileReport(ctx *gin.Context, report model.Report) {
+	query := h.DB.Model(&model.Report{}).
+		Where("reporter_id = ? AND target_type = ? AND company_id = ? AND status = ?",
+			report.ReporterID, report.TargetType, report.CompanyID, model.ReportStatusOpen)
+	if report.JobID != nil {
+		query = query.Where("job_id = ?", *report.JobID)
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		msg := "Failed to file report"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+	if count > 0 {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "you have already reported this " + string(report.TargetType)})
+		return
+	}
+
+	report.Status = model.ReportStatusOpen
+	if err := h.DB.Create(&report).Error; err != nil {
+		msg := "Failed to file report"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
+
+// @Summary Get the report queue (Admin only)
+// @Description Lists reports against jobs and companies. Open reports are listed oldest first so the queue is worked in order, resolved reports newest first.
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Report status (open, actioned, dismissed)" default(open)
+// @Param targetType query string false "Only reports against jobs or companies (job, company)"
+// @Param companyId query string false "Only reports involving this company"
+// @Param offset query uint false "Pagination offset"
+// @Param limit query uint false "Pagination limit" default(32)
+// @Success 200 {array} handlers.ReportResponse "Reports"
+// @Failure 400 {object} object{error=string} "Bad Request"
+// @Failure 403 {object} object{error=string} "Forbidden"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /admin/reports [get]
+func (h *ReportHandlers) GetReportsHandler(ctx *gin.Context) {
+	type GetReportsInput struct {
+		Status     string `form:"status" binding:"oneof=open actioned dismissed"`
+		TargetType string `form:"targetType" binding:"omitempty,oneof=job company"`
+		CompanyID  string `form:"companyId" binding:"omitempty,uuid"`
+		Offset     uint   `form:"offset"`
+		Limit      uint   `form:"limit" binding:"max=64"`
+	}
+	input := GetReportsInput{
+		Status: string(model.ReportStatusOpen),
+		Limit:  32,
+	}
+	if err := ctx.ShouldBindQuery(&input); err != nil {
+		msg := "Failed to bind input"
+		slog.Debug(msg, "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	query := h.DB.Model(&model.Report{}).
+		Select("reports.*, reporters.username AS reporter_name, company_users.username AS company_name, jobs.name AS job_name, "+
+			"(SELECT COUNT(*) FROM reports AS others WHERE others.status = ? AND others.target_type = reports.target_type "+
+			"AND others.company_id = reports.company_id AND others.job_id IS NOT DISTINCT FROM reports.job_id) AS target_open_reports",
+			model.ReportStatusOpen).
+		Joins("LEFT JOIN users AS reporters ON reporters.id = reports.reporter_id").
+		Joins("LEFT JOIN users AS company_users ON company_users.id = reports.company_id").
+		Joins("LEFT JOIN jobs ON jobs.id = reports.job_id").
+		Where("reports.status = ?", input.Status)
+	if input.TargetType != "" {
+		query = query.Where("reports.target_type = ?", input.TargetType)
+	}
+	if input.CompanyID != "" {
+		query = query.Where("reports.company_id = ?", input.CompanyID)
+	}
+	if input.Status == string(model.ReportStatusOpen) {
+		query = query.Order("reports.created_at ASC").Order("reports.id ASC")
+	} else {
+		query = query.Order("reports.resolved_at DESC").Order("reports.id DESC")
+	}
+
+	reports := []ReportResponse{}
+	if err := query.Offset(int(input.Offset)).Limit(int(input.Limit)).Scan(&reports).Error; err != nil {
+		msg := "Failed to fetch reports"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+	ctx.JSON(http.StatusOK, reports)
+}
+
+// @Summary Resolve a report (Admin only)
+// @Description Dismisses a report, or acts on it by closing the reported job or suspending the company.
+// @Description Closing a job also resolves every other open report against that job, suspending a company every open report involving the company.
+// @Description Every resolved report and the action taken are written to the audit log.
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path uint true "Report ID"
+// @Param resolution body handlers.ResolveReportInput true "Action to take"
+// @Success 200 {object} object{message=string,resolved=[]uint} "IDs of the reports resolved"
+// @Failure 400 {object} object{error=string} "Bad Request"
+// @Failure 404 {object} object{error=string} "Not Found"
+// @Failure 409 {object} object{error=string} "Conflict: Report already resolved"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /admin/reports/{id} [patch]
+func (h *ReportHandlers) ResolveReportHandler(ctx *gin.Context) {
+	adminId := ctx.MustGet("userID").(string)
+
+	reportId64, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil || reportId64 <= 0 || reportId64 > math.MaxUint32 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid report ID"})
+		return
+	}
+
+	input := ResolveReportInput{}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		msg := "Failed to bind input"
+		slog.Debug(msg, "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	report := model.Report{}
+	if err := h.DB.Take(&report, uint(reportId64)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "report not found"})
+			return
+		}
+		msg := "Failed to fetch report"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+	if input.Action == ReportActionCloseJob && report.JobID == nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "only job reports can close a job"})
+		return
+	}
+
+	var resolved []uint
+	var closedJobIDs []uint
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the report so that two admins cannot act on it at the same time
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&report, report.ID).Error; err != nil {
+			return err
+		}
+		if report.Status != model.ReportStatusOpen {
+			return errReportResolved
+		}
+
+		status := model.ReportStatusActioned
+		related := tx.Model(&model.Report{}).Where("status = ?", model.ReportStatusOpen)
+		switch input.Action {
+		case ReportActionDismiss:
+			status = model.ReportStatusDismissed
+			related = related.Where("id = ?", report.ID)
+		case ReportActionCloseJob:
+			if err := closeJobForReport(tx, *report.JobID, adminId, input.Resolution); err != nil {
+				return err
+			}
+			closedJobIDs = []uint{*report.JobID}
+			related = related.Where("job_id = ?", *report.JobID)
+		case ReportActionSuspendCompany:
+			jobIDs, err := suspendCompany(tx, report.CompanyID, adminId, input.Resolution)
+			if err != nil {
+				return err
+			}
+			closedJobIDs = jobIDs
+			related = related.Where("company_id = ?", report.CompanyID)
+		}
+
+		if err := related.Pluck("id", &resolved).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		if err := tx.Model(&model.Report{}).Where("id IN ?", resolved).Updates(map[string]any{
+			"status":         status,
+			"resolved_by_id": adminId,
+			"resolved_at":    now,
+			"resolution":     input.Resolution,
+		}).Error; err != nil {
+			return err
+		}
+		audits := make([]model.Audit, 0, len(resolved))
+		for _, id := range resolved {
+			audits = append(audits, model.Audit{
+				ActorID:    adminId,
+				Action:     string(status),
+				Reason:     input.Resolution,
+				ObjectName: "Report",
+				ObjectID:   strconv.FormatUint(uint64(id), 10),
+			})
+		}
+		return tx.Create(&audits).Error
+	})
+	if err != nil {
+		if errors.Is(err, errReportResolved) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		msg := "Failed to resolve report"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+	if len(closedJobIDs) != 0 {
+		if err := services.RemoveSavedJobsForClosedJobs(h.DB, closedJobIDs...); err != nil {
+			slog.Warn("Failed to remove saved jobs for closed jobs", "job_ids", closedJobIDs, "error", err)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "ok", "resolved": resolved})
+}
+
+// closeJobForReport closes a reported job and records it in the audit log.
+func closeJobForReport(tx *gorm.DB, jobID uint, adminID string, reason string) error {
+	if err := tx.Model(&model.Job{}).Where("id = ?", jobID).Update("is_open", false).Error; err != nil {
+		return err
+	}
+	return tx.Create(&model.Audit{
+		ActorID:    adminID,
+		Action:     "closed",
+		Reason:     reason,
+		ObjectName: "Job",
+		ObjectID:   strconv.FormatUint(uint64(jobID), 10),
+	}).Error
+}
+
+// suspendCompany blocks a company's account and closes all of its open jobs, recording it in the audit log.
+// The jobs stay closed when the suspension is lifted, the company reopens the ones it still wants.
+// Returns the IDs of the jobs it closed, so their bookmarks can be removed once the transaction commits.
+func suspendCompany(tx *gorm.DB, companyID string, adminID string, reason string) ([]uint, error) {
+	result := tx.Model(&model.Company{}).
+		Where("user_id = ? AND suspended_at IS NULL", companyID).
+		Update("suspended_at", time.Now())
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	var closedJobIDs []uint
+	if err := tx.Model(&model.Job{}).
+		Where("company_id = ? AND is_open = ?", companyID, true).
+		Pluck("id", &closedJobIDs).Error; err != nil {
+		return nil, err
+	}
+	if len(closedJobIDs) != 0 {
+		if err := tx.Model(&model.Job{}).
+			Where("id IN ?", closedJobIDs).
+			Update("is_open", false).Error; err != nil {
+			return nil, err
+		}
+	}
+	if result.RowsAffected == 0 {
+		// Already suspended
+		return closedJobIDs, nil
+	}
+	return closedJobIDs, tx.Create(&model.Audit{
+		ActorID:    adminID,
+		Action:     "suspended",
+		Reason:     reason,
+		ObjectName: "Company",
+		ObjectID:   companyID,
+	}).Error
+}
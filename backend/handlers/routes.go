@@ -18,7 +18,7 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, redisClient *redis.Client, ema
 	localAuthHandlers := NewLocalAuthHandlers(db, jwtHandlers)
 	googleAuthHandlers := NewOAuthHandlers(db, jwtHandlers)
 
-	jobHandlers, err := NewJobHandlers(db, aiService, emailService)
+	jobHandlers, err := NewJobHandlers(db, redisClient, aiService, emailService)
 	if err != nil {
 		return err
 	}
@@ -26,13 +26,26 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, redisClient *redis.Client, ema
 	if err != nil {
 		return err
 	}
+	interviewHandlers, err := NewInterviewHandlers(db, emailService)
+	if err != nil {
+		return err
+	}
 	studentHandlers, err := NewStudentHandler(db, fileHandlers, aiService, emailService)
 	if err != nil {
 		return err
 	}
+	jobAlertHandlers, err := NewJobAlertHandlers(db)
+	if err != nil {
+		return err
+	}
 	companyHandlers := NewCompanyHandlers(db)
 	userHandlers := NewUserHandlers(db, helper.GetGracePeriodDays())
 	adminHandlers := NewAdminHandlers(db)
+	reportHandlers := NewReportHandlers(db)
+	savedJobHandlers := NewSavedJobHandlers(db)
+	skillHandlers := NewSkillHandlers(db)
+	locationHandlers := NewLocationHandlers()
+	feedHandlers := NewFeedHandlers(db)
 
 	// Middlewares
 	turnstileMiddleware := middlewares.TurnstileMiddleware()
@@ -44,6 +57,7 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, redisClient *redis.Client, ema
 	trustedRateLimiter := middlewares.RateLimiterWithLimits(redisClient, 100, 100*60)
 	authRateLimiter := middlewares.AuthRateLimiter(redisClient, 5, 20)
 	authedRateLimiter := middlewares.RateLimiterWithLimits(redisClient, 60, 60*60)
+	reportRateLimiter := middlewares.UserRateLimiterWithLimits(redisClient, "report", 3, 10)
 
 	if fileService == nil {
 		return fmt.Errorf("fileService must be provided")
@@ -54,6 +68,14 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, redisClient *redis.Client, ema
 	// File Routes
 	router.GET("/files/:fileID", fileHandlers.ServeFileHandler)
 
+	// Public job feeds
+	feeds := router.Group("/feeds", authedRateLimiter)
+	feeds.GET("/jobs.rss", feedHandlers.GetJobsRSSHandler)
+	feeds.GET("/jobs.atom", feedHandlers.GetJobsAtomHandler)
+	feeds.GET("/jobs.json", feedHandlers.GetJobsJSONFeedHandler)
+	feeds.GET("/jobs/:id", feedHandlers.GetJobPostingHandler)
+	feeds.GET("/sitemap.xml", feedHandlers.GetJobsSitemapHandler)
+
 	// Authentication Routes
 	auth := router.Group("/auth", authRateLimiter)
 	auth.POST("/admin/login", turnstileMiddleware, localAuthHandlers.AdminLoginHandler)
@@ -83,32 +105,70 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, redisClient *redis.Client, ema
 	protectedActive.PATCH("/me", turnstileMiddleware, userHandlers.EditProfileHandler)
 	protectedActive.GET("/me", userHandlers.GetProfileHandler)
 	protectedActive.POST("/me/deactivate", turnstileMiddleware, userHandlers.DeactivateAccount)
+	protectedActive.GET("/me/saved-jobs", savedJobHandlers.GetSavedJobsHandler)
+	protectedActive.POST("/me/saved-jobs/:jobId", savedJobHandlers.SaveJobHandler)
+	protectedActive.DELETE("/me/saved-jobs/:jobId", savedJobHandlers.UnsaveJobHandler)
+	protectedActive.GET("/me/job-alerts", jobAlertHandlers.GetJobAlertsHandler)
+	protectedActive.POST("/me/job-alerts", jobAlertHandlers.CreateJobAlertHandler)
+	protectedActive.PATCH("/me/job-alerts/:id", jobAlertHandlers.EditJobAlertHandler)
+	protectedActive.DELETE("/me/job-alerts/:id", jobAlertHandlers.DeleteJobAlertHandler)
+
+	// Unsubscribe links are opened straight from digest emails, so they do not require authentication.
+	// Opening the link only shows a confirmation page, the alert is deleted when it is posted.
+	router.GET("/job-alerts/unsubscribe/:token", authedRateLimiter, jobAlertHandlers.ConfirmUnsubscribeJobAlertHandler)
+	router.POST("/job-alerts/unsubscribe/:token", authedRateLimiter, jobAlertHandlers.UnsubscribeJobAlertHandler)
 
 	// Company Routs
 	company := protectedActive.Group("/company")
 	company.GET("/:id", companyHandlers.GetCompanyProfileHandler)
+	company.POST("/:id/report", reportRateLimiter, reportHandlers.ReportCompanyHandler)
 
 	companyAdmin := trustedProtectedActive.Group("/company")
 	companyAdmin.GET("", companyHandlers.GetCompanyListHandler)
+	companyAdmin.POST("/:id/suspension", companyHandlers.CompanySuspensionHandler)
 
 	// Job Routes
 	job := protectedActive.Group("/jobs")
 	job.GET("", jobHandlers.FetchJobsHandler)
+	job.GET("/recommended", jobHandlers.FetchRecommendedJobsHandler)
 	job.POST("", turnstileMiddleware, jobHandlers.CreateJobHandler)
 	job.GET("/:id/applications", applicationHandlers.GetJobApplicationsHandler)
 	job.DELETE("/:id/applications", applicationHandlers.ClearJobApplicationsHandler)
 	job.GET("/:id/applications/:email", applicationHandlers.GetJobApplicationHandler)
+	job.GET("/:id/applicants/:studentUserId/history", applicationHandlers.GetJobApplicationHistoryHandler)
 	job.PATCH("/:id/applications/:studentUserId/status", applicationHandlers.UpdateJobApplicationStatusHandler)
+	job.PATCH("/:id/applications/:studentUserId/evaluation", applicationHandlers.EvaluateJobApplicationHandler)
+	job.GET("/:id/applicants/:studentUserId/interviews", interviewHandlers.GetJobApplicationInterviewsHandler)
+	job.POST("/:id/applicants/:studentUserId/interviews", interviewHandlers.ProposeInterviewHandler)
+	job.POST("/:id/interviews/:interviewId/reschedule", interviewHandlers.RescheduleInterviewHandler)
+	job.POST("/:id/interviews/:interviewId/cancel", interviewHandlers.CompanyCancelInterviewHandler)
 	job.GET("/:id", jobHandlers.GetJobDetailHandler)
+	job.GET("/:id/revisions", jobHandlers.GetJobRevisionsHandler)
+	job.GET("/:id/analytics", jobHandlers.GetJobAnalyticsHandler)
 	job.POST("/:id/apply", turnstileMiddleware, applicationHandlers.CreateJobApplicationHandler)
+	job.POST("/:id/clone", turnstileMiddleware, jobHandlers.CloneJobHandler)
+	job.POST("/:id/report", reportRateLimiter, reportHandlers.ReportJobHandler)
+	job.POST("/import", turnstileMiddleware, jobHandlers.ImportJobsHandler)
 	job.PATCH("/:id", middlewares.TurnstileExceptionMiddleware(), jobHandlers.EditJobHandler, turnstileMiddleware, jobHandlers.EditJobHandler)
 
 	jobAdmin := trustedProtectedActive.Group("/jobs")
 	jobAdmin.POST("/:id/approval", jobHandlers.JobApprovalHandler)
 
+	// Job Template Routes
+	jobTemplate := protectedActive.Group("/job-templates")
+	jobTemplate.GET("", jobHandlers.GetJobTemplatesHandler)
+	jobTemplate.POST("", jobHandlers.CreateJobTemplateHandler)
+	jobTemplate.PUT("/:id", jobHandlers.EditJobTemplateHandler)
+	jobTemplate.DELETE("/:id", jobHandlers.DeleteJobTemplateHandler)
+	jobTemplate.POST("/:id/jobs", turnstileMiddleware, jobHandlers.InstantiateJobTemplateHandler)
+
 	// Application Routes
 	application := protectedActive.Group("/applications")
 	application.GET("", applicationHandlers.GetAllJobApplicationsHandler)
+	application.POST("/:jobId/withdraw", applicationHandlers.WithdrawJobApplicationHandler)
+	application.GET("/:jobId/interviews", interviewHandlers.GetOwnInterviewsHandler)
+	application.POST("/:jobId/interviews/:interviewId/select", interviewHandlers.SelectInterviewSlotHandler)
+	application.POST("/:jobId/interviews/:interviewId/cancel", interviewHandlers.StudentCancelInterviewHandler)
 
 	// Student Routes
 	student := protectedActive.Group("/students")
@@ -117,9 +177,20 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, redisClient *redis.Client, ema
 	studentAdmin := trustedProtectedActive.Group("/students")
 	studentAdmin.POST("/:id/approval", studentHandlers.ApproveHandler)
 
+	// Skill Routes
+	protectedActive.GET("/skills", skillHandlers.GetSkillsHandler)
+
+	// Location Routes
+	protectedActive.GET("/locations/provinces", locationHandlers.GetProvincesHandler)
+
 	// Admin Routes
 	admin := trustedProtectedActive.Group("/admin")
 	admin.GET("/audits", adminHandlers.FetchAuditLog)
 	admin.GET("/emaillog", adminHandlers.FetchEmailLog)
+	admin.GET("/reports", reportHandlers.GetReportsHandler)
+	admin.PATCH("/reports/:id", reportHandlers.ResolveReportHandler)
+	admin.POST("/skills", skillHandlers.CreateSkillHandler)
+	admin.PUT("/skills/:id", skillHandlers.EditSkillHandler)
+	admin.DELETE("/skills/:id", skillHandlers.DeleteSkillHandler)
 	return nil
 }
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"ku-work/backend/helper"
+	"ku-work/backend/model"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SavedJobHandlers struct {
+	DB *gorm.DB
+}
+
+func NewSavedJobHandlers(db *gorm.DB) *SavedJobHandlers {
+	return &SavedJobHandlers{
+		DB: db,
+	}
+}
+
+// @Summary Save a job
+// @Description Bookmarks an open, approved job whose application deadline has not passed for the authenticated student. Saving a job twice is a no-op.
+// @Tags Saved Jobs
+// @Security BearerAuth
+// @Produce json
+// @Param jobId path uint true "Job ID"
+// @Success 200 {object} object{message=string} "ok"
+// @Failure 400 {object} object{error=string} "Bad Request: Invalid job ID"
+// @Failure 403 {object} object{error=string} "Forbidden: Only students can save jobs"
+// @Failure 404 {object} object{error=string} "Not Found: Job not found"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /me/saved-jobs/{jobId} [post]
+func (h *SavedJobHandlers) SaveJobHandler(ctx *gin.Context) {
+	userId := ctx.MustGet("userID").(string)
+	if helper.GetRole(userId, h.DB) != helper.Student {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "only students can save jobs"})
+		return
+	}
+
+	jobIdStr := ctx.Param("jobId")
+	jobId64, err := strconv.ParseUint(jobIdStr, 10, 64)
+	if err != nil || jobId64 <= 0 || jobId64 > math.MaxUint32 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid job id"})
+		return
+	}
+	jobId := uint(jobId64)
+
+	// Only jobs a student can see are allowed to be saved
+	now := time.Now()
+	job := model.Job{}
+	if err := h.DB.
+		Where("id = ? AND is_open = ? AND approval_status = ?", jobId, true, model.JobApprovalAccepted).
+		Where("application_deadline IS NULL OR application_deadline > ?", now).
+		Take(&job).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+		} else {
+			slog.Error("Failed to get job", "error", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get job"})
+		}
+		return
+	}
+	if !job.IsPublishedAt(now) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+		return
+	}
+
+	savedJob := model.SavedJob{
+		UserID: userId,
+		JobID:  job.ID,
+	}
+	if err := h.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&savedJob).Error; err != nil {
+		slog.Error("Failed to save job", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save job"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "ok"})
+}
+
+// @Summary Remove a saved job
+// @Description Removes a job from the authenticated student's bookmarks. Removing a job that is not saved is a no-op.
+// @Tags Saved Jobs
+// @Security BearerAuth
+// @Produce json
+// @Param jobId path uint true "Job ID"
+// @Success 200 {object} object{message=string} "ok"
+// @Failure 400 {object} object{error=string} "Bad Request: Invalid job ID"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /me/saved-jobs/{jobId} [delete]
+func (h *SavedJobHandlers) UnsaveJobHandler(ctx *gin.Context) {
+	userId := ctx.MustGet("userID").(string)
+
+	jobIdStr := ctx.Param("jobId")
+	jobId64, err := strconv.ParseUint(jobIdStr, 10, 64)
+	if err != nil || jobId64 <= 0 || jobId64 > math.MaxUint32 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid job id"})
+		return
+	}
+
+	if err := h.DB.Where("user_id = ? AND job_id = ?", userId, uint(jobId64)).Delete(&model.SavedJob{}).Error; err != nil {
+		slog.Error("Failed to remove saved job", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove saved job"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "ok"})
+}
+
+// @Summary List saved jobs
+// @Description Retrieves the authenticated student's saved jobs, most recently saved first.
+// @Tags Saved Jobs
+// @Security BearerAuth
+// @Produce json
+// @Param offset query int false "Offset for pagination" default(0)
+// @Param limit query int false "Limit for pagination" default(32)
+// @Success 200 {object} object{jobs=[]handlers.JobResponse,total=int} "List of saved jobs with total count"
+// @Failure 400 {object} object{error=string} "Bad Request"
+// @Failure 403 {object} object{error=string} "Forbidden: Only students can save jobs"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /me/saved-jobs [get]
+func (h *SavedJobHandlers) GetSavedJobsHandler(ctx *gin.Context) {
+	userId := ctx.MustGet("userID").(string)
+	if helper.GetRole(userId, h.DB) != helper.Student {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "only students can save jobs"})
+		return
+	}
+
+	type FetchSavedJobsInput struct {
+		Offset uint `json:"offset" form:"offset"`
+		Limit  uint `json:"limit" form:"limit" binding:"max=64"`
+	}
+	input := FetchSavedJobsInput{}
+	if err := ctx.ShouldBind(&input); err != nil {
+		slog.Debug("Failed to bind get saved jobs request", "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if input.Limit == 0 {
+		input.Limit = 32
+	}
+
+	query := h.DB.Model(&model.Job{}).
+		Joins("INNER JOIN saved_jobs ON saved_jobs.job_id = jobs.id").
+		Joins("INNER JOIN users ON users.id = jobs.company_id").
+		Joins("INNER JOIN companies ON companies.user_id = jobs.company_id").
+		Where("saved_jobs.user_id = ?", userId).
+		Where("jobs.approval_status = ?", model.JobApprovalAccepted).
+		Scopes(helper.PublishedJobsScope(time.Now()))
+
+	var totalCount int64
+	if err := query.Count(&totalCount).Error; err != nil {
+		slog.Error("Failed to count saved jobs", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count saved jobs"})
+		return
+	}
+
+	jobs := []JobResponse{}
+	if err := query.
+		Select("jobs.*, users.username as company_name, companies.photo_id, companies.banner_id, EXISTS (SELECT 1 FROM job_applications WHERE job_applications.job_id = jobs.id AND job_applications.user_id = ?) AS applied, TRUE AS saved", userId).
+		Order("saved_jobs.created_at DESC").
+		Offset(int(input.Offset)).
+		Limit(int(input.Limit)).
+		Find(&jobs).Error; err != nil {
+		slog.Error("Failed to get saved jobs", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get saved jobs"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"jobs":  jobs,
+		"total": totalCount,
+	})
+}
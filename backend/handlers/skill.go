@@ -0,0 +1,401 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"ku-work/backend/helper"
+	"ku-work/backend/model"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// errUnknownSkills aborts a transaction when some submitted skill names match no skill.
+var errUnknownSkills = errors.New("unknown skills")
+
+type SkillHandlers struct {
+	DB *gorm.DB
+}
+
+func NewSkillHandlers(db *gorm.DB) *SkillHandlers {
+	return &SkillHandlers{
+		DB: db,
+	}
+}
+
+// SkillTag is the normalized form of a skill returned on student profiles.
+type SkillTag struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// JobSkillTag is the normalized form of a skill returned on job posts.
+type JobSkillTag struct {
+	SkillTag
+	Required bool `json:"required"`
+}
+
+// SkillInput defines the request body for creating or editing a skill.
+type SkillInput struct {
+	Name    string   `json:"name" binding:"required,max=64"`
+	Aliases []string `json:"aliases" binding:"max=16,dive,max=64"`
+}
+
+// @Summary List skills
+// @Description Retrieves the skill vocabulary, optionally filtered by a name or alias prefix.
+// @Tags Skills
+// @Security BearerAuth
+// @Produce json
+// @Param q query string false "Name or alias prefix"
+// @Success 200 {object} object{skills=[]model.Skill} "List of skills"
+// @Failure 400 {object} object{error=string} "Bad Request"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /skills [get]
+func (h *SkillHandlers) GetSkillsHandler(ctx *gin.Context) {
+	type FetchSkillsInput struct {
+		Query string `form:"q" binding:"max=64"`
+	}
+	input := FetchSkillsInput{}
+	if err := ctx.ShouldBind(&input); err != nil {
+		msg := "Failed to bind input"
+		slog.Debug(msg, "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	query := h.DB.Model(&model.Skill{}).Preload("Aliases")
+	if slug := helper.NormalizeSkillName(input.Query); slug != "" {
+		pattern := helper.EscapeLikePattern(slug) + "%"
+		query = query.Where("slug LIKE ? OR id IN (SELECT skill_id FROM skill_aliases WHERE slug LIKE ?)", pattern, pattern)
+	}
+
+	skills := []model.Skill{}
+	if err := query.Order("name ASC").Find(&skills).Error; err != nil {
+		msg := "Failed to fetch skills"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"skills": skills})
+}
+
+// @Summary Create a skill (Admin only)
+// @Description Adds a skill and its aliases to the vocabulary. Names and aliases must not clash with any existing skill or alias.
+// @Tags Skills
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param skill body handlers.SkillInput true "Skill data"
+// @Success 200 {object} model.Skill "Created skill"
+// @Failure 400 {object} object{error=string} "Bad Request"
+// @Failure 409 {object} object{error=string} "Conflict: Name or alias already in use"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /admin/skills [post]
+func (h *SkillHandlers) CreateSkillHandler(ctx *gin.Context) {
+	input := SkillInput{}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		msg := "Failed to bind input"
+		slog.Debug(msg, "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	skill := model.Skill{}
+	if !h.applySkillInput(ctx, &skill, input) {
+		return
+	}
+
+	if err := h.DB.Create(&skill).Error; err != nil {
+		msg := "Failed to create skill"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, skill)
+}
+
+// @Summary Edit a skill (Admin only)
+// @Description Renames a skill and replaces its aliases. Jobs and students linked to the skill keep their links.
+// @Tags Skills
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path uint true "Skill ID"
+// @Param skill body handlers.SkillInput true "Skill data"
+// @Success 200 {object} model.Skill "Updated skill"
+// @Failure 400 {object} object{error=string} "Bad Request"
+// @Failure 404 {object} object{error=string} "Not Found"
+// @Failure 409 {object} object{error=string} "Conflict: Name or alias already in use"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /admin/skills/{id} [put]
+func (h *SkillHandlers) EditSkillHandler(ctx *gin.Context) {
+	skill, ok := h.findSkill(ctx)
+	if !ok {
+		return
+	}
+
+	input := SkillInput{}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		msg := "Failed to bind input"
+		slog.Debug(msg, "error", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	if !h.applySkillInput(ctx, &skill, input) {
+		return
+	}
+
+	if err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("skill_id = ?", skill.ID).Delete(&model.SkillAlias{}).Error; err != nil {
+			return err
+		}
+		return tx.Session(&gorm.Session{FullSaveAssociations: true}).Save(&skill).Error
+	}); err != nil {
+		msg := "Failed to update skill"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, skill)
+}
+
+// @Summary Delete a skill (Admin only)
+// @Description Removes a skill from the vocabulary along with its aliases and every job and student link to it.
+// @Tags Skills
+// @Security BearerAuth
+// @Produce json
+// @Param id path uint true "Skill ID"
+// @Success 200 {object} object{message=string} "ok"
+// @Failure 400 {object} object{error=string} "Bad Request"
+// @Failure 404 {object} object{error=string} "Not Found"
+// @Failure 500 {object} object{error=string} "Internal Server Error"
+// @Router /admin/skills/{id} [delete]
+func (h *SkillHandlers) DeleteSkillHandler(ctx *gin.Context) {
+	skill, ok := h.findSkill(ctx)
+	if !ok {
+		return
+	}
+
+	if err := h.DB.Delete(&skill).Error; err != nil {
+		msg := "Failed to delete skill"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "ok"})
+}
+
+// findSkill loads the skill named by the id path parameter.
+// It writes the error response itself and reports whether the handler should continue.
+func (h *SkillHandlers) findSkill(ctx *gin.Context) (model.Skill, bool) {
+	skill := model.Skill{}
+
+	skillId, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil || skillId <= 0 || skillId > math.MaxUint32 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid skill id"})
+		return skill, false
+	}
+
+	if err := h.DB.Take(&skill, uint(skillId)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "skill not found"})
+		} else {
+			msg := "Failed to fetch skill"
+			slog.Error(msg, "error", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		}
+		return skill, false
+	}
+	return skill, true
+}
+
+// applySkillInput copies the input onto the skill after checking that its name and aliases are free.
+// It writes the error response itself and reports whether the handler should continue.
+func (h *SkillHandlers) applySkillInput(ctx *gin.Context, skill *model.Skill, input SkillInput) bool {
+	name := strings.Join(strings.Fields(input.Name), " ")
+	slug := helper.NormalizeSkillName(name)
+	if slug == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "name must not be empty"})
+		return false
+	}
+
+	aliases := []model.SkillAlias{}
+	slugs := []string{slug}
+	seen := map[string]bool{slug: true}
+	for _, alias := range input.Aliases {
+		aliasSlug := helper.NormalizeSkillName(alias)
+		if aliasSlug == "" || seen[aliasSlug] {
+			continue
+		}
+		seen[aliasSlug] = true
+		slugs = append(slugs, aliasSlug)
+		aliases = append(aliases, model.SkillAlias{
+			SkillID: skill.ID,
+			Alias:   strings.Join(strings.Fields(alias), " "),
+			Slug:    aliasSlug,
+		})
+	}
+
+	ids, _, err := helper.ResolveSkillIDs(h.DB, slugs)
+	if err != nil {
+		msg := "Failed to check skill names"
+		slog.Error(msg, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return false
+	}
+	for _, id := range ids {
+		if id != skill.ID {
+			ctx.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("name or alias already used by skill %d", id)})
+			return false
+		}
+	}
+
+	skill.Name = name
+	skill.Slug = slug
+	skill.Aliases = aliases
+	return true
+}
+
+// loadJobSkillTags returns the skill tags of each job, keyed by job ID.
+// Required skills are listed before nice-to-have ones.
+func loadJobSkillTags(db *gorm.DB, jobIDs []uint) (map[uint][]JobSkillTag, error) {
+	type jobSkillRow struct {
+		JobID uint
+		JobSkillTag
+	}
+	rows := []jobSkillRow{}
+	if len(jobIDs) != 0 {
+		if err := db.Model(&model.JobSkill{}).
+			Select("job_skills.job_id, job_skills.required, skills.id, skills.name").
+			Joins("INNER JOIN skills ON skills.id = job_skills.skill_id").
+			Where("job_skills.job_id IN ?", jobIDs).
+			Order("job_skills.required DESC, skills.name ASC").
+			Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	tags := make(map[uint][]JobSkillTag, len(jobIDs))
+	for _, row := range rows {
+		tags[row.JobID] = append(tags[row.JobID], row.JobSkillTag)
+	}
+	return tags, nil
+}
+
+// loadStudentSkillTags returns the skill tags of each student, keyed by user ID.
+func loadStudentSkillTags(db *gorm.DB, userIDs []string) (map[string][]SkillTag, error) {
+	type studentSkillRow struct {
+		UserID string
+		SkillTag
+	}
+	rows := []studentSkillRow{}
+	if len(userIDs) != 0 {
+		if err := db.Model(&model.StudentSkill{}).
+			Select("student_skills.user_id, skills.id, skills.name").
+			Joins("INNER JOIN skills ON skills.id = student_skills.skill_id").
+			Where("student_skills.user_id IN ?", userIDs).
+			Order("skills.name ASC").
+			Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	tags := make(map[string][]SkillTag, len(userIDs))
+	for _, row := range rows {
+		tags[row.UserID] = append(tags[row.UserID], row.SkillTag)
+	}
+	return tags, nil
+}
+
+// replaceJobSkills resolves the skill names and replaces every skill link of the job.
+// A skill listed as both required and nice-to-have is kept as required.
+// Returns the names that did not match any skill, in which case nothing is changed.
+func replaceJobSkills(tx *gorm.DB, jobID uint, required []string, niceToHave []string) ([]string, error) {
+	requiredIDs, unknownRequired, err := helper.ResolveSkillIDs(tx, required)
+	if err != nil {
+		return nil, err
+	}
+	niceToHaveIDs, unknownNiceToHave, err := helper.ResolveSkillIDs(tx, niceToHave)
+	if err != nil {
+		return nil, err
+	}
+	if unknown := append(unknownRequired, unknownNiceToHave...); len(unknown) != 0 {
+		return unknown, nil
+	}
+
+	links := []model.JobSkill{}
+	linked := map[uint]bool{}
+	for _, id := range requiredIDs {
+		linked[id] = true
+		links = append(links, model.JobSkill{JobID: jobID, SkillID: id, Required: true})
+	}
+	for _, id := range niceToHaveIDs {
+		if linked[id] {
+			continue
+		}
+		linked[id] = true
+		links = append(links, model.JobSkill{JobID: jobID, SkillID: id, Required: false})
+	}
+
+	if err := tx.Where("job_id = ?", jobID).Delete(&model.JobSkill{}).Error; err != nil {
+		return nil, err
+	}
+	if len(links) != 0 {
+		if err := tx.Create(&links).Error; err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// currentJobSkillNames returns the names of the skills a job currently requires and would like to have.
+func currentJobSkillNames(tx *gorm.DB, jobID uint) (required []string, niceToHave []string, err error) {
+	tags, err := loadJobSkillTags(tx, []uint{jobID})
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, tag := range tags[jobID] {
+		if tag.Required {
+			required = append(required, tag.Name)
+		} else {
+			niceToHave = append(niceToHave, tag.Name)
+		}
+	}
+	return required, niceToHave, nil
+}
+
+// replaceStudentSkills resolves the skill names and replaces every skill link of the student.
+// Returns the names that did not match any skill, in which case nothing is changed.
+func replaceStudentSkills(tx *gorm.DB, userID string, names []string) ([]string, error) {
+	ids, unknown, err := helper.ResolveSkillIDs(tx, names)
+	if err != nil {
+		return nil, err
+	}
+	if len(unknown) != 0 {
+		return unknown, nil
+	}
+
+	if err := tx.Where("user_id = ?", userID).Delete(&model.StudentSkill{}).Error; err != nil {
+		return nil, err
+	}
+	links := make([]model.StudentSkill, 0, len(ids))
+	for _, id := range ids {
+		links = append(links, model.StudentSkill{UserID: userID, SkillID: id})
+	}
+	if len(links) != 0 {
+		if err := tx.Create(&links).Error; err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
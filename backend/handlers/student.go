@@ -44,10 +44,11 @@ func NewStudentHandler(db *gorm.DB, fileHandlers *FileHandlers, aiService *servi
 // It embeds model.Student and augments with display name and email from oauth details.
 type StudentInfo struct {
 	model.Student
-	FirstName string `json:"firstName"`
-	LastName  string `json:"lastName"`
-	Email     string `json:"email"`
-	FullName  string `json:"fullName"`
+	FirstName string     `json:"firstName"`
+	LastName  string     `json:"lastName"`
+	Email     string     `json:"email"`
+	FullName  string     `json:"fullName"`
+	Skills    []SkillTag `gorm:"-" json:"skills"`
 }
 
 // anonymizeStudent clears personally-identifying information from a StudentInfo response.
@@ -467,8 +468,20 @@ func (h *StudentHandler) GetProfileHandler(ctx *gin.Context) {
 				return
 			}
 
+			userIDs := make([]string, 0, len(students))
+			for _, student := range students {
+				userIDs = append(userIDs, student.UserID)
+			}
+			skillTags, err := loadStudentSkillTags(h.DB, userIDs)
+			if err != nil {
+				slog.Error("Failed to get student skills", "error", err)
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get student profiles"})
+				return
+			}
+
 			// Anonymize deactivated accounts before returning list
 			for i := range students {
+				students[i].Skills = skillTags[students[i].UserID]
 				uid := students[i].UserID
 				if uid == "" {
 					continue
@@ -491,6 +504,14 @@ func (h *StudentHandler) GetProfileHandler(ctx *gin.Context) {
 		return
 	}
 
+	skillTags, err := loadStudentSkillTags(h.DB, []string{userId})
+	if err != nil {
+		slog.Error("Failed to get student skills", "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get student profile"})
+		return
+	}
+	studentInfo.Skills = skillTags[userId]
+
 	// If the target account is deactivated, anonymize the profile
 	if helper.IsDeactivated(h.DB, userId) {
 		anonymizeStudent(&studentInfo)
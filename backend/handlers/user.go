@@ -41,6 +41,7 @@ func NewUserHandlers(db *gorm.DB, gracePeriod int) *UserHandlers {
 // @Param github formData string false "GitHub profile URL (Student only)"
 // @Param linkedIn formData string false "LinkedIn profile URL (Student only)"
 // @Param studentStatus formData string false "Student status (Student only)" Enums(Graduated, Current Student)
+// @Param skills formData []string false "Skill names or aliases, replaces all current skills when sent (Student only)"
 // @Param email formData string false "Company email (Company only)"
 // @Param website formData string false "Company website URL (Company only)"
 // @Param address formData string false "Company address (Company only)"
@@ -235,6 +236,7 @@ func (h *UserHandlers) editStudentProfile(ctx *gin.Context, userId string) {
 		LinkedIn      *string               `form:"linkedIn" binding:"omitempty,max=256"`
 		StudentStatus string                `form:"studentStatus" binding:"required,oneof='Graduated' 'Current Student'"`
 		Photo         *multipart.FileHeader `form:"photo"`
+		Skills        []string              `form:"skills" binding:"max=32,dive,max=64"`
 	}
 	input := StudentEditProfileInput{}
 	err := ctx.MustBindWith(&input, binding.FormMultipart)
@@ -293,11 +295,34 @@ func (h *UserHandlers) editStudentProfile(ctx *gin.Context, userId string) {
 		student.PhotoID = photo.ID
 	}
 
+	// Skills are only replaced when the field is sent, an empty value clears them
+	_, hasSkills := ctx.GetPostFormArray("skills")
+
 	// Save data into database
-	result := h.DB.Save(&student)
-	if result.Error != nil {
+	var unknownSkills []string
+	if err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&student).Error; err != nil {
+			return err
+		}
+		if !hasSkills {
+			return nil
+		}
+		var err error
+		unknownSkills, err = replaceStudentSkills(tx, userId, input.Skills)
+		if err != nil {
+			return err
+		}
+		if len(unknownSkills) != 0 {
+			return errUnknownSkills
+		}
+		return nil
+	}); err != nil {
+		if err == errUnknownSkills {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "unknown skills", "skills": unknownSkills})
+			return
+		}
 		msg := "Failed to save student"
-		slog.Error(msg, "error", result.Error)
+		slog.Error(msg, "error", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
 		return
 	}
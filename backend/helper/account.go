@@ -34,3 +34,14 @@ func IsDeactivated(db *gorm.DB, userId string) bool {
 	}
 	return user.DeletedAt.Valid
 }
+
+// IsSuspended reports whether the user is a company that an admin has suspended.
+func IsSuspended(db *gorm.DB, userId string) bool {
+	var count int64
+	if err := db.Model(&model.Company{}).
+		Where("user_id = ? AND suspended_at IS NOT NULL", userId).
+		Count(&count).Error; err != nil {
+		return false
+	}
+	return count > 0
+}
@@ -0,0 +1,198 @@
+package helper
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor is malformed or belongs to a different sort order.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// CursorKind is the Go type a sort key value is decoded into when read back from a cursor.
+type CursorKind int
+
+const (
+	CursorInt CursorKind = iota
+	CursorFloat
+	CursorString
+	CursorTime
+)
+
+// SortKey is one expression of a keyset-paginated ordering.
+// Expr must never be NULL, wrap nullable columns in COALESCE. The last key must make the ordering unique.
+type SortKey struct {
+	Expr string
+	Args []any
+	Desc bool
+	Kind CursorKind
+}
+
+// Cursor is the decoded form of an opaque pagination token.
+// It holds the sort key values of the row a page starts after.
+type Cursor struct {
+	Values   []any
+	Backward bool
+}
+
+type cursorToken struct {
+	Sort     string            `json:"s"`
+	Values   []json.RawMessage `json:"v"`
+	Backward bool              `json:"b,omitempty"`
+}
+
+// EncodeCursor builds an opaque token pointing after the row with the given sort key values.
+// A backward cursor fetches the rows before that row instead.
+func EncodeCursor(sort string, values []any, backward bool) string {
+	token := cursorToken{Sort: sort, Backward: backward}
+	for _, value := range values {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return ""
+		}
+		token.Values = append(token.Values, raw)
+	}
+	data, err := json.Marshal(token)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a token made by EncodeCursor for the same sort order and keys.
+func DecodeCursor(encoded string, sort string, keys []SortKey) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	token := cursorToken{}
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if token.Sort != sort || len(token.Values) != len(keys) {
+		return nil, ErrInvalidCursor
+	}
+
+	cursor := Cursor{Backward: token.Backward}
+	for i, key := range keys {
+		var value any
+		var err error
+		switch key.Kind {
+		case CursorInt:
+			var v int64
+			err = json.Unmarshal(token.Values[i], &v)
+			value = v
+		case CursorFloat:
+			var v float64
+			err = json.Unmarshal(token.Values[i], &v)
+			value = v
+		case CursorString:
+			var v string
+			err = json.Unmarshal(token.Values[i], &v)
+			value = v
+		case CursorTime:
+			var v time.Time
+			err = json.Unmarshal(token.Values[i], &v)
+			value = v
+		}
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		cursor.Values = append(cursor.Values, value)
+	}
+	return &cursor, nil
+}
+
+// KeysetScope orders the query by keys and, if a cursor is given, only keeps rows after it.
+// One row more than limit is fetched so KeysetPage can tell whether another page follows.
+func KeysetScope(keys []SortKey, cursor *Cursor, limit int) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		backward := cursor != nil && cursor.Backward
+
+		if cursor != nil {
+			// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ... with the comparison flipped for descending keys
+			alternatives := make([]string, 0, len(keys))
+			args := []any{}
+			for i, key := range keys {
+				terms := make([]string, 0, i+1)
+				for j := range i {
+					terms = append(terms, keys[j].Expr+" = ?")
+					args = append(args, keys[j].Args...)
+					args = append(args, cursor.Values[j])
+				}
+				op := " > ?"
+				if key.Desc != backward {
+					op = " < ?"
+				}
+				terms = append(terms, key.Expr+op)
+				args = append(args, key.Args...)
+				args = append(args, cursor.Values[i])
+				alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+			}
+			db = db.Where("("+strings.Join(alternatives, " OR ")+")", args...)
+		}
+
+		order := make([]string, 0, len(keys))
+		args := []any{}
+		for _, key := range keys {
+			direction := " ASC"
+			if key.Desc != backward {
+				direction = " DESC"
+			}
+			order = append(order, key.Expr+direction)
+			args = append(args, key.Args...)
+		}
+		return db.Order(clause.OrderBy{Expression: clause.Expr{SQL: strings.Join(order, ", "), Vars: args}}).
+			Limit(limit + 1)
+	}
+}
+
+// KeysetPage drops the extra row fetched by KeysetScope and puts a backward page back into display order.
+// It reports whether more rows exist in the direction the cursor was travelling.
+func KeysetPage[T any](rows []T, limit int, cursor *Cursor) ([]T, bool) {
+	hasMore := len(rows) > limit
+	if hasMore {
+		rows = rows[:limit]
+	}
+	if cursor != nil && cursor.Backward {
+		slices.Reverse(rows)
+	}
+	return rows, hasMore
+}
+
+// PageCursors returns the tokens for the pages after and before rows, empty where there is no such page.
+// offset is the legacy offset the page was fetched with, a page past the first always has a previous one.
+func PageCursors[T any](sort string, rows []T, values func(T) []any, hasMore bool, cursor *Cursor, offset uint) (next string, prev string) {
+	if len(rows) == 0 {
+		// Past either end, the cursor itself leads back to the rows on the other side
+		if cursor != nil && cursor.Backward {
+			return EncodeCursor(sort, cursor.Values, false), ""
+		} else if cursor != nil {
+			return "", EncodeCursor(sort, cursor.Values, true)
+		}
+		return "", ""
+	}
+
+	first := values(rows[0])
+	last := values(rows[len(rows)-1])
+	if cursor != nil && cursor.Backward {
+		next = EncodeCursor(sort, last, false)
+		if hasMore {
+			prev = EncodeCursor(sort, first, true)
+		}
+		return next, prev
+	}
+	if hasMore {
+		next = EncodeCursor(sort, last, false)
+	}
+	if cursor != nil || offset > 0 {
+		prev = EncodeCursor(sort, first, true)
+	}
+	return next, prev
+}
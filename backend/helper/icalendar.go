@@ -0,0 +1,140 @@
+package helper
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// CalendarMethod is the iTIP method of a calendar invitation (RFC 5546).
+type CalendarMethod string
+
+const (
+	// CalendarRequest invites attendees to an event, or updates an event they were invited to
+	CalendarRequest CalendarMethod = "REQUEST"
+	// CalendarCancel cancels an event attendees were invited to
+	CalendarCancel CalendarMethod = "CANCEL"
+)
+
+// icalendarTimeLayout writes times in UTC, as RFC 5545 dates with the Z suffix
+const icalendarTimeLayout = "20060102T150405Z"
+
+// icalendarLineLength is the longest a content line may be, in octets, before it has to be folded
+const icalendarLineLength = 75
+
+// CalendarAttendee is a person taking part in a calendar event.
+type CalendarAttendee struct {
+	Name  string
+	Email string
+}
+
+// CalendarEvent is a single event sent as an iCalendar invitation.
+// Updates and cancellations of an event must keep its UID and raise its Sequence,
+// so calendar clients replace the event they already have.
+type CalendarEvent struct {
+	UID         string
+	Sequence    uint
+	Method      CalendarMethod
+	Stamp       time.Time
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	Location    string
+	URL         string
+	Organizer   CalendarAttendee
+	Attendees   []CalendarAttendee
+}
+
+// ICS renders the event as an RFC 5545 calendar object.
+func (event *CalendarEvent) ICS() []byte {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//KU-Work//Interviews//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:" + string(event.Method),
+		"BEGIN:VEVENT",
+		"UID:" + escapeICalendarText(event.UID),
+		fmt.Sprintf("SEQUENCE:%d", event.Sequence),
+		"DTSTAMP:" + event.Stamp.UTC().Format(icalendarTimeLayout),
+		"DTSTART:" + event.Start.UTC().Format(icalendarTimeLayout),
+		"DTEND:" + event.End.UTC().Format(icalendarTimeLayout),
+		"SUMMARY:" + escapeICalendarText(event.Summary),
+	}
+	if event.Description != "" {
+		lines = append(lines, "DESCRIPTION:"+escapeICalendarText(event.Description))
+	}
+	if event.Location != "" {
+		lines = append(lines, "LOCATION:"+escapeICalendarText(event.Location))
+	}
+	if event.URL != "" {
+		lines = append(lines, "URL:"+event.URL)
+	}
+	if event.Organizer.Email != "" {
+		lines = append(lines, fmt.Sprintf("ORGANIZER;CN=%s:mailto:%s", quoteICalendarParam(event.Organizer.Name), event.Organizer.Email))
+	}
+	for _, attendee := range event.Attendees {
+		lines = append(lines, fmt.Sprintf("ATTENDEE;CN=%s;ROLE=REQ-PARTICIPANT;RSVP=FALSE:mailto:%s", quoteICalendarParam(attendee.Name), attendee.Email))
+	}
+	if event.Method == CalendarCancel {
+		lines = append(lines, "STATUS:CANCELLED")
+	} else {
+		lines = append(lines, "STATUS:CONFIRMED")
+	}
+	lines = append(lines, "END:VEVENT", "END:VCALENDAR")
+
+	builder := strings.Builder{}
+	for _, line := range lines {
+		builder.WriteString(foldICalendarLine(line))
+		builder.WriteString("\r\n")
+	}
+	return []byte(builder.String())
+}
+
+// escapeICalendarText escapes a TEXT value as described in RFC 5545 section 3.3.11
+func escapeICalendarText(value string) string {
+	value = strings.ReplaceAll(value, "\r\n", "\n")
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\n", `\n`,
+		"\r", "",
+	).Replace(value)
+}
+
+// quoteICalendarParam quotes a parameter value, dropping the characters a quoted value may not hold
+func quoteICalendarParam(value string) string {
+	value = strings.Map(func(r rune) rune {
+		if r == '"' || r < ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, value)
+	return `"` + value + `"`
+}
+
+// foldICalendarLine splits a content line into lines of at most 75 octets, without breaking UTF-8 characters.
+// Continuation lines start with a space as described in RFC 5545 section 3.1.
+func foldICalendarLine(line string) string {
+	if len(line) <= icalendarLineLength {
+		return line
+	}
+	builder := strings.Builder{}
+	limit := icalendarLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		builder.WriteString(line[:cut])
+		builder.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space counts towards the length of continuation lines
+		limit = icalendarLineLength - 1
+	}
+	builder.WriteString(line)
+	return builder.String()
+}
@@ -0,0 +1,15 @@
+package helper
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PublishedJobsScope limits a jobs query to jobs inside their publishing window at the given time.
+// It mirrors model.Job.IsPublishedAt for use in SQL queries.
+func PublishedJobsScope(now time.Time) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("(jobs.publish_at IS NULL OR jobs.publish_at <= ?) AND (jobs.unpublish_at IS NULL OR jobs.unpublish_at > ?)", now, now)
+	}
+}
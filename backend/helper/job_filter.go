@@ -0,0 +1,122 @@
+package helper
+
+import (
+	"fmt"
+	"ku-work/backend/database"
+	"ku-work/backend/model"
+	"math"
+
+	"gorm.io/gorm"
+)
+
+// JobFilter is the set of search criteria a student can apply to job posts.
+// It is shared by the job listing endpoint and saved job alerts so both match jobs the same way.
+type JobFilter struct {
+	Keyword  string
+	Location string
+	// WorkMode lists the accepted work modes, e.g. onsite, hybrid or remote
+	WorkMode []string
+	// Province is the canonical name of a province in the bundled location dataset
+	Province string
+	// Near limits jobs to those located within a radius of a point, jobs without coordinates never match
+	Near       *GeoRadius
+	JobType    []string
+	Experience []string
+	// MinSalary and MaxSalary are monthly figures in Currency, compared against each job's salary converted to the same period
+	MinSalary uint
+	MaxSalary uint
+	// Currency defaults to model.DefaultSalaryCurrency, only jobs paying in it match a salary bound
+	Currency string
+	// Skills are skill names or aliases, a job matches if it lists any of them
+	Skills []string
+}
+
+// GeoRadius is a circle on the map, given by its centre and radius in kilometres.
+type GeoRadius struct {
+	Latitude  float64
+	Longitude float64
+	RadiusKm  float64
+}
+
+// JobFilterScope limits a jobs query to jobs matching the filter.
+// A zero MaxSalary means there is no upper salary bound. Jobs with an undisclosed salary never match a salary bound.
+func JobFilterScope(filter JobFilter) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		// Match keywords against the maintained search document, every word is matched as a prefix
+		if tsQuery := BuildPrefixTSQuery(filter.Keyword); tsQuery != "" {
+			db = db.Where("jobs.search_document @@ to_tsquery(?, ?)", database.JobSearchConfig, tsQuery)
+		}
+		// Words text search cannot split, such as Thai, are looked up anywhere in the fields of the search document
+		for _, word := range SubstringSearchWords(filter.Keyword) {
+			pattern := "%" + EscapeLikePattern(word) + "%"
+			db = db.Where(
+				"(jobs.name ILIKE ? OR jobs.position ILIKE ? OR jobs.duration ILIKE ? OR jobs.description ILIKE ? OR "+
+					"EXISTS (SELECT 1 FROM users WHERE users.id = jobs.company_id AND users.username ILIKE ?))",
+				pattern, pattern, pattern, pattern, pattern,
+			)
+		}
+
+		hasMaxSalary := filter.MaxSalary != 0 && uint64(filter.MaxSalary) <= math.MaxInt64/model.SalaryPeriodsPerYear[model.SalaryPeriodHour]
+		if filter.MinSalary != 0 || hasMaxSalary {
+			currency := filter.Currency
+			if currency == "" {
+				currency = model.DefaultSalaryCurrency
+			}
+			db = db.Where("jobs.salary_currency = ? AND NOT jobs.salary_undisclosed", currency)
+		}
+		if filter.MinSalary != 0 {
+			db = db.Where(YearlySalaryExpr("jobs.min_salary")+" >= ?", model.YearlySalary(filter.MinSalary, model.SalaryPeriodMonth))
+		}
+		if hasMaxSalary {
+			db = db.Where(YearlySalaryExpr("jobs.max_salary")+" <= ?", model.YearlySalary(filter.MaxSalary, model.SalaryPeriodMonth))
+		}
+
+		if len(filter.Location) != 0 {
+			db = db.Where("jobs.location ILIKE ?", filter.Location)
+		}
+
+		if len(filter.WorkMode) != 0 {
+			db = db.Where("jobs.work_mode IN ?", filter.WorkMode)
+		}
+
+		if filter.Province != "" {
+			db = db.Where("jobs.province = ?", filter.Province)
+		}
+
+		if filter.Near != nil {
+			// The bounding box lets the latitude index discard far away jobs before computing distances
+			latDelta := filter.Near.RadiusKm / EarthRadiusKm * 180 / math.Pi
+			db = db.Where("jobs.latitude BETWEEN ? AND ?", filter.Near.Latitude-latDelta, filter.Near.Latitude+latDelta).
+				Where(DistanceKmExpr("jobs.latitude", "jobs.longitude")+" <= ?",
+					filter.Near.Latitude, filter.Near.Latitude, filter.Near.Longitude, filter.Near.RadiusKm)
+		}
+
+		if len(filter.JobType) != 0 {
+			db = db.Where("jobs.job_type IN ?", filter.JobType)
+		}
+
+		if len(filter.Experience) != 0 {
+			db = db.Where("jobs.experience IN ?", filter.Experience)
+		}
+
+		if len(filter.Skills) != 0 {
+			slugs := make([]string, 0, len(filter.Skills))
+			for _, skill := range filter.Skills {
+				slugs = append(slugs, NormalizeSkillName(skill))
+			}
+			db = db.Where("EXISTS (SELECT 1 FROM job_skills WHERE job_skills.job_id = jobs.id AND job_skills.skill_id IN (SELECT id FROM skills WHERE slug IN ? UNION SELECT skill_id FROM skill_aliases WHERE slug IN ?))", slugs, slugs)
+		}
+		return db
+	}
+}
+
+// YearlySalaryExpr is the SQL counterpart of model.YearlySalary for a salary column of the jobs table.
+func YearlySalaryExpr(column string) string {
+	return fmt.Sprintf("(%s::bigint * CASE jobs.salary_period WHEN '%s' THEN %d WHEN '%s' THEN %d WHEN '%s' THEN %d ELSE %d END)",
+		column,
+		model.SalaryPeriodHour, model.SalaryPeriodsPerYear[model.SalaryPeriodHour],
+		model.SalaryPeriodDay, model.SalaryPeriodsPerYear[model.SalaryPeriodDay],
+		model.SalaryPeriodYear, model.SalaryPeriodsPerYear[model.SalaryPeriodYear],
+		model.SalaryPeriodsPerYear[model.SalaryPeriodMonth],
+	)
+}
@@ -0,0 +1,127 @@
+package helper
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// thaiLocationsJSON is the bundled list of Thai provinces and districts with approximate centre coordinates.
+// It covers every province, Bangkok's districts, each province's capital district and the main employment districts.
+// Districts missing from the file are still accepted and placed at the centre of their province,
+// more districts can be added to the file without code changes.
+//
+//go:embed thai_locations.json
+var thaiLocationsJSON []byte
+
+var ErrUnknownProvince = errors.New("unknown province")
+
+// EarthRadiusKm is the mean radius of the Earth used for distance calculations.
+const EarthRadiusKm = 6371.0
+
+// District is a district (amphoe or khet) of a Thai province.
+type District struct {
+	Name      string  `json:"name"`
+	NameTh    string  `json:"nameTh"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Province is a Thai province with its districts. The coordinates are those of the provincial capital.
+type Province struct {
+	Name      string     `json:"name"`
+	NameTh    string     `json:"nameTh"`
+	Latitude  float64    `json:"latitude"`
+	Longitude float64    `json:"longitude"`
+	Districts []District `json:"districts"`
+}
+
+// Place is a location resolved against the bundled dataset, with the canonical English names.
+// The coordinates are those of the province when District is empty or not in the dataset.
+type Place struct {
+	Province  string
+	District  string
+	Latitude  float64
+	Longitude float64
+}
+
+var loadProvinces = sync.OnceValue(func() []Province {
+	provinces := []Province{}
+	if err := json.Unmarshal(thaiLocationsJSON, &provinces); err != nil {
+		panic(fmt.Sprintf("invalid bundled location dataset: %v", err))
+	}
+	return provinces
+})
+
+// Provinces returns every province in the bundled dataset, ordered as in the file.
+// The returned slice is shared and must not be modified.
+func Provinces() []Province {
+	return loadProvinces()
+}
+
+// normalizePlaceName turns a place name into the key used to match it, so "  chiang  MAI" matches "Chiang Mai".
+func normalizePlaceName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// FindProvince looks up a province by its English or Thai name, ignoring case and extra spaces.
+func FindProvince(name string) (*Province, bool) {
+	key := normalizePlaceName(name)
+	if key == "" {
+		return nil, false
+	}
+	provinces := loadProvinces()
+	for i := range provinces {
+		if normalizePlaceName(provinces[i].Name) == key || normalizePlaceName(provinces[i].NameTh) == key {
+			return &provinces[i], true
+		}
+	}
+	return nil, false
+}
+
+// FindDistrict looks up one of the province's districts by its English or Thai name, ignoring case and extra spaces.
+func (p *Province) FindDistrict(name string) (*District, bool) {
+	key := normalizePlaceName(name)
+	if key == "" {
+		return nil, false
+	}
+	for i := range p.Districts {
+		if normalizePlaceName(p.Districts[i].Name) == key || normalizePlaceName(p.Districts[i].NameTh) == key {
+			return &p.Districts[i], true
+		}
+	}
+	return nil, false
+}
+
+// ResolvePlace checks a province and optional district against the bundled dataset and returns their coordinates.
+// It returns ErrUnknownProvince if the province does not match. A district the dataset does not list
+// is kept as given, with extra spaces removed, and placed at the centre of the province.
+func ResolvePlace(province string, district string) (Place, error) {
+	p, ok := FindProvince(province)
+	if !ok {
+		return Place{}, ErrUnknownProvince
+	}
+	place := Place{Province: p.Name, Latitude: p.Latitude, Longitude: p.Longitude}
+	if strings.TrimSpace(district) == "" {
+		return place, nil
+	}
+	d, ok := p.FindDistrict(district)
+	if !ok {
+		place.District = strings.Join(strings.Fields(district), " ")
+		return place, nil
+	}
+	place.District = d.Name
+	place.Latitude = d.Latitude
+	place.Longitude = d.Longitude
+	return place, nil
+}
+
+// DistanceKmExpr is the SQL great-circle (haversine) distance in kilometres between a row's coordinate columns and a point.
+// It takes the point's latitude, latitude and longitude, in that order, as arguments.
+func DistanceKmExpr(latColumn string, lonColumn string) string {
+	return fmt.Sprintf("(2 * %f * ASIN(LEAST(1, SQRT(POWER(SIN(RADIANS(%s - ?) / 2), 2) + COS(RADIANS(?)) * COS(RADIANS(%s)) * POWER(SIN(RADIANS(%s - ?) / 2), 2)))))",
+		EarthRadiusKm, latColumn, latColumn, lonColumn)
+}
@@ -0,0 +1,75 @@
+package helper
+
+import (
+	"strings"
+	"unicode"
+)
+
+// BuildPrefixTSQuery turns free-text user input into a to_tsquery expression
+// where every word must match as a prefix, e.g. "soft eng" becomes "soft:* & eng:*".
+// Words that text search cannot match are left out, they are returned by SubstringSearchWords instead.
+// Characters that have meaning in tsquery syntax are stripped, so the result is always safe to pass to to_tsquery.
+// Returns an empty string if the input contains no searchable words.
+func BuildPrefixTSQuery(input string) string {
+	terms := make([]string, 0)
+	for _, word := range tsQueryWords(input) {
+		if !needsSubstringSearch(word) {
+			terms = append(terms, word+":*")
+		}
+	}
+	return strings.Join(terms, " & ")
+}
+
+// SubstringSearchWords returns the words of free-text user input that have to be matched as substrings
+// rather than through text search. These are the words with letters outside the Latin script:
+// Thai is written without spaces between words, so text search indexes a whole phrase as a single
+// word and would only find a Thai keyword at the start of a phrase.
+func SubstringSearchWords(input string) []string {
+	words := make([]string, 0)
+	for _, word := range tsQueryWords(input) {
+		if needsSubstringSearch(word) {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// needsSubstringSearch reports whether a word has letters outside the Latin script.
+func needsSubstringSearch(word string) bool {
+	for _, r := range word {
+		if unicode.IsLetter(r) && !unicode.Is(unicode.Latin, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// EscapeLikePattern escapes the LIKE wildcards in user input.
+func EscapeLikePattern(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+// BuildAnyTSQuery turns free text into a to_tsquery expression that matches any of its words,
+// e.g. "Computer Engineering" becomes "computer | engineering".
+// Like BuildPrefixTSQuery the result is always safe to pass to to_tsquery, and empty if there is nothing to search for.
+func BuildAnyTSQuery(input string) string {
+	return strings.Join(tsQueryWords(input), " | ")
+}
+
+// tsQueryWords splits input into lowercased words with every character that has meaning in tsquery syntax removed.
+func tsQueryWords(input string) []string {
+	terms := make([]string, 0)
+	for word := range strings.FieldsSeq(input) {
+		cleaned := strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) {
+				return unicode.ToLower(r)
+			}
+			return -1
+		}, word)
+		if cleaned == "" {
+			continue
+		}
+		terms = append(terms, cleaned)
+	}
+	return terms
+}
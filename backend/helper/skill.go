@@ -0,0 +1,63 @@
+package helper
+
+import (
+	"ku-work/backend/model"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// NormalizeSkillName turns a skill name or alias into the key used to match it,
+// so "  Node JS " and "node js" resolve to the same skill.
+func NormalizeSkillName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// ResolveSkillIDs maps skill names or aliases to the IDs of the skills they refer to.
+// The IDs are de-duplicated and keep the order of the first name that resolved to them.
+// Names that match no skill are returned in unknown.
+func ResolveSkillIDs(db *gorm.DB, names []string) (ids []uint, unknown []string, err error) {
+	slugs := make([]string, 0, len(names))
+	for _, name := range names {
+		if slug := NormalizeSkillName(name); slug != "" {
+			slugs = append(slugs, slug)
+		}
+	}
+	if len(slugs) == 0 {
+		return []uint{}, []string{}, nil
+	}
+
+	type slugMatch struct {
+		ID   uint
+		Slug string
+	}
+	var matches []slugMatch
+	if err := db.Model(&model.Skill{}).Select("id, slug").Where("slug IN ?", slugs).Scan(&matches).Error; err != nil {
+		return nil, nil, err
+	}
+	var aliasMatches []slugMatch
+	if err := db.Model(&model.SkillAlias{}).Select("skill_id AS id, slug").Where("slug IN ?", slugs).Scan(&aliasMatches).Error; err != nil {
+		return nil, nil, err
+	}
+
+	bySlug := make(map[string]uint, len(matches)+len(aliasMatches))
+	for _, match := range append(matches, aliasMatches...) {
+		bySlug[match.Slug] = match.ID
+	}
+
+	ids = []uint{}
+	unknown = []string{}
+	seen := make(map[uint]bool)
+	for _, slug := range slugs {
+		id, ok := bySlug[slug]
+		if !ok {
+			unknown = append(unknown, slug)
+			continue
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, unknown, nil
+}
@@ -0,0 +1,26 @@
+package helper
+
+import (
+	"os"
+	"strings"
+)
+
+// GetFrontendURL returns the public base URL of the frontend without a trailing slash
+// Defaults to http://localhost:3000 if not set
+func GetFrontendURL() string {
+	frontendURL := os.Getenv("FRONTEND_URL")
+	if frontendURL == "" {
+		return "http://localhost:3000"
+	}
+	return strings.TrimRight(frontendURL, "/")
+}
+
+// GetPublicAPIURL returns the public base URL of this API without a trailing slash
+// Defaults to http://localhost:8000 if not set
+func GetPublicAPIURL() string {
+	publicAPIURL := os.Getenv("PUBLIC_API_URL")
+	if publicAPIURL == "" {
+		return "http://localhost:8000"
+	}
+	return strings.TrimRight(publicAPIURL, "/")
+}
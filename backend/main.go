@@ -59,7 +59,7 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	scheduler := setupScheduler(ctx, db, emailService)
+	scheduler := setupScheduler(ctx, db, redisClient, emailService)
 	scheduler.Start()
 
 	router := setupRouter(db, redisClient, emailService, aiService, fileService)
@@ -102,7 +102,7 @@ func initializeServices(db *gorm.DB) (*services.EmailService, *services.AIServic
 }
 
 // setupScheduler configures and returns the background task scheduler
-func setupScheduler(ctx context.Context, db *gorm.DB, emailService *services.EmailService) *helper.Scheduler {
+func setupScheduler(ctx context.Context, db *gorm.DB, redisClient *redis.Client, emailService *services.EmailService) *helper.Scheduler {
 	scheduler := helper.NewScheduler(ctx)
 
 	// Token cleanup task
@@ -110,12 +110,41 @@ func setupScheduler(ctx context.Context, db *gorm.DB, emailService *services.Ema
 		return helper.CleanupExpiredTokens(db)
 	})
 
+	// Job views are counted in Redis and written to Postgres in batches
+	jobAnalyticsService := services.NewJobAnalyticsService(db, redisClient)
+	scheduler.AddTask("job-view-flush", getJobViewFlushInterval(), func() error {
+		return jobAnalyticsService.FlushJobViews()
+	})
+
+	// Job lifecycle tasks run without the email service too, companies are only notified when it is available
+	jobLifecycleService, err := services.NewJobLifecycleService(db, emailService)
+	if err != nil {
+		slog.Warn("Job lifecycle service initialization failed", "error", err)
+	} else {
+		scheduler.AddTask("job-deadline-close", getJobLifecycleInterval(), func() error {
+			return jobLifecycleService.CloseExpiredJobs()
+		})
+		scheduler.AddTask("job-publish-notify", getJobLifecycleInterval(), func() error {
+			return jobLifecycleService.PublishScheduledJobs()
+		})
+	}
+
 	// Email retry task (if email service is available)
 	if emailService != nil {
 		interval := getEmailRetryInterval()
 		scheduler.AddTask("email-retry", interval, func() error {
 			return emailService.RetryFailedEmails()
 		})
+
+		jobAlertService, err := services.NewJobAlertService(db, emailService)
+		if err != nil {
+			slog.Warn("Job alert service initialization failed", "error", err)
+		} else {
+			// Each alert keeps its own daily or weekly schedule, this only decides how often due alerts are picked up
+			scheduler.AddTask("job-alert-digest", time.Hour, func() error {
+				return jobAlertService.SendJobAlertDigests()
+			})
+		}
 	}
 
 	// Account anonymization task - runs daily to anonymize accounts past grace period
@@ -145,6 +174,40 @@ func getEmailRetryInterval() time.Duration {
 	return time.Duration(minutes) * time.Minute
 }
 
+// getJobLifecycleInterval reads the job deadline/publishing check interval from environment or returns default
+func getJobLifecycleInterval() time.Duration {
+	defaultInterval := 5 * time.Minute
+
+	intervalStr, hasInterval := os.LookupEnv("JOB_LIFECYCLE_INTERVAL_MINUTES")
+	if !hasInterval {
+		return defaultInterval
+	}
+
+	minutes, err := strconv.Atoi(intervalStr)
+	if err != nil || minutes <= 0 {
+		return defaultInterval
+	}
+
+	return time.Duration(minutes) * time.Minute
+}
+
+// getJobViewFlushInterval reads how often buffered job views are written to the database from environment or returns default
+func getJobViewFlushInterval() time.Duration {
+	defaultInterval := 5 * time.Minute
+
+	intervalStr, hasInterval := os.LookupEnv("JOB_VIEW_FLUSH_INTERVAL_MINUTES")
+	if !hasInterval {
+		return defaultInterval
+	}
+
+	minutes, err := strconv.Atoi(intervalStr)
+	if err != nil || minutes <= 0 {
+		return defaultInterval
+	}
+
+	return time.Duration(minutes) * time.Minute
+}
+
 // getAccountDeletionInterval reads the account anonymization check interval from environment or returns default
 func getAccountDeletionInterval() time.Duration {
 	defaultInterval := 24 * time.Hour // Check once per day by default (PDPA compliant anonymization)
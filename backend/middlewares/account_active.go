@@ -30,6 +30,11 @@ func AccountActiveMiddleware(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
+		if helper.IsSuspended(db, userID) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "account suspended"})
+			return
+		}
+
 		c.Next()
 	}
 }
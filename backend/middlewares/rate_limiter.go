@@ -80,3 +80,28 @@ func RateLimiterWithLimits(redisClient *redis.Client, minuteLimit, hourLimit int
 		ctx.Next()
 	}
 }
+
+// UserRateLimiterWithLimits creates a rate limiting middleware that counts requests per authenticated user instead of per IP.
+// scope keeps the counters of different actions apart. It must run after AuthMiddleware.
+// minuteLimit: maximum attempts per minute per user
+// hourLimit: maximum attempts per hour per user
+func UserRateLimiterWithLimits(redisClient *redis.Client, scope string, minuteLimit, hourLimit int) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID := ctx.GetString("userID")
+		if userID == "" {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		allowed, message := checkLimit(redisClient, fmt.Sprintf("%s:%s", scope, userID), minuteLimit, hourLimit)
+		if !allowed {
+			ctx.JSON(http.StatusTooManyRequests, gin.H{
+				"error": message,
+			})
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// ApplicationStatusChange records one move of a job application, from being sent to its latest stage.
+// Changes are only ever added, so together they are the application's timeline.
+// FromStatus and FromStage are empty for the change that created the application.
+type ApplicationStatusChange struct {
+	ID             uint                 `gorm:"primaryKey" json:"id"`
+	CreatedAt      time.Time            `json:"createdAt"`
+	JobID          uint                 `gorm:"index:idx_application_status_changes_application" json:"jobId"`
+	UserID         string               `gorm:"type:uuid;index:idx_application_status_changes_application" json:"userId"`
+	JobApplication JobApplication       `gorm:"foreignKey:JobID,UserID;references:JobID,UserID;constraint:OnDelete:CASCADE;" json:"-"`
+	ActorID        string               `gorm:"type:uuid" json:"actorId"`
+	FromStatus     JobApplicationStatus `json:"fromStatus"`
+	ToStatus       JobApplicationStatus `json:"toStatus"`
+	FromStage      string               `json:"fromStage"`
+	ToStage        string               `json:"toStage"`
+	Reason         string               `json:"reason"`
+}
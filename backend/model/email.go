@@ -1,6 +1,10 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
 
 type MailLogStatus string
 
@@ -21,4 +25,13 @@ type MailLog struct {
 	ErrorCode        string        `json:"errorCode,omitempty"`
 	ErrorDescription string        `json:"errorDesc,omitempty"`
 	RetryCount       int           `gorm:"default:0" json:"retryCount"` // Number of retry attempts made
+	// Attachments are kept so that retried emails are sent with them
+	Attachments datatypes.JSONSlice[MailAttachment] `json:"-"`
+}
+
+// MailAttachment is a file sent along with an email.
+type MailAttachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+	Content     []byte `json:"content"`
 }
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+type InterviewStatus string
+
+const (
+	// InterviewProposed interviews wait for the student to pick one of the slots
+	InterviewProposed InterviewStatus = "proposed"
+	// InterviewScheduled interviews take place in the selected slot
+	InterviewScheduled InterviewStatus = "scheduled"
+	InterviewCancelled InterviewStatus = "cancelled"
+)
+
+// Interview is arranged by a company with a student who applied to one of its jobs.
+// The company proposes slots and the student picks one, after which both are sent a calendar invitation.
+type Interview struct {
+	ID             uint            `gorm:"primaryKey" json:"id"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	UpdatedAt      time.Time       `json:"updatedAt"`
+	JobID          uint            `gorm:"index:idx_interviews_application" json:"jobId"`
+	UserID         string          `gorm:"type:uuid;index:idx_interviews_application" json:"userId"`
+	JobApplication JobApplication  `gorm:"foreignKey:JobID,UserID;references:JobID,UserID;constraint:OnDelete:CASCADE;" json:"-"`
+	Status         InterviewStatus `gorm:"not null;default:'proposed'" json:"status"`
+	Note           string          `json:"note"`
+	Slots          []InterviewSlot `gorm:"constraint:OnDelete:CASCADE;" json:"slots"`
+	SelectedSlotID *uint           `json:"selectedSlotId"`
+	CancelReason   string          `json:"cancelReason,omitempty"`
+	// Sequence is the revision of the calendar invitation, raised every time an updated one is sent
+	Sequence uint `gorm:"not null;default:0" json:"-"`
+	// InvitedAt is when the first calendar invitation was sent, nil if there has been none
+	InvitedAt *time.Time `json:"-"`
+}
+
+// InterviewSlot is a time proposed for an interview. Interviews take place online at
+// MeetingURL, in person at Location, or both.
+type InterviewSlot struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	InterviewID     uint      `gorm:"index" json:"-"`
+	StartsAt        time.Time `gorm:"not null" json:"startsAt"`
+	DurationMinutes uint      `gorm:"not null" json:"durationMinutes"`
+	MeetingURL      string    `json:"meetingUrl,omitempty"`
+	Location        string    `json:"location,omitempty"`
+}
+
+// EndsAt returns when the slot is over.
+func (slot *InterviewSlot) EndsAt() time.Time {
+	return slot.StartsAt.Add(time.Duration(slot.DurationMinutes) * time.Minute)
+}
+
+// CalendarUID identifies the interview in calendar invitations, it stays the same across reschedules.
+func (interview *Interview) CalendarUID() string {
+	return fmt.Sprintf("interview-%d@ku-work", interview.ID)
+}
+
+// SelectedSlot returns the slot the interview takes place in, or nil if none was picked.
+func (interview *Interview) SelectedSlot() *InterviewSlot {
+	if interview.SelectedSlotID == nil {
+		return nil
+	}
+	for i := range interview.Slots {
+		if interview.Slots[i].ID == *interview.SelectedSlotID {
+			return &interview.Slots[i]
+		}
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -27,6 +28,44 @@ const (
 	JobTypeInternship JobType = "internship"
 )
 
+type WorkMode string
+
+const (
+	WorkModeOnsite WorkMode = "onsite"
+	WorkModeHybrid WorkMode = "hybrid"
+	WorkModeRemote WorkMode = "remote"
+)
+
+type SalaryPeriod string
+
+const (
+	SalaryPeriodHour  SalaryPeriod = "hour"
+	SalaryPeriodDay   SalaryPeriod = "day"
+	SalaryPeriodMonth SalaryPeriod = "month"
+	SalaryPeriodYear  SalaryPeriod = "year"
+)
+
+// DefaultSalaryCurrency is the ISO 4217 currency of jobs that do not state one.
+const DefaultSalaryCurrency = "THB"
+
+// SalaryPeriodsPerYear is how many of each pay period make up a year, assuming 8 hour days and 22 working days a month.
+// Salaries are compared as yearly figures so that the arithmetic stays in whole numbers.
+var SalaryPeriodsPerYear = map[SalaryPeriod]uint64{
+	SalaryPeriodHour:  8 * 22 * 12,
+	SalaryPeriodDay:   22 * 12,
+	SalaryPeriodMonth: 12,
+	SalaryPeriodYear:  1,
+}
+
+// YearlySalary converts an amount paid per period to a yearly figure. Unknown periods are treated as monthly.
+func YearlySalary(amount uint, period SalaryPeriod) uint64 {
+	perYear, ok := SalaryPeriodsPerYear[period]
+	if !ok {
+		perYear = SalaryPeriodsPerYear[SalaryPeriodMonth]
+	}
+	return uint64(amount) * perYear
+}
+
 type JobApprovalStatus string
 
 const (
@@ -36,23 +75,61 @@ const (
 )
 
 type Job struct {
-	ID                  uint              `gorm:"primaryKey" json:"id"`
-	CreatedAt           time.Time         `json:"createdAt"`
-	Name                string            `json:"name"`
-	CompanyID           string            `gorm:"type:uuid" json:"companyId"`
-	Company             Company           `gorm:"foreignKey:CompanyID;constraint:OnDelete:CASCADE;" json:"company"`
-	Position            string            `json:"position"`
-	Duration            string            `json:"duration"`
-	Description         string            `json:"description"`
-	Location            string            `json:"location"`
-	JobType             JobType           `json:"jobType"`
-	Experience          ExperienceType    `json:"experienceType"`
-	MinSalary           uint              `json:"minSalary"`
-	MaxSalary           uint              `json:"maxSalary"`
-	ApprovalStatus      JobApprovalStatus `json:"approvalStatus"`
-	IsOpen              bool              `json:"open"`
-	NotifyOnApplication bool              `json:"notifyOnApplication default:true"`
-	JobApplications     []JobApplication  `gorm:"foreignkey:JobID;constraint:OnDelete:CASCADE;" json:"-"`
+	ID                uint              `gorm:"primaryKey" json:"id"`
+	CreatedAt         time.Time         `json:"createdAt"`
+	Name              string            `json:"name"`
+	CompanyID         string            `gorm:"type:uuid" json:"companyId"`
+	Company           Company           `gorm:"foreignKey:CompanyID;constraint:OnDelete:CASCADE;" json:"company"`
+	Position          string            `json:"position"`
+	Duration          string            `json:"duration"`
+	Description       string            `json:"description"`
+	Location          string            `json:"location"`
+	WorkMode          WorkMode          `gorm:"default:onsite" json:"workMode"`
+	Province          string            `gorm:"index" json:"province"`
+	District          string            `json:"district"`
+	Latitude          *float64          `gorm:"index" json:"latitude"`
+	Longitude         *float64          `json:"longitude"`
+	JobType           JobType           `json:"jobType"`
+	Experience        ExperienceType    `json:"experienceType"`
+	MinSalary         uint              `json:"minSalary"`
+	MaxSalary         uint              `json:"maxSalary"`
+	SalaryCurrency    string            `gorm:"size:3;default:THB" json:"salaryCurrency"`
+	SalaryPeriod      SalaryPeriod      `gorm:"default:month" json:"salaryPeriod"`
+	SalaryUndisclosed bool              `json:"salaryUndisclosed"`
+	ApprovalStatus    JobApprovalStatus `json:"approvalStatus"`
+	ApprovedAt        *time.Time        `gorm:"index" json:"approvedAt"`
+	IsOpen            bool              `json:"open"`
+	// Openings is how many people the company is hiring, the job closes once that many applications are accepted.
+	// Nil means the company has not said and the job stays open until it is closed by hand.
+	Openings                *uint `json:"openings"`
+	NotifyPendingWhenFilled bool  `json:"notifyPendingWhenFilled"`
+	// Pipeline is the job's own hiring stages, empty for jobs that use DefaultPipeline
+	Pipeline            datatypes.JSONSlice[PipelineStage] `json:"pipeline"`
+	ApplicationDeadline *time.Time                         `gorm:"index" json:"applicationDeadline"`
+	PublishAt           *time.Time                         `gorm:"index" json:"publishAt"`
+	UnpublishAt         *time.Time                         `gorm:"index" json:"unpublishAt"`
+	PublishNotifiedAt   *time.Time                         `json:"-"`
+	NotifyOnApplication bool                               `json:"notifyOnApplication default:true"`
+	ClonedFromID        *uint                              `gorm:"index" json:"clonedFromId"`
+	ClonedFrom          *Job                               `gorm:"foreignKey:ClonedFromID;constraint:OnDelete:SET NULL;" json:"-"`
+	TemplateID          *uint                              `gorm:"index" json:"templateId"`
+	Template            *JobTemplate                       `gorm:"foreignKey:TemplateID;constraint:OnDelete:SET NULL;" json:"-"`
+	JobApplications     []JobApplication                   `gorm:"foreignkey:JobID;constraint:OnDelete:CASCADE;" json:"-"`
+	Questions           []JobQuestion                      `gorm:"foreignKey:JobID;constraint:OnDelete:CASCADE;" json:"-"`
+	// SearchDocument is maintained by a database trigger (see database.SetupJobSearch).
+	SearchDocument string `gorm:"type:tsvector;index:idx_jobs_search_document,type:gin;->:false;<-:false" json:"-"`
+}
+
+// IsPublishedAt reports whether the job is inside its publishing window at the given time.
+// Jobs without PublishAt or UnpublishAt are unbounded on that side.
+func (job *Job) IsPublishedAt(t time.Time) bool {
+	if job.PublishAt != nil && job.PublishAt.After(t) {
+		return false
+	}
+	if job.UnpublishAt != nil && !job.UnpublishAt.After(t) {
+		return false
+	}
+	return true
 }
 
 type JobApplicationStatus string
@@ -61,6 +138,8 @@ const (
 	JobApplicationAccepted JobApplicationStatus = "accepted"
 	JobApplicationRejected JobApplicationStatus = "rejected"
 	JobApplicationPending  JobApplicationStatus = "pending"
+	// JobApplicationWithdrawn is set by the student, the company can no longer change it
+	JobApplicationWithdrawn JobApplicationStatus = "withdrawn"
 )
 
 type JobApplication struct {
@@ -70,7 +149,18 @@ type JobApplication struct {
 	ContactPhone string               `json:"phone"`
 	ContactEmail string               `json:"email"`
 	Status       JobApplicationStatus `json:"status"`
-	Files        []File               `gorm:"many2many:job_application_has_file;constraint:OnDelete:CASCADE;" json:"files"`
+	// Stage is the key of the pipeline stage the application is in, Status follows from its outcome.
+	// Students only see Status, so the stage is left out of their responses.
+	Stage       string     `gorm:"index;default:''" json:"stage,omitempty"`
+	WithdrawnAt *time.Time `json:"withdrawnAt"`
+	// Answers are only returned by the detailed application view
+	Answers datatypes.JSONSlice[ApplicationAnswer] `json:"-"`
+	// RecruiterNotes and Rating, from 1 to 5, are the company's private evaluation of the applicant.
+	// They are only returned to the company that posted the job.
+	RecruiterNotes string     `json:"-"`
+	Rating         *uint      `gorm:"index" json:"-"`
+	EvaluatedAt    *time.Time `json:"-"`
+	Files          []File     `gorm:"many2many:job_application_has_file;constraint:OnDelete:CASCADE;" json:"files"`
 }
 
 // BeforeDelete is a GORM hook that deletes associated files from storage.
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+type JobAlertFrequency string
+
+const (
+	JobAlertDaily  JobAlertFrequency = "daily"
+	JobAlertWeekly JobAlertFrequency = "weekly"
+)
+
+// JobAlert is a student's saved job search that is periodically emailed as a digest of newly approved jobs.
+type JobAlert struct {
+	ID               uint                        `gorm:"primaryKey" json:"id"`
+	CreatedAt        time.Time                   `json:"createdAt"`
+	UpdatedAt        time.Time                   `json:"updatedAt"`
+	UserID           string                      `gorm:"type:uuid;index" json:"-"`
+	Student          Student                     `gorm:"foreignKey:UserID;references:UserID;constraint:OnDelete:CASCADE;" json:"-"`
+	Name             string                      `json:"name"`
+	Keyword          string                      `json:"keyword"`
+	Location         string                      `json:"location"`
+	JobType          datatypes.JSONSlice[string] `json:"jobType"`
+	Experience       datatypes.JSONSlice[string] `json:"experience"`
+	MinSalary        uint                        `json:"minSalary"`
+	MaxSalary        uint                        `json:"maxSalary"`
+	Frequency        JobAlertFrequency           `json:"frequency"`
+	LastSentAt       time.Time                   `gorm:"index" json:"lastSentAt"`
+	UnsubscribeToken string                      `gorm:"uniqueIndex" json:"-"`
+}
+
+// Interval returns how long the alert waits between digests.
+func (alert *JobAlert) Interval() time.Duration {
+	if alert.Frequency == JobAlertWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
@@ -0,0 +1,75 @@
+package model
+
+// PipelineStage is a step of a job's hiring pipeline. Outcome is the simplified status students see
+// while their application is in the stage, so companies can name and order stages as they like.
+type PipelineStage struct {
+	Key     string               `json:"key"`
+	Name    string               `json:"name"`
+	Outcome JobApplicationStatus `json:"outcome"`
+}
+
+// DefaultPipeline is used by jobs that do not define their own stages.
+var DefaultPipeline = []PipelineStage{
+	{Key: "applied", Name: "Applied", Outcome: JobApplicationPending},
+	{Key: "screening", Name: "Screening", Outcome: JobApplicationPending},
+	{Key: "interview", Name: "Interview", Outcome: JobApplicationPending},
+	{Key: "offer", Name: "Offer", Outcome: JobApplicationPending},
+	{Key: "hired", Name: "Hired", Outcome: JobApplicationAccepted},
+	{Key: "rejected", Name: "Rejected", Outcome: JobApplicationRejected},
+}
+
+// Stages returns the job's pipeline, or the default one if it has none.
+func (job *Job) Stages() []PipelineStage {
+	if len(job.Pipeline) == 0 {
+		return DefaultPipeline
+	}
+	return job.Pipeline
+}
+
+// FindStage returns the index of the stage with the given key, or -1.
+func FindStage(stages []PipelineStage, key string) int {
+	for i, stage := range stages {
+		if stage.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// FirstStageWithOutcome returns the index of the first stage whose outcome is the given status, or -1.
+func FirstStageWithOutcome(stages []PipelineStage, status JobApplicationStatus) int {
+	for i, stage := range stages {
+		if stage.Outcome == status {
+			return i
+		}
+	}
+	return -1
+}
+
+// ApplicationStage returns the index of the stage an application is in.
+// Applications from before pipelines, or whose stage was removed, are placed by their status.
+func ApplicationStage(stages []PipelineStage, stage string, status JobApplicationStatus) int {
+	if i := FindStage(stages, stage); i != -1 && stages[i].Outcome == status {
+		return i
+	}
+	return FirstStageWithOutcome(stages, status)
+}
+
+// CanMoveToStage reports whether an application may move between two stages of a pipeline.
+// Applications in progress move forward, possibly skipping stages, or are rejected from anywhere.
+// Rejected applications can be reconsidered by moving them back into progress,
+// and hired applications can only be rejected, for example when an offer falls through.
+func CanMoveToStage(stages []PipelineStage, from int, to int) bool {
+	if from == to {
+		return true
+	}
+	switch stages[from].Outcome {
+	case JobApplicationPending:
+		return to > from || stages[to].Outcome == JobApplicationRejected
+	case JobApplicationRejected:
+		return stages[to].Outcome == JobApplicationPending
+	case JobApplicationAccepted:
+		return stages[to].Outcome == JobApplicationRejected
+	}
+	return false
+}
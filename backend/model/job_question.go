@@ -0,0 +1,38 @@
+package model
+
+import "gorm.io/datatypes"
+
+type JobQuestionType string
+
+const (
+	JobQuestionShortText    JobQuestionType = "short_text"
+	JobQuestionLongText     JobQuestionType = "long_text"
+	JobQuestionSingleChoice JobQuestionType = "single_choice"
+	JobQuestionMultiChoice  JobQuestionType = "multi_choice"
+	JobQuestionDate         JobQuestionType = "date"
+	JobQuestionURL          JobQuestionType = "url"
+	JobQuestionYesNo        JobQuestionType = "yes_no"
+)
+
+// JobQuestion is a screening question students answer when applying to a job.
+// Questions are asked in the order of Position.
+type JobQuestion struct {
+	ID       uint                        `gorm:"primaryKey" json:"id"`
+	JobID    uint                        `gorm:"index" json:"-"`
+	Job      Job                         `gorm:"foreignKey:JobID;constraint:OnDelete:CASCADE;" json:"-"`
+	Position uint                        `json:"position"`
+	Type     JobQuestionType             `json:"type"`
+	Prompt   string                      `json:"prompt"`
+	Required bool                        `json:"required"`
+	Choices  datatypes.JSONSlice[string] `json:"choices"`
+}
+
+// ApplicationAnswer is a student's answer to a screening question.
+// The prompt and type are copied from the question so that answers stay readable after the questions are edited.
+// Value is a string, except for multi_choice where it is a list of strings and yes_no where it is a bool.
+type ApplicationAnswer struct {
+	QuestionID uint            `json:"questionId"`
+	Prompt     string          `json:"prompt"`
+	Type       JobQuestionType `json:"type"`
+	Value      any             `json:"value"`
+}
@@ -0,0 +1,104 @@
+package model
+
+import (
+	"slices"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// JobFieldChange is a single field of a job that was changed by an edit.
+type JobFieldChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+// JobRevision records one edit of a job as a field-level diff.
+type JobRevision struct {
+	ID                 uint                                `gorm:"primaryKey" json:"id"`
+	CreatedAt          time.Time                           `json:"createdAt"`
+	JobID              uint                                `gorm:"index" json:"jobId"`
+	Job                Job                                 `gorm:"foreignKey:JobID;constraint:OnDelete:CASCADE;" json:"-"`
+	EditorID           string                              `gorm:"type:uuid" json:"editorId"`
+	Changes            datatypes.JSONSlice[JobFieldChange] `json:"changes"`
+	RequiresReapproval bool                                `json:"requiresReapproval"`
+}
+
+// MaterialJobFields are the fields whose change sends an approved job back for review.
+var MaterialJobFields = map[string]bool{
+	"name":              true,
+	"position":          true,
+	"duration":          true,
+	"description":       true,
+	"location":          true,
+	"workMode":          true,
+	"province":          true,
+	"district":          true,
+	"experienceType":    true,
+	"minSalary":         true,
+	"maxSalary":         true,
+	"salaryCurrency":    true,
+	"salaryPeriod":      true,
+	"salaryUndisclosed": true,
+	"jobType":           true,
+	"questions":         true,
+}
+
+// DiffJobs lists the fields that differ between two versions of a job, named as in the job JSON.
+func DiffJobs(before *Job, after *Job) []JobFieldChange {
+	changes := []JobFieldChange{}
+	addIfChanged := func(field string, oldValue any, newValue any, changed bool) {
+		if changed {
+			changes = append(changes, JobFieldChange{Field: field, Old: oldValue, New: newValue})
+		}
+	}
+	addIfChanged("name", before.Name, after.Name, before.Name != after.Name)
+	addIfChanged("position", before.Position, after.Position, before.Position != after.Position)
+	addIfChanged("duration", before.Duration, after.Duration, before.Duration != after.Duration)
+	addIfChanged("description", before.Description, after.Description, before.Description != after.Description)
+	addIfChanged("location", before.Location, after.Location, before.Location != after.Location)
+	addIfChanged("workMode", before.WorkMode, after.WorkMode, before.WorkMode != after.WorkMode)
+	addIfChanged("province", before.Province, after.Province, before.Province != after.Province)
+	addIfChanged("district", before.District, after.District, before.District != after.District)
+	addIfChanged("jobType", before.JobType, after.JobType, before.JobType != after.JobType)
+	addIfChanged("experienceType", before.Experience, after.Experience, before.Experience != after.Experience)
+	addIfChanged("minSalary", before.MinSalary, after.MinSalary, before.MinSalary != after.MinSalary)
+	addIfChanged("maxSalary", before.MaxSalary, after.MaxSalary, before.MaxSalary != after.MaxSalary)
+	addIfChanged("salaryCurrency", before.SalaryCurrency, after.SalaryCurrency, before.SalaryCurrency != after.SalaryCurrency)
+	addIfChanged("salaryPeriod", before.SalaryPeriod, after.SalaryPeriod, before.SalaryPeriod != after.SalaryPeriod)
+	addIfChanged("salaryUndisclosed", before.SalaryUndisclosed, after.SalaryUndisclosed, before.SalaryUndisclosed != after.SalaryUndisclosed)
+	addIfChanged("open", before.IsOpen, after.IsOpen, before.IsOpen != after.IsOpen)
+	addIfChanged("openings", before.Openings, after.Openings, !sameUint(before.Openings, after.Openings))
+	addIfChanged("notifyPendingWhenFilled", before.NotifyPendingWhenFilled, after.NotifyPendingWhenFilled, before.NotifyPendingWhenFilled != after.NotifyPendingWhenFilled)
+	addIfChanged("notifyOnApplication", before.NotifyOnApplication, after.NotifyOnApplication, before.NotifyOnApplication != after.NotifyOnApplication)
+	addIfChanged("applicationDeadline", before.ApplicationDeadline, after.ApplicationDeadline, !sameTime(before.ApplicationDeadline, after.ApplicationDeadline))
+	addIfChanged("publishAt", before.PublishAt, after.PublishAt, !sameTime(before.PublishAt, after.PublishAt))
+	addIfChanged("unpublishAt", before.UnpublishAt, after.UnpublishAt, !sameTime(before.UnpublishAt, after.UnpublishAt))
+	addIfChanged("pipeline", before.Pipeline, after.Pipeline, !slices.Equal(before.Pipeline, after.Pipeline))
+	return changes
+}
+
+// HasMaterialChange reports whether any of the changes touches a material field.
+func HasMaterialChange(changes []JobFieldChange) bool {
+	for _, change := range changes {
+		if MaterialJobFields[change.Field] {
+			return true
+		}
+	}
+	return false
+}
+
+func sameTime(a *time.Time, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func sameUint(a *uint, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
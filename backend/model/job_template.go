@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// JobTemplate is a company's reusable job post that can be instantiated into new jobs.
+type JobTemplate struct {
+	ID                  uint                        `gorm:"primaryKey" json:"id"`
+	CreatedAt           time.Time                   `json:"createdAt"`
+	UpdatedAt           time.Time                   `json:"updatedAt"`
+	CompanyID           string                      `gorm:"type:uuid;uniqueIndex:idx_job_templates_company_title" json:"companyId"`
+	Company             Company                     `gorm:"foreignKey:CompanyID;constraint:OnDelete:CASCADE;" json:"-"`
+	Title               string                      `gorm:"uniqueIndex:idx_job_templates_company_title" json:"title"`
+	Name                string                      `json:"name"`
+	Position            string                      `json:"position"`
+	Duration            string                      `json:"duration"`
+	Description         string                      `json:"description"`
+	Location            string                      `json:"location"`
+	WorkMode            WorkMode                    `gorm:"default:onsite" json:"workMode"`
+	Province            string                      `json:"province"`
+	District            string                      `json:"district"`
+	JobType             JobType                     `json:"jobType"`
+	Experience          ExperienceType              `json:"experience"`
+	MinSalary           uint                        `json:"minSalary"`
+	MaxSalary           uint                        `json:"maxSalary"`
+	SalaryCurrency      string                      `gorm:"size:3;default:THB" json:"salaryCurrency"`
+	SalaryPeriod        SalaryPeriod                `gorm:"default:month" json:"salaryPeriod"`
+	SalaryUndisclosed   bool                        `json:"salaryUndisclosed"`
+	NotifyOnApplication bool                        `json:"notifyOnApplication"`
+	RequiredSkills      datatypes.JSONSlice[string] `json:"requiredSkills"`
+	NiceToHaveSkills    datatypes.JSONSlice[string] `json:"niceToHaveSkills"`
+}
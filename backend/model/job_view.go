@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// JobView counts how often one viewer opened a job's detail page on one day (UTC).
+// Rows are written in batches from the counters buffered in Redis.
+type JobView struct {
+	JobID    uint      `gorm:"primaryKey" json:"jobId"`
+	Job      Job       `gorm:"foreignKey:JobID;constraint:OnDelete:CASCADE;" json:"-"`
+	ViewerID string    `gorm:"primaryKey;type:uuid" json:"viewerId"`
+	Date     time.Time `gorm:"primaryKey;type:date" json:"date"`
+	Views    int64     `json:"views"`
+}
+
+// JobViewFlush records a Redis batch of view counters that was written to job_views,
+// so a batch whose deletion from Redis failed is not counted twice on the next run.
+type JobViewFlush struct {
+	Key       string    `gorm:"primaryKey"`
+	CreatedAt time.Time `gorm:"index"`
+}
@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+type ReportTargetType string
+
+const (
+	ReportTargetJob     ReportTargetType = "job"
+	ReportTargetCompany ReportTargetType = "company"
+)
+
+type ReportReason string
+
+const (
+	ReportReasonScam           ReportReason = "scam"
+	ReportReasonMisleading     ReportReason = "misleading"
+	ReportReasonDiscriminatory ReportReason = "discriminatory"
+	ReportReasonInappropriate  ReportReason = "inappropriate"
+	ReportReasonSpam           ReportReason = "spam"
+	ReportReasonOther          ReportReason = "other"
+)
+
+type ReportStatus string
+
+const (
+	ReportStatusOpen      ReportStatus = "open"
+	ReportStatusActioned  ReportStatus = "actioned"
+	ReportStatusDismissed ReportStatus = "dismissed"
+)
+
+// Report is a user's complaint about a job or a company, waiting in the admin moderation queue.
+// CompanyID is always set, for job reports it is the company that posted the job.
+type Report struct {
+	ID           uint             `gorm:"primaryKey" json:"id"`
+	CreatedAt    time.Time        `json:"createdAt"`
+	UpdatedAt    time.Time        `json:"updatedAt"`
+	ReporterID   string           `gorm:"type:uuid;index" json:"reporterId"`
+	Reporter     User             `gorm:"foreignKey:ReporterID;constraint:OnDelete:CASCADE;" json:"-"`
+	TargetType   ReportTargetType `json:"targetType"`
+	JobID        *uint            `gorm:"index" json:"jobId"`
+	Job          *Job             `gorm:"foreignKey:JobID;constraint:OnDelete:CASCADE;" json:"-"`
+	CompanyID    string           `gorm:"type:uuid;index" json:"companyId"`
+	Company      Company          `gorm:"foreignKey:CompanyID;references:UserID;constraint:OnDelete:CASCADE;" json:"-"`
+	Reason       ReportReason     `json:"reason"`
+	Details      string           `json:"details"`
+	Status       ReportStatus     `gorm:"default:open;index" json:"status"`
+	ResolvedByID *string          `gorm:"type:uuid" json:"resolvedById"`
+	ResolvedAt   *time.Time       `json:"resolvedAt"`
+	Resolution   string           `json:"resolution"`
+}
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// SavedJob is a student's bookmark on a job post.
+type SavedJob struct {
+	UserID    string    `gorm:"primaryKey;type:uuid" json:"userId"`
+	JobID     uint      `gorm:"primaryKey;index" json:"jobId"`
+	CreatedAt time.Time `json:"createdAt"`
+	Student   Student   `gorm:"foreignKey:UserID;references:UserID;constraint:OnDelete:CASCADE;" json:"-"`
+	Job       Job       `gorm:"foreignKey:JobID;constraint:OnDelete:CASCADE;" json:"-"`
+}
@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+// Skill is an entry in the admin-managed skill vocabulary shared by jobs and students.
+type Skill struct {
+	ID        uint         `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time    `json:"createdAt"`
+	UpdatedAt time.Time    `json:"updatedAt"`
+	Name      string       `gorm:"uniqueIndex" json:"name"`
+	Slug      string       `gorm:"uniqueIndex" json:"-"`
+	Aliases   []SkillAlias `gorm:"foreignKey:SkillID;constraint:OnDelete:CASCADE;" json:"aliases"`
+}
+
+// SkillAlias is an alternative spelling that resolves to a skill, e.g. "golang" for "Go".
+type SkillAlias struct {
+	ID      uint   `gorm:"primaryKey" json:"-"`
+	SkillID uint   `gorm:"index" json:"-"`
+	Alias   string `json:"alias"`
+	Slug    string `gorm:"uniqueIndex" json:"-"`
+}
+
+// JobSkill links a job to a skill it asks for.
+type JobSkill struct {
+	JobID    uint  `gorm:"primaryKey" json:"jobId"`
+	SkillID  uint  `gorm:"primaryKey;index" json:"skillId"`
+	Required bool  `json:"required"`
+	Job      Job   `gorm:"foreignKey:JobID;constraint:OnDelete:CASCADE;" json:"-"`
+	Skill    Skill `gorm:"foreignKey:SkillID;constraint:OnDelete:CASCADE;" json:"-"`
+}
+
+// StudentSkill links a student to a skill listed on their profile.
+type StudentSkill struct {
+	UserID  string  `gorm:"primaryKey;type:uuid" json:"userId"`
+	SkillID uint    `gorm:"primaryKey;index" json:"skillId"`
+	Student Student `gorm:"foreignKey:UserID;references:UserID;constraint:OnDelete:CASCADE;" json:"-"`
+	Skill   Skill   `gorm:"foreignKey:SkillID;constraint:OnDelete:CASCADE;" json:"-"`
+}
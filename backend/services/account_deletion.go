@@ -74,9 +74,22 @@ func AnonymizeExpiredAccounts(db *gorm.DB, gracePeriodDay int) error {
 func DisableCompanyJobPosts(db *gorm.DB, companyUserID string) error {
 	slog.Info("Disabling job posts for company", "company_id", companyUserID)
 
+	// Find the open jobs of this company, so that only their bookmarks are cleaned up afterwards
+	var jobIDs []uint
+	if err := db.Model(&model.Job{}).
+		Where("company_id = ? AND is_open = ?", companyUserID, true).
+		Pluck("id", &jobIDs).Error; err != nil {
+		slog.Error("Failed to find job posts for company", "user_id", companyUserID, "error", err)
+		return fmt.Errorf("failed to find job posts: %w", err)
+	}
+	if len(jobIDs) == 0 {
+		slog.Info("No open job posts to disable for company", "user_id", companyUserID)
+		return nil
+	}
+
 	// Update all jobs for this company to set is_open = false
 	result := db.Model(&model.Job{}).
-		Where("company_id = ? AND is_open = ?", companyUserID, true).
+		Where("id IN ? AND is_open = ?", jobIDs, true).
 		Update("is_open", false)
 
 	if result.Error != nil {
@@ -85,6 +98,11 @@ func DisableCompanyJobPosts(db *gorm.DB, companyUserID string) error {
 	}
 
 	slog.Info("Disabled job posts for company", "count", result.RowsAffected, "user_id", companyUserID)
+
+	// Closed jobs can no longer be bookmarked
+	if err := RemoveSavedJobsForClosedJobs(db, jobIDs...); err != nil {
+		slog.Warn("Failed to remove saved jobs for company", "user_id", companyUserID, "error", err)
+	}
 	return nil
 }
 
@@ -127,6 +145,10 @@ func AnonymizeJobApplicationsForStudent(tx *gorm.DB, studentUserID string) error
 		updates := map[string]any{
 			"contact_phone": "",
 			"contact_email": fmt.Sprintf("%s@anonymized.local", anonymousID),
+			// Screening answers may hold personal details
+			"answers": nil,
+			// Recruiter notes are free text about the student, the rating is kept for analytics
+			"recruiter_notes": "",
 		}
 
 		if err := tx.Unscoped().Model(&model.JobApplication{}).
@@ -136,6 +158,20 @@ func AnonymizeJobApplicationsForStudent(tx *gorm.DB, studentUserID string) error
 		}
 	}
 
+	// The timeline is kept for analytics, but reasons are free text about the student
+	if err := tx.Model(&model.ApplicationStatusChange{}).
+		Where("user_id = ?", studentUserID).
+		Update("reason", "").Error; err != nil {
+		slog.Error("Failed to anonymize application history", "user_id", studentUserID, "error", err)
+		return fmt.Errorf("failed to anonymize application history: %w", err)
+	}
+	if err := tx.Model(&model.Interview{}).
+		Where("user_id = ?", studentUserID).
+		Updates(map[string]any{"note": "", "cancel_reason": ""}).Error; err != nil {
+		slog.Error("Failed to anonymize interviews", "user_id", studentUserID, "error", err)
+		return fmt.Errorf("failed to anonymize interviews: %w", err)
+	}
+
 	slog.Info("Successfully anonymized job applications for student", "count", len(applications), "user_id", studentUserID)
 	return nil
 }
@@ -182,6 +218,17 @@ func AnonymizeAccount(db *gorm.DB, userID string) error {
 				return fmt.Errorf("failed to anonymize job applications: %w", err)
 			}
 			slog.Info("Anonymized job applications for student", "user_id", userID)
+
+			// Bookmarks reveal a student's interests, so they are removed rather than retained
+			if err := DeleteSavedJobsForStudent(tx, userID); err != nil {
+				return fmt.Errorf("failed to delete saved jobs: %w", err)
+			}
+			slog.Info("Deleted saved jobs for student", "user_id", userID)
+
+			if err := DeleteJobAlertsForStudent(tx, userID); err != nil {
+				return fmt.Errorf("failed to delete job alerts: %w", err)
+			}
+			slog.Info("Deleted job alerts for student", "user_id", userID)
 		}
 
 		// Anonymize Company record if exists
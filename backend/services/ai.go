@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 	"text/template"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -28,9 +29,13 @@ func (current *AIService) AutoApproveJob(job *model.Job) {
 		return
 	}
 	tx := current.DB.Begin()
+	updates := map[string]any{"approval_status": approvalStatus}
+	if approvalStatus == model.JobApprovalAccepted {
+		updates["approved_at"] = time.Now()
+	}
 	if err := current.DB.Model(&model.Job{
 		ID: job.ID,
-	}).Update("approval_status", approvalStatus).Error; err != nil {
+	}).Updates(updates).Error; err != nil {
 		tx.Rollback()
 		return
 	}
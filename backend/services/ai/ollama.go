@@ -46,33 +46,45 @@ type AIOptions struct {
 
 func (current *OllamaApprovalAI) CheckJob(job *model.Job) (model.JobApprovalStatus, []string) {
 	type AIInput struct {
-		Name        string               `json:"name,omitempty"`
-		Position    string               `json:"position,omitempty"`
-		Duration    string               `json:"duration,omitempty"`
-		Description string               `json:"description,omitempty"`
-		Location    string               `json:"location,omitempty"`
-		JobType     model.JobType        `json:"jobType,omitempty"`
-		Experience  model.ExperienceType `json:"experienceType,omitempty"`
-		MinSalary   uint                 `json:"minSalary,omitempty"`
-		MaxSalary   uint                 `json:"maxSalary,omitempty"`
+		Name              string               `json:"name,omitempty"`
+		Position          string               `json:"position,omitempty"`
+		Duration          string               `json:"duration,omitempty"`
+		Description       string               `json:"description,omitempty"`
+		Location          string               `json:"location,omitempty"`
+		WorkMode          model.WorkMode       `json:"workMode,omitempty"`
+		Province          string               `json:"province,omitempty"`
+		District          string               `json:"district,omitempty"`
+		JobType           model.JobType        `json:"jobType,omitempty"`
+		Experience        model.ExperienceType `json:"experienceType,omitempty"`
+		MinSalary         uint                 `json:"minSalary,omitempty"`
+		MaxSalary         uint                 `json:"maxSalary,omitempty"`
+		SalaryCurrency    string               `json:"salaryCurrency,omitempty"`
+		SalaryPeriod      model.SalaryPeriod   `json:"salaryPeriod,omitempty"`
+		SalaryUndisclosed bool                 `json:"salaryUndisclosed,omitempty"`
 	}
 	jobData, err := json.Marshal(AIInput{
-		Name:        job.Name,
-		Position:    job.Position,
-		Duration:    job.Duration,
-		Description: job.Description,
-		Location:    job.Location,
-		JobType:     job.JobType,
-		Experience:  job.Experience,
-		MinSalary:   job.MinSalary,
-		MaxSalary:   job.MaxSalary,
+		Name:              job.Name,
+		Position:          job.Position,
+		Duration:          job.Duration,
+		Description:       job.Description,
+		Location:          job.Location,
+		WorkMode:          job.WorkMode,
+		Province:          job.Province,
+		District:          job.District,
+		JobType:           job.JobType,
+		Experience:        job.Experience,
+		MinSalary:         job.MinSalary,
+		MaxSalary:         job.MaxSalary,
+		SalaryCurrency:    job.SalaryCurrency,
+		SalaryPeriod:      job.SalaryPeriod,
+		SalaryUndisclosed: job.SalaryUndisclosed,
 	})
 	if err != nil {
 		return model.JobApprovalPending, nil
 	}
 	optsData, err := json.Marshal(AIOptions{
 		Model:  current.model,
-		System: "Please evaluate whether job application is valid or not. Salary unit is Baht per month. Ignore missing company name, and contact information. Please respond in JSON",
+		System: "Please evaluate whether job application is valid or not. Salaries are in salaryCurrency (ISO 4217) per salaryPeriod; when salaryUndisclosed is true the salary is negotiable and no figures are given, which is acceptable. Ignore missing company name, and contact information. Please respond in JSON",
 		Prompt: string(jobData),
 		Format: json.RawMessage(`{"type":"object","properties":{"reasons":{"type":"array"},"valid":{"type":"boolean"}}}`),
 		Stream: false,
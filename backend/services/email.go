@@ -77,7 +77,8 @@ func sanitizeEmailContent(content string) string {
 	return sanitized
 }
 
-func (cur *EmailService) SendTo(target string, subject string, content string) error {
+// SendTo sends an HTML email with optional attachments and records it in the mail log.
+func (cur *EmailService) SendTo(target string, subject string, content string, attachments ...email.Attachment) error {
 	// Escape header values
 	escapedTarget := escapeHeaderValue(target)
 	escapedSubject := escapeHeaderValue(subject)
@@ -94,13 +95,20 @@ func (cur *EmailService) SendTo(target string, subject string, content string) e
 		Status:     model.MailLogStatusTemporaryError, // If it fail for no reason, log as temporary error to retry later.
 		RetryCount: 0,
 	}
+	for _, attachment := range attachments {
+		mailLog.Attachments = append(mailLog.Attachments, model.MailAttachment{
+			Filename:    escapeHeaderValue(attachment.Filename),
+			ContentType: escapeHeaderValue(attachment.ContentType),
+			Content:     attachment.Content,
+		})
+	}
 
 	// Create context with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), cur.timeout)
 	defer cancel()
 
 	// Attempt to send email with timeout
-	err := cur.provider.SendTo(ctx, escapedTarget, escapedSubject, sanitizedContent)
+	err := cur.provider.SendTo(ctx, escapedTarget, escapedSubject, sanitizedContent, mailAttachments(mailLog)...)
 
 	// Update log status based on result
 	if err != nil {
@@ -124,6 +132,19 @@ func (cur *EmailService) SendTo(target string, subject string, content string) e
 	return err
 }
 
+// mailAttachments returns the attachments of a logged email in the form sent to the provider
+func mailAttachments(mailLog model.MailLog) []email.Attachment {
+	attachments := make([]email.Attachment, 0, len(mailLog.Attachments))
+	for _, attachment := range mailLog.Attachments {
+		attachments = append(attachments, email.Attachment{
+			Filename:    attachment.Filename,
+			ContentType: attachment.ContentType,
+			Content:     attachment.Content,
+		})
+	}
+	return attachments
+}
+
 // isTemporaryError determines if an email error is temporary (can be retried)
 func isTemporaryError(errorMsg string) bool {
 	// Common temporary error patterns
@@ -226,7 +247,7 @@ func (cur *EmailService) RetryFailedEmails() error {
 		ctx, cancel := context.WithTimeout(context.Background(), cur.timeout)
 
 		// Attempt to resend
-		err := cur.provider.SendTo(ctx, mailLog.To, mailLog.Subject, mailLog.Body)
+		err := cur.provider.SendTo(ctx, mailLog.To, mailLog.Subject, mailLog.Body, mailAttachments(mailLog)...)
 		cancel()
 
 		// Update mail log based on result
@@ -9,6 +9,6 @@ func NewDummyEmailProvider() *DummyEmailProvider {
 	return &DummyEmailProvider{}
 }
 
-func (cur *DummyEmailProvider) SendTo(ctx context.Context, target string, subject string, content string) error {
+func (cur *DummyEmailProvider) SendTo(ctx context.Context, target string, subject string, content string, attachments ...Attachment) error {
 	return nil
 }
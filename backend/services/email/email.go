@@ -2,6 +2,13 @@ package email
 
 import "context"
 
+// Attachment is a file sent along with an email.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
 type EmailProvider interface {
-	SendTo(ctx context.Context, target string, subject string, content string) error
+	SendTo(ctx context.Context, target string, subject string, content string, attachments ...Attachment) error
 }
@@ -57,9 +57,13 @@ func NewGmailEmailProvider() (*GmailEmailProvider, error) {
 	}, nil
 }
 
-func (cur *GmailEmailProvider) SendTo(ctx context.Context, target string, subject string, content string) error {
+func (cur *GmailEmailProvider) SendTo(ctx context.Context, target string, subject string, content string, attachments ...Attachment) error {
+	raw, err := buildMessage(fmt.Sprintf("To: %s\r\nSubject: %s\r\n", target, subject), content, attachments)
+	if err != nil {
+		return err
+	}
 	message := gmail.Message{
-		Raw: base64.URLEncoding.EncodeToString([]byte(fmt.Sprintf("To: %s\r\nSubject: %s\r\nMIME-version: 1.0;\r\nContent-Type: text/html; charset=\"UTF-8\";\r\n\r\n%s", target, subject, content))),
+		Raw: base64.URLEncoding.EncodeToString([]byte(raw)),
 	}
 
 	// Use a channel to handle the email sending with timeout
@@ -0,0 +1,61 @@
+package email
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+)
+
+// base64LineLength is the longest line of a base64 encoded part, as required by RFC 2045
+const base64LineLength = 76
+
+// buildMessage assembles a raw email from its leading headers and HTML content.
+// Emails without attachments are sent as a single HTML part, otherwise the content
+// and the attachments are sent as the parts of a multipart/mixed message.
+func buildMessage(headers string, content string, attachments []Attachment) (string, error) {
+	if len(attachments) == 0 {
+		return fmt.Sprintf("%sMIME-version: 1.0;\r\nContent-Type: text/html; charset=\"UTF-8\";\r\n\r\n%s", headers, content), nil
+	}
+
+	body := bytes.Buffer{}
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {`text/html; charset="UTF-8"`},
+	})
+	if err != nil {
+		return "", err
+	}
+	if _, err := part.Write([]byte(content)); err != nil {
+		return "", err
+	}
+	for _, attachment := range attachments {
+		mediaType, params, err := mime.ParseMediaType(attachment.ContentType)
+		if err != nil {
+			return "", fmt.Errorf("invalid content type of attachment %s: %w", attachment.Filename, err)
+		}
+		params["name"] = attachment.Filename
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(mediaType, params)},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return "", err
+		}
+		encoded := base64.StdEncoding.EncodeToString(attachment.Content)
+		for len(encoded) > 0 {
+			n := min(base64LineLength, len(encoded))
+			if _, err := part.Write([]byte(encoded[:n] + "\r\n")); err != nil {
+				return "", err
+			}
+			encoded = encoded[n:]
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%sMIME-version: 1.0;\r\nContent-Type: multipart/mixed; boundary=\"%s\"\r\n\r\n%s", headers, writer.Boundary(), body.String()), nil
+}
@@ -38,8 +38,11 @@ func NewSMTPEmailProvider() (*SMTPEmailProvider, error) {
 	}, nil
 }
 
-func (cur *SMTPEmailProvider) SendTo(ctx context.Context, target string, subject string, content string) error {
-	msg := fmt.Sprintf("Subject: %s\r\nMIME-version: 1.0;\r\nContent-Type: text/html; charset=\"UTF-8\";\r\n\r\n%s", subject, content)
+func (cur *SMTPEmailProvider) SendTo(ctx context.Context, target string, subject string, content string, attachments ...Attachment) error {
+	msg, err := buildMessage(fmt.Sprintf("Subject: %s\r\n", subject), content, attachments)
+	if err != nil {
+		return err
+	}
 
 	// Use a channel to handle the email sending with timeout
 	errChan := make(chan error, 1)
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"ku-work/backend/helper"
+	"ku-work/backend/model"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxJobAlertDigestJobs caps how many jobs are listed in a single digest email.
+const maxJobAlertDigestJobs = 20
+
+// JobAlertService sends students digests of new jobs matching their saved searches.
+type JobAlertService struct {
+	DB                          *gorm.DB
+	emailService                *EmailService
+	jobAlertDigestEmailTemplate *template.Template
+	publicAPIURL                string
+}
+
+func NewJobAlertService(DB *gorm.DB, emailService *EmailService) (*JobAlertService, error) {
+	jobAlertDigestEmailTemplate, err := template.New("job_alert_digest.tmpl").ParseFiles("email_templates/job_alert_digest.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	publicAPIURL, hasURL := os.LookupEnv("PUBLIC_API_URL")
+	if !hasURL || publicAPIURL == "" {
+		publicAPIURL = "http://localhost:8000"
+	}
+	return &JobAlertService{
+		DB:                          DB,
+		emailService:                emailService,
+		jobAlertDigestEmailTemplate: jobAlertDigestEmailTemplate,
+		publicAPIURL:                strings.TrimRight(publicAPIURL, "/"),
+	}, nil
+}
+
+// GenerateJobAlertUnsubscribeToken returns a random URL-safe token identifying a single alert.
+func GenerateJobAlertUnsubscribeToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// DeleteJobAlertsForStudent removes every saved search a student has made
+func DeleteJobAlertsForStudent(tx *gorm.DB, studentUserID string) error {
+	if err := tx.Where("user_id = ?", studentUserID).Delete(&model.JobAlert{}).Error; err != nil {
+		return fmt.Errorf("failed to delete job alerts: %w", err)
+	}
+	return nil
+}
+
+// SendJobAlertDigests emails every due alert the jobs that became visible to students since its previous digest.
+// Alerts without new matches are skipped silently but still move on to the next period.
+// This function is designed to be called by the scheduler.
+func (current *JobAlertService) SendJobAlertDigests() error {
+	now := time.Now()
+
+	var alerts []model.JobAlert
+	if err := current.DB.
+		Joins("INNER JOIN users ON users.id = job_alerts.user_id AND users.deleted_at IS NULL").
+		Where("(job_alerts.frequency = ? AND job_alerts.last_sent_at <= ?) OR (job_alerts.frequency = ? AND job_alerts.last_sent_at <= ?)",
+			model.JobAlertDaily, now.Add(-24*time.Hour),
+			model.JobAlertWeekly, now.Add(-7*24*time.Hour)).
+		Find(&alerts).Error; err != nil {
+		return fmt.Errorf("failed to query due job alerts: %w", err)
+	}
+
+	sentCount := 0
+	for _, alert := range alerts {
+		// Claim the period first so concurrent runs never send the same digest twice
+		result := current.DB.Model(&model.JobAlert{}).
+			Where("id = ? AND last_sent_at = ?", alert.ID, alert.LastSentAt).
+			Update("last_sent_at", now)
+		if result.Error != nil {
+			slog.Error("Failed to update job alert", "alert_id", alert.ID, "error", result.Error)
+			continue
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		sent, err := current.sendDigest(&alert, alert.LastSentAt, now)
+		if err != nil {
+			slog.Warn("Failed to send job alert digest", "alert_id", alert.ID, "error", err)
+			continue
+		}
+		if sent {
+			sentCount++
+		}
+	}
+
+	if sentCount > 0 {
+		slog.Info("Sent job alert digests", "count", sentCount)
+	}
+	return nil
+}
+
+func (current *JobAlertService) sendDigest(alert *model.JobAlert, since time.Time, until time.Time) (bool, error) {
+	type DigestJob struct {
+		ID                uint
+		Name              string
+		Position          string
+		CompanyName       string
+		Location          string
+		MinSalary         uint
+		MaxSalary         uint
+		SalaryCurrency    string
+		SalaryPeriod      string
+		SalaryUndisclosed bool
+	}
+	type Context struct {
+		FirstName      string
+		Alert          *model.JobAlert
+		Jobs           []DigestJob
+		TotalCount     int64
+		UnsubscribeURL string
+	}
+	var context Context
+	context.Alert = alert
+
+	// A job is new to students once it is both approved and inside its publishing window
+	query := current.DB.Model(&model.Job{}).
+		Joins("INNER JOIN users ON users.id = jobs.company_id").
+		Where("jobs.approval_status = ? AND jobs.is_open = ?", model.JobApprovalAccepted, true).
+		Where("(jobs.application_deadline IS NULL OR jobs.application_deadline > ?)", until).
+		Scopes(helper.PublishedJobsScope(until)).
+		Where("GREATEST(jobs.approved_at, jobs.publish_at) > ? AND GREATEST(jobs.approved_at, jobs.publish_at) <= ?", since, until).
+		Scopes(helper.JobFilterScope(helper.JobFilter{
+			Keyword:    alert.Keyword,
+			Location:   alert.Location,
+			JobType:    alert.JobType,
+			Experience: alert.Experience,
+			MinSalary:  alert.MinSalary,
+			MaxSalary:  alert.MaxSalary,
+		}))
+
+	if err := query.Count(&context.TotalCount).Error; err != nil {
+		return false, err
+	}
+	if context.TotalCount == 0 {
+		return false, nil
+	}
+	if err := query.
+		Select("jobs.id, jobs.name, jobs.position, users.username AS company_name, jobs.location, jobs.min_salary, jobs.max_salary, jobs.salary_currency, jobs.salary_period, jobs.salary_undisclosed").
+		Order("jobs.approved_at DESC").
+		Limit(maxJobAlertDigestJobs).
+		Scan(&context.Jobs).Error; err != nil {
+		return false, err
+	}
+
+	var oauthDetails model.GoogleOAuthDetails
+	if err := current.DB.Select("first_name", "email").Take(&oauthDetails, "user_id = ?", alert.UserID).Error; err != nil {
+		return false, err
+	}
+	context.FirstName = oauthDetails.FirstName
+	context.UnsubscribeURL = fmt.Sprintf("%s/job-alerts/unsubscribe/%s", current.publicAPIURL, alert.UnsubscribeToken)
+
+	var tpl bytes.Buffer
+	if err := current.jobAlertDigestEmailTemplate.Execute(&tpl, context); err != nil {
+		return false, err
+	}
+	if err := current.emailService.SendTo(
+		oauthDetails.Email,
+		fmt.Sprintf("[KU-Work] %d new jobs for \"%s\"", context.TotalCount, alert.Name),
+		tpl.String(),
+	); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"ku-work/backend/model"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// Views are counted in one hash per day, keyed by "{jobID}:{viewerID}"
+	jobViewPendingKeyPrefix = "job_views:pending:"
+	// A day's hash is renamed under this prefix while it is written to Postgres, so new views start a fresh hash
+	jobViewFlushingKeyPrefix = "job_views:flushing:"
+	// Counters that could not be flushed for this long are dropped rather than kept forever
+	jobViewKeyTTL = 7 * 24 * time.Hour
+	// jobViewDateLayout is the layout of the day part of the Redis keys
+	jobViewDateLayout = "2006-01-02"
+	// Viewed jobs are looked up this many at a time to stay under Postgres' bind parameter limit
+	jobViewLookupBatchSize = 1000
+)
+
+// JobAnalyticsService buffers job detail views in Redis and periodically writes them to Postgres.
+type JobAnalyticsService struct {
+	DB    *gorm.DB
+	redis *redis.Client
+}
+
+func NewJobAnalyticsService(DB *gorm.DB, redisClient *redis.Client) *JobAnalyticsService {
+	return &JobAnalyticsService{
+		DB:    DB,
+		redis: redisClient,
+	}
+}
+
+// RecordJobView counts one view of a job by a viewer for today (UTC).
+func (s *JobAnalyticsService) RecordJobView(ctx context.Context, jobID uint, viewerID string) error {
+	if s.redis == nil {
+		return fmt.Errorf("redis client is not initialized")
+	}
+
+	key := jobViewPendingKeyPrefix + time.Now().UTC().Format(jobViewDateLayout)
+	pipe := s.redis.TxPipeline()
+	pipe.HIncrBy(ctx, key, fmt.Sprintf("%d:%s", jobID, viewerID), 1)
+	pipe.Expire(ctx, key, jobViewKeyTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to record job view in Redis: %w", err)
+	}
+	return nil
+}
+
+// FlushJobViews moves the view counters buffered in Redis into the job_views table.
+// A batch that fails to write stays in Redis and is retried on the next run.
+func (s *JobAnalyticsService) FlushJobViews() error {
+	if s.redis == nil {
+		return fmt.Errorf("redis client is not initialized")
+	}
+	ctx := context.Background()
+
+	pendingKeys, err := s.scanKeys(ctx, jobViewPendingKeyPrefix+"*")
+	if err != nil {
+		return err
+	}
+	for _, key := range pendingKeys {
+		day := strings.TrimPrefix(key, jobViewPendingKeyPrefix)
+		flushingKey := fmt.Sprintf("%s%s:%d", jobViewFlushingKeyPrefix, day, time.Now().UnixNano())
+		if err := s.redis.Rename(ctx, key, flushingKey).Err(); err != nil && !strings.Contains(err.Error(), "no such key") {
+			return fmt.Errorf("failed to claim job views for %s: %w", day, err)
+		}
+	}
+
+	// Also picks up batches left behind by a failed run
+	flushingKeys, err := s.scanKeys(ctx, jobViewFlushingKeyPrefix+"*")
+	if err != nil {
+		return err
+	}
+	flushed := 0
+	for _, key := range flushingKeys {
+		count, err := s.flushKey(ctx, key)
+		if err != nil {
+			slog.Error("Failed to flush job views", "key", key, "error", err)
+			continue
+		}
+		flushed += count
+	}
+	if flushed > 0 {
+		slog.Info("Flushed job views", "rows", flushed)
+	}
+
+	// Batches older than this have expired from Redis, so their records are no longer needed
+	if err := s.DB.Where("created_at < ?", time.Now().Add(-jobViewKeyTTL)).Delete(&model.JobViewFlush{}).Error; err != nil {
+		slog.Warn("Failed to prune job view flush records", "error", err)
+	}
+	return nil
+}
+
+func (s *JobAnalyticsService) scanKeys(ctx context.Context, pattern string) ([]string, error) {
+	keys := []string{}
+	var cursor uint64
+	for {
+		batch, nextCursor, err := s.redis.Scan(ctx, cursor, pattern, 100).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job view keys: %w", err)
+		}
+		keys = append(keys, batch...)
+		cursor = nextCursor
+		if cursor == 0 {
+			return keys, nil
+		}
+	}
+}
+
+// flushKey writes one claimed day of counters to Postgres and deletes it from Redis.
+// The batch is recorded in the same transaction as its rows, so it is written at most once.
+func (s *JobAnalyticsService) flushKey(ctx context.Context, key string) (int, error) {
+	parts := strings.Split(strings.TrimPrefix(key, jobViewFlushingKeyPrefix), ":")
+	date, err := time.Parse(jobViewDateLayout, parts[0])
+	if err != nil {
+		// Not a key this service wrote, leave it alone
+		return 0, fmt.Errorf("unexpected job view key: %w", err)
+	}
+
+	var done int64
+	if err := s.DB.Model(&model.JobViewFlush{}).Where("key = ?", key).Count(&done).Error; err != nil {
+		return 0, fmt.Errorf("failed to check flushed job views: %w", err)
+	}
+	if done > 0 {
+		// Written by an earlier run that failed to delete it
+		if err := s.redis.Del(ctx, key).Err(); err != nil {
+			return 0, fmt.Errorf("failed to delete flushed job views: %w", err)
+		}
+		return 0, nil
+	}
+
+	counters, err := s.redis.HGetAll(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read job views: %w", err)
+	}
+
+	views := make([]model.JobView, 0, len(counters))
+	jobIDSet := map[uint]bool{}
+	for field, value := range counters {
+		jobIDStr, viewerID, found := strings.Cut(field, ":")
+		jobID, err := strconv.ParseUint(jobIDStr, 10, 32)
+		count, countErr := strconv.ParseInt(value, 10, 64)
+		if !found || err != nil || countErr != nil {
+			continue
+		}
+		views = append(views, model.JobView{JobID: uint(jobID), ViewerID: viewerID, Date: date, Views: count})
+		jobIDSet[uint(jobID)] = true
+	}
+	jobIDs := make([]uint, 0, len(jobIDSet))
+	for jobID := range jobIDSet {
+		jobIDs = append(jobIDs, jobID)
+	}
+
+	// Views of jobs deleted since would violate the foreign key and fail the whole batch
+	existingSet := make(map[uint]bool, len(jobIDs))
+	for start := 0; start < len(jobIDs); start += jobViewLookupBatchSize {
+		end := min(start+jobViewLookupBatchSize, len(jobIDs))
+		existing := []uint{}
+		if err := s.DB.Model(&model.Job{}).Where("id IN ?", jobIDs[start:end]).Pluck("id", &existing).Error; err != nil {
+			return 0, fmt.Errorf("failed to check viewed jobs: %w", err)
+		}
+		for _, id := range existing {
+			existingSet[id] = true
+		}
+	}
+	rows := make([]model.JobView, 0, len(views))
+	for _, view := range views {
+		if existingSet[view.JobID] {
+			rows = append(rows, view)
+		}
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if len(rows) > 0 {
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "job_id"}, {Name: "viewer_id"}, {Name: "date"}},
+				DoUpdates: clause.Assignments(map[string]any{"views": gorm.Expr("job_views.views + excluded.views")}),
+			}).CreateInBatches(&rows, 500).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(&model.JobViewFlush{Key: key}).Error
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to save job views: %w", err)
+	}
+
+	if err := s.redis.Del(ctx, key).Err(); err != nil {
+		return 0, fmt.Errorf("failed to delete flushed job views: %w", err)
+	}
+	return len(rows), nil
+}
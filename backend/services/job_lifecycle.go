@@ -0,0 +1,214 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"ku-work/backend/model"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// JobLifecycleService runs the time-based transitions of job posts, such as closing them after their deadline.
+// The emails to companies are skipped when there is no email service.
+type JobLifecycleService struct {
+	DB                             *gorm.DB
+	emailService                   *EmailService
+	jobDeadlineClosedEmailTemplate *template.Template
+	jobPublishedEmailTemplate      *template.Template
+}
+
+func NewJobLifecycleService(DB *gorm.DB, emailService *EmailService) (*JobLifecycleService, error) {
+	jobDeadlineClosedEmailTemplate, err := template.New("job_deadline_closed.tmpl").ParseFiles("email_templates/job_deadline_closed.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	jobPublishedEmailTemplate, err := template.New("job_published.tmpl").ParseFiles("email_templates/job_published.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	return &JobLifecycleService{
+		DB:                             DB,
+		emailService:                   emailService,
+		jobDeadlineClosedEmailTemplate: jobDeadlineClosedEmailTemplate,
+		jobPublishedEmailTemplate:      jobPublishedEmailTemplate,
+	}, nil
+}
+
+// CloseExpiredJobs closes open jobs whose application deadline has passed
+// and emails each company a summary of the applicants still waiting for a decision.
+// This function is designed to be called by the scheduler.
+func (current *JobLifecycleService) CloseExpiredJobs() error {
+	now := time.Now()
+
+	var jobs []model.Job
+	if err := current.DB.
+		Where("is_open = ? AND application_deadline IS NOT NULL AND application_deadline <= ?", true, now).
+		Find(&jobs).Error; err != nil {
+		return fmt.Errorf("failed to query expired jobs: %w", err)
+	}
+
+	closedCount := 0
+	for _, job := range jobs {
+		// Only the run that actually flips the flag sends the summary
+		result := current.DB.Model(&model.Job{}).
+			Where("id = ? AND is_open = ?", job.ID, true).
+			Update("is_open", false)
+		if result.Error != nil {
+			slog.Error("Failed to close expired job", "job_id", job.ID, "error", result.Error)
+			continue
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		closedCount++
+		job.IsOpen = false
+
+		if err := RemoveSavedJobsForClosedJobs(current.DB, job.ID); err != nil {
+			slog.Warn("Failed to remove saved jobs for closed job", "job_id", job.ID, "error", err)
+		}
+
+		if current.emailService == nil {
+			continue
+		}
+		if err := current.sendDeadlineClosedEmail(&job); err != nil {
+			slog.Warn("Failed to send deadline summary email", "job_id", job.ID, "error", err)
+		}
+	}
+
+	if closedCount > 0 {
+		slog.Info("Closed jobs past their application deadline", "count", closedCount)
+	}
+	return nil
+}
+
+func (current *JobLifecycleService) sendDeadlineClosedEmail(job *model.Job) error {
+	type PendingApplicant struct {
+		FirstName string
+		LastName  string
+		AppliedAt time.Time
+	}
+	type Context struct {
+		CompanyUser       model.User
+		Job               *model.Job
+		PendingApplicants []PendingApplicant
+	}
+	var context Context
+	context.Job = job
+
+	if err := current.DB.Select("username").Take(&context.CompanyUser, "id = ?", job.CompanyID).Error; err != nil {
+		return err
+	}
+	var company model.Company
+	if err := current.DB.Select("email").Take(&company, "user_id = ?", job.CompanyID).Error; err != nil {
+		return err
+	}
+
+	if err := current.DB.Model(&model.JobApplication{}).
+		Joins("INNER JOIN google_o_auth_details ON google_o_auth_details.user_id = job_applications.user_id").
+		Select("google_o_auth_details.first_name, google_o_auth_details.last_name, job_applications.created_at AS applied_at").
+		Where("job_applications.job_id = ? AND job_applications.status = ?", job.ID, model.JobApplicationPending).
+		Order("job_applications.created_at ASC").
+		Scan(&context.PendingApplicants).Error; err != nil {
+		return err
+	}
+
+	// Convert to Bangkok timezone (GMT+7)
+	bangkokLocation, _ := time.LoadLocation("Asia/Bangkok")
+	for i := range context.PendingApplicants {
+		context.PendingApplicants[i].AppliedAt = context.PendingApplicants[i].AppliedAt.In(bangkokLocation)
+	}
+
+	var tpl bytes.Buffer
+	if err := current.jobDeadlineClosedEmailTemplate.Execute(&tpl, context); err != nil {
+		return err
+	}
+	return current.emailService.SendTo(
+		company.Email,
+		fmt.Sprintf("[KU-Work] Applications for %s - %s have closed", job.Name, job.Position),
+		tpl.String(),
+	)
+}
+
+// PublishScheduledJobs emails companies once their approved, scheduled jobs become visible to students.
+// Jobs without a PublishAt are visible as soon as they are approved and are not announced again.
+// This function is designed to be called by the scheduler.
+func (current *JobLifecycleService) PublishScheduledJobs() error {
+	now := time.Now()
+
+	var jobs []model.Job
+	if err := current.DB.
+		Where("approval_status = ? AND publish_notified_at IS NULL", model.JobApprovalAccepted).
+		Where("publish_at IS NOT NULL AND publish_at <= ?", now).
+		Where("unpublish_at IS NULL OR unpublish_at > ?", now).
+		Find(&jobs).Error; err != nil {
+		return fmt.Errorf("failed to query scheduled jobs: %w", err)
+	}
+
+	publishedCount := 0
+	for _, job := range jobs {
+		// Mark first so concurrent runs never announce the same job twice
+		result := current.DB.Model(&model.Job{}).
+			Where("id = ? AND publish_notified_at IS NULL", job.ID).
+			Update("publish_notified_at", now)
+		if result.Error != nil {
+			slog.Error("Failed to mark job as published", "job_id", job.ID, "error", result.Error)
+			continue
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		publishedCount++
+
+		if current.emailService == nil {
+			continue
+		}
+		if err := current.sendPublishedEmail(&job); err != nil {
+			slog.Warn("Failed to send job published email", "job_id", job.ID, "error", err)
+		}
+	}
+
+	if publishedCount > 0 {
+		slog.Info("Published scheduled jobs", "count", publishedCount)
+	}
+	return nil
+}
+
+func (current *JobLifecycleService) sendPublishedEmail(job *model.Job) error {
+	type Context struct {
+		CompanyUser model.User
+		Job         *model.Job
+		PublishAt   time.Time
+		UnpublishAt *time.Time
+	}
+	var context Context
+	context.Job = job
+
+	if err := current.DB.Select("username").Take(&context.CompanyUser, "id = ?", job.CompanyID).Error; err != nil {
+		return err
+	}
+	var company model.Company
+	if err := current.DB.Select("email").Take(&company, "user_id = ?", job.CompanyID).Error; err != nil {
+		return err
+	}
+
+	// Convert to Bangkok timezone (GMT+7)
+	bangkokLocation, _ := time.LoadLocation("Asia/Bangkok")
+	context.PublishAt = job.PublishAt.In(bangkokLocation)
+	if job.UnpublishAt != nil {
+		unpublishAt := job.UnpublishAt.In(bangkokLocation)
+		context.UnpublishAt = &unpublishAt
+	}
+
+	var tpl bytes.Buffer
+	if err := current.jobPublishedEmailTemplate.Execute(&tpl, context); err != nil {
+		return err
+	}
+	return current.emailService.SendTo(
+		company.Email,
+		fmt.Sprintf("[KU-Work] Your \"%s - %s\" job is now live", job.Name, job.Position),
+		tpl.String(),
+	)
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"fmt"
+	"ku-work/backend/model"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+// RemoveSavedJobsForClosedJobs deletes bookmarks that point at jobs which are no longer open.
+// If jobIDs are given only bookmarks on those jobs are considered, otherwise every closed job is cleaned up.
+func RemoveSavedJobsForClosedJobs(db *gorm.DB, jobIDs ...uint) error {
+	closedJobs := db.Model(&model.Job{}).Select("id").Where("is_open = ?", false)
+	if len(jobIDs) != 0 {
+		closedJobs = closedJobs.Where("id IN ?", jobIDs)
+	}
+
+	result := db.Where("job_id IN (?)", closedJobs).Delete(&model.SavedJob{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove saved jobs: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		slog.Info("Removed saved jobs for closed jobs", "count", result.RowsAffected)
+	}
+	return nil
+}
+
+// DeleteSavedJobsForStudent removes every bookmark a student has made
+func DeleteSavedJobsForStudent(tx *gorm.DB, studentUserID string) error {
+	if err := tx.Where("user_id = ?", studentUserID).Delete(&model.SavedJob{}).Error; err != nil {
+		return fmt.Errorf("failed to delete saved jobs: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+package tests
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"ku-work/backend/model"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties/assert"
+)
+
+func TestFeed(t *testing.T) {
+	t.Run("OpenJobs", func(t *testing.T) {
+		companyUser := CreateTestUser(t, UserCreationInfo{
+			Username:  fmt.Sprintf("feedtester-%d", time.Now().UnixNano()),
+			IsCompany: true,
+		})
+
+		past := time.Now().Add(-time.Hour)
+		future := time.Now().Add(time.Hour)
+		jobs := []model.Job{
+			{Name: "open", JobType: model.JobTypeFullTime, IsOpen: true, ApprovalStatus: model.JobApprovalAccepted},
+			{Name: "internship", JobType: model.JobTypeInternship, IsOpen: true, ApprovalStatus: model.JobApprovalAccepted},
+			{Name: "closed", JobType: model.JobTypeFullTime, IsOpen: false, ApprovalStatus: model.JobApprovalAccepted},
+			{Name: "pending", JobType: model.JobTypeFullTime, IsOpen: true, ApprovalStatus: model.JobApprovalPending},
+			{Name: "expired", JobType: model.JobTypeFullTime, IsOpen: true, ApprovalStatus: model.JobApprovalAccepted, ApplicationDeadline: &past},
+			{Name: "scheduled", JobType: model.JobTypeFullTime, IsOpen: true, ApprovalStatus: model.JobApprovalAccepted, PublishAt: &future},
+		}
+		for i := range jobs {
+			jobs[i].CompanyID = companyUser.Company.UserID
+			jobs[i].Position = "software engineer"
+			jobs[i].Description = "make software"
+			jobs[i].Experience = model.ExperienceJunior
+			if err := db.Create(&jobs[i]).Error; err != nil {
+				t.Error(err)
+				return
+			}
+		}
+
+		type JSONFeed struct {
+			Version string `json:"version"`
+			Items   []struct {
+				ID    string `json:"id"`
+				Title string `json:"title"`
+			} `json:"items"`
+		}
+		itemTitles := func(w *httptest.ResponseRecorder) []string {
+			feed := JSONFeed{}
+			if err := json.Unmarshal(w.Body.Bytes(), &feed); err != nil {
+				t.Error(err)
+			}
+			titles := []string{}
+			for _, item := range feed.Items {
+				titles = append(titles, strings.SplitN(item.Title, " - ", 2)[0])
+			}
+			return titles
+		}
+
+		w := DoRequest("GET", fmt.Sprintf("/feeds/jobs.json?companyId=%s", companyUser.Company.UserID), "", "")
+		assert.Equal(t, w.Code, 200)
+		assert.Equal(t, strings.HasPrefix(w.Header().Get("Content-Type"), "application/feed+json"), true)
+		assert.Equal(t, strings.HasPrefix(w.Header().Get("Cache-Control"), "public"), true)
+		titles := itemTitles(w)
+		assert.Equal(t, len(titles), 2)
+		assert.Equal(t, strings.Contains(strings.Join(titles, ","), "open"), true)
+		assert.Equal(t, strings.Contains(strings.Join(titles, ","), "internship"), true)
+
+		w = DoRequest("GET", fmt.Sprintf("/feeds/jobs.json?companyId=%s&jobType=internship", companyUser.Company.UserID), "", "")
+		assert.Equal(t, w.Code, 200)
+		assert.Equal(t, itemTitles(w), []string{"internship"})
+
+		// Conditional GET
+		etag := w.Header().Get("ETag")
+		lastModified := w.Header().Get("Last-Modified")
+		assert.Equal(t, etag != "", true)
+		w = DoRequestWithHeaders("GET", fmt.Sprintf("/feeds/jobs.json?companyId=%s&jobType=internship", companyUser.Company.UserID), "", map[string]string{"If-None-Match": etag})
+		assert.Equal(t, w.Code, 304)
+		w = DoRequestWithHeaders("GET", fmt.Sprintf("/feeds/jobs.json?companyId=%s&jobType=internship", companyUser.Company.UserID), "", map[string]string{"If-Modified-Since": lastModified})
+		assert.Equal(t, w.Code, 304)
+
+		// Closing a job changes the feed
+		if err := db.Model(&jobs[1]).Update("is_open", false).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		w = DoRequestWithHeaders("GET", fmt.Sprintf("/feeds/jobs.json?companyId=%s&jobType=internship", companyUser.Company.UserID), "", map[string]string{"If-None-Match": etag})
+		assert.Equal(t, w.Code, 200)
+		assert.Equal(t, itemTitles(w), []string{})
+
+		type RSS struct {
+			Channel struct {
+				Items []struct {
+					Title string `xml:"title"`
+					Link  string `xml:"link"`
+				} `xml:"item"`
+			} `xml:"channel"`
+		}
+		w = DoRequest("GET", fmt.Sprintf("/feeds/jobs.rss?companyId=%s", companyUser.Company.UserID), "", "")
+		assert.Equal(t, w.Code, 200)
+		assert.Equal(t, strings.HasPrefix(w.Header().Get("Content-Type"), "application/rss+xml"), true)
+		rss := RSS{}
+		if err := xml.Unmarshal(w.Body.Bytes(), &rss); err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, len(rss.Channel.Items), 1)
+		assert.Equal(t, strings.HasSuffix(rss.Channel.Items[0].Link, fmt.Sprintf("/jobs?id=%d", jobs[0].ID)), true)
+
+		type Atom struct {
+			Entries []struct {
+				Title string `xml:"title"`
+			} `xml:"entry"`
+		}
+		w = DoRequest("GET", fmt.Sprintf("/feeds/jobs.atom?companyId=%s", companyUser.Company.UserID), "", "")
+		assert.Equal(t, w.Code, 200)
+		assert.Equal(t, strings.HasPrefix(w.Header().Get("Content-Type"), "application/atom+xml"), true)
+		atom := Atom{}
+		if err := xml.Unmarshal(w.Body.Bytes(), &atom); err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, len(atom.Entries), 1)
+
+		w = DoRequest("GET", "/feeds/jobs.json?limit=1000", "", "")
+		assert.Equal(t, w.Code, 400)
+	})
+
+	t.Run("JobPosting", func(t *testing.T) {
+		companyUser := CreateTestUser(t, UserCreationInfo{
+			Username:  fmt.Sprintf("jobpostingtester-%d", time.Now().UnixNano()),
+			IsCompany: true,
+		})
+		companyToken := AccessToken(t, companyUser.Company.UserID)
+
+		deadline := time.Now().Add(48 * time.Hour)
+		unpublishAt := time.Now().Add(24 * time.Hour)
+		jobs := []model.Job{
+			{Name: "onsite", JobType: model.JobTypeFullTime, WorkMode: model.WorkModeOnsite, Province: "Bangkok", District: "Chatuchak",
+				MinSalary: 20000, MaxSalary: 30000, SalaryCurrency: "THB", SalaryPeriod: model.SalaryPeriodMonth,
+				ApplicationDeadline: &deadline, UnpublishAt: &unpublishAt},
+			{Name: "remote", JobType: model.JobTypeInternship, WorkMode: model.WorkModeRemote, SalaryUndisclosed: true},
+			{Name: "pending", JobType: model.JobTypeFullTime, ApprovalStatus: model.JobApprovalPending},
+		}
+		for i := range jobs {
+			jobs[i].CompanyID = companyUser.Company.UserID
+			jobs[i].Position = "software engineer"
+			jobs[i].Description = "make software"
+			jobs[i].Experience = model.ExperienceJunior
+			jobs[i].IsOpen = true
+			if jobs[i].ApprovalStatus == "" {
+				jobs[i].ApprovalStatus = model.JobApprovalAccepted
+			}
+			if err := db.Create(&jobs[i]).Error; err != nil {
+				t.Error(err)
+				return
+			}
+		}
+
+		type JobPosting struct {
+			Type               string `json:"@type"`
+			Title              string `json:"title"`
+			EmploymentType     string `json:"employmentType"`
+			ValidThrough       string `json:"validThrough"`
+			JobLocationType    string `json:"jobLocationType"`
+			HiringOrganization struct {
+				Name string `json:"name"`
+			} `json:"hiringOrganization"`
+			JobLocation *struct {
+				Address struct {
+					AddressRegion string `json:"addressRegion"`
+				} `json:"address"`
+			} `json:"jobLocation"`
+			BaseSalary *struct {
+				Currency string `json:"currency"`
+				Value    struct {
+					MinValue uint   `json:"minValue"`
+					UnitText string `json:"unitText"`
+				} `json:"value"`
+			} `json:"baseSalary"`
+		}
+		decode := func(w *httptest.ResponseRecorder) JobPosting {
+			posting := JobPosting{}
+			if err := json.Unmarshal(w.Body.Bytes(), &posting); err != nil {
+				t.Error(err)
+			}
+			return posting
+		}
+
+		w := DoRequest("GET", fmt.Sprintf("/feeds/jobs/%d", jobs[0].ID), "", "")
+		assert.Equal(t, w.Code, 200)
+		assert.Equal(t, strings.HasPrefix(w.Header().Get("Content-Type"), "application/ld+json"), true)
+		posting := decode(w)
+		assert.Equal(t, posting.Type, "JobPosting")
+		assert.Equal(t, posting.Title, "onsite")
+		assert.Equal(t, posting.EmploymentType, "FULL_TIME")
+		assert.Equal(t, posting.HiringOrganization.Name, companyUser.User.Username)
+		assert.Equal(t, posting.ValidThrough, unpublishAt.UTC().Format(time.RFC3339))
+		assert.Equal(t, posting.JobLocation != nil && posting.JobLocation.Address.AddressRegion == "Bangkok", true)
+		assert.Equal(t, posting.BaseSalary != nil && posting.BaseSalary.Currency == "THB", true)
+		assert.Equal(t, posting.BaseSalary != nil && posting.BaseSalary.Value.MinValue == 20000 && posting.BaseSalary.Value.UnitText == "MONTH", true)
+
+		w = DoRequest("GET", fmt.Sprintf("/feeds/jobs/%d", jobs[1].ID), "", "")
+		assert.Equal(t, w.Code, 200)
+		posting = decode(w)
+		assert.Equal(t, posting.EmploymentType, "INTERN")
+		assert.Equal(t, posting.JobLocationType, "TELECOMMUTE")
+		assert.Equal(t, posting.JobLocation == nil, true)
+		assert.Equal(t, posting.BaseSalary == nil, true)
+
+		w = DoRequest("GET", fmt.Sprintf("/feeds/jobs/%d", jobs[2].ID), "", "")
+		assert.Equal(t, w.Code, 404)
+
+		// Job details can be negotiated as JSON-LD
+		headers := map[string]string{"Authorization": fmt.Sprintf("Bearer %s", companyToken)}
+		w = DoRequestWithHeaders("GET", fmt.Sprintf("/jobs/%d", jobs[0].ID), "", headers)
+		assert.Equal(t, w.Code, 200)
+		assert.Equal(t, strings.HasPrefix(w.Header().Get("Content-Type"), "application/json"), true)
+		headers["Accept"] = "application/ld+json"
+		w = DoRequestWithHeaders("GET", fmt.Sprintf("/jobs/%d", jobs[0].ID), "", headers)
+		assert.Equal(t, w.Code, 200)
+		assert.Equal(t, strings.HasPrefix(w.Header().Get("Content-Type"), "application/ld+json"), true)
+		assert.Equal(t, decode(w).Title, "onsite")
+
+		type URLSet struct {
+			URLs []struct {
+				Loc string `xml:"loc"`
+			} `xml:"url"`
+		}
+		w = DoRequest("GET", "/feeds/sitemap.xml", "", "")
+		assert.Equal(t, w.Code, 200)
+		urlSet := URLSet{}
+		if err := xml.Unmarshal(w.Body.Bytes(), &urlSet); err != nil {
+			t.Error(err)
+			return
+		}
+		locs := map[string]bool{}
+		for _, url := range urlSet.URLs {
+			locs[url.Loc[strings.LastIndex(url.Loc, "/"):]] = true
+		}
+		assert.Equal(t, locs[fmt.Sprintf("/jobs?id=%d", jobs[0].ID)], true)
+		assert.Equal(t, locs[fmt.Sprintf("/jobs?id=%d", jobs[1].ID)], true)
+		assert.Equal(t, locs[fmt.Sprintf("/jobs?id=%d", jobs[2].ID)], false)
+		w = DoRequestWithHeaders("GET", "/feeds/sitemap.xml", "", map[string]string{"If-None-Match": w.Header().Get("ETag")})
+		assert.Equal(t, w.Code, 304)
+	})
+}
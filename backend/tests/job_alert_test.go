@@ -0,0 +1,177 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"ku-work/backend/handlers"
+	"ku-work/backend/model"
+	"ku-work/backend/services"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties/assert"
+)
+
+func TestJobAlert(t *testing.T) {
+	t.Run("CreateAndUnsubscribe", func(t *testing.T) {
+		var err error
+		var studentUser *UserCreationResult
+		if studentUser, err = CreateUser(UserCreationInfo{
+			Username:  fmt.Sprintf("jobalerttester-student-%d", time.Now().UnixNano()),
+			IsStudent: true,
+			IsOAuth:   true,
+		}); err != nil {
+			t.Error(err)
+			return
+		}
+		defer (func() {
+			_ = db.Delete(&studentUser.User)
+		})()
+		jwtToken, _, err := handlers.NewJWTHandlers(db, redisClient).GenerateTokens(studentUser.User.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		w := httptest.NewRecorder()
+		payload := `{"name":"Backend internships","keyword":"backend","jobType":["internship"],"frequency":"weekly"}`
+		req, _ := http.NewRequest("POST", "/me/job-alerts", strings.NewReader(payload))
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwtToken))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, w.Code, 200)
+
+		alert := model.JobAlert{}
+		if err := json.Unmarshal(w.Body.Bytes(), &alert); err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, alert.Frequency, model.JobAlertWeekly)
+		if err := db.First(&alert, alert.ID).Error; err != nil {
+			t.Error(err)
+			return
+		}
+
+		unsubscribeURL := fmt.Sprintf("/job-alerts/unsubscribe/%s", alert.UnsubscribeToken)
+
+		// Opening the link from the email only asks for confirmation
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", unsubscribeURL, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, w.Code, 200)
+		assert.Equal(t, strings.Contains(w.Body.String(), "Backend internships"), true)
+		var count int64
+		db.Model(&model.JobAlert{}).Where("id = ?", alert.ID).Count(&count)
+		assert.Equal(t, count, int64(1))
+
+		for _, code := range []int{200, 404} {
+			w = httptest.NewRecorder()
+			req, _ = http.NewRequest("POST", unsubscribeURL, nil)
+			router.ServeHTTP(w, req)
+			assert.Equal(t, w.Code, code)
+		}
+		db.Model(&model.JobAlert{}).Where("id = ?", alert.ID).Count(&count)
+		assert.Equal(t, count, int64(0))
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", unsubscribeURL, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, w.Code, 404)
+	})
+
+	t.Run("Digest", func(t *testing.T) {
+		var err error
+		var companyUser *UserCreationResult
+		if companyUser, err = CreateUser(UserCreationInfo{
+			Username:  fmt.Sprintf("jobalerttester-company-%d", time.Now().UnixNano()),
+			IsCompany: true,
+		}); err != nil {
+			t.Error(err)
+			return
+		}
+		defer (func() {
+			_ = db.Delete(&companyUser.User)
+		})()
+		var studentUser *UserCreationResult
+		if studentUser, err = CreateUser(UserCreationInfo{
+			Username:  fmt.Sprintf("jobalerttester-digest-%d", time.Now().UnixNano()),
+			IsStudent: true,
+			IsOAuth:   true,
+		}); err != nil {
+			t.Error(err)
+			return
+		}
+		defer (func() {
+			_ = db.Delete(&studentUser.User)
+		})()
+
+		approvedAt := time.Now().Add(-time.Hour)
+		job := model.Job{
+			Name:           fmt.Sprintf("alertable-job-%d", time.Now().UnixNano()),
+			Position:       "Digest Engineer",
+			CompanyID:      companyUser.Company.UserID,
+			IsOpen:         true,
+			JobType:        model.JobTypeInternship,
+			ApprovalStatus: model.JobApprovalAccepted,
+			ApprovedAt:     &approvedAt,
+		}
+		if err := db.Create(&job).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		token, err := services.GenerateJobAlertUnsubscribeToken()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		alert := model.JobAlert{
+			UserID:           studentUser.User.ID,
+			Name:             "Digest alert",
+			Keyword:          "digest",
+			JobType:          []string{string(model.JobTypeInternship)},
+			Frequency:        model.JobAlertDaily,
+			LastSentAt:       time.Now().Add(-25 * time.Hour),
+			UnsubscribeToken: token,
+		}
+		if err := db.Create(&alert).Error; err != nil {
+			t.Error(err)
+			return
+		}
+
+		emailService, err := services.NewEmailService(db)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		jobAlertService, err := services.NewJobAlertService(db, emailService)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if err := jobAlertService.SendJobAlertDigests(); err != nil {
+			t.Error(err)
+			return
+		}
+
+		var mailCount int64
+		if err := db.Model(&model.MailLog{}).Where("\"to\" = ? AND body LIKE ?", studentUser.OAuth.Email, "%"+job.Name+"%").Count(&mailCount).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, mailCount, int64(1))
+
+		// The next run is not due yet, so nothing is sent again
+		if err := jobAlertService.SendJobAlertDigests(); err != nil {
+			t.Error(err)
+			return
+		}
+		if err := db.Model(&model.MailLog{}).Where("\"to\" = ? AND body LIKE ?", studentUser.OAuth.Email, "%"+job.Name+"%").Count(&mailCount).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, mailCount, int64(1))
+	})
+}
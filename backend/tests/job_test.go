@@ -2,14 +2,18 @@ package tests
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"ku-work/backend/handlers"
+	"ku-work/backend/helper"
 	"ku-work/backend/model"
+	"ku-work/backend/services"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 	"time"
@@ -395,6 +399,7 @@ func TestJob(t *testing.T) {
 		job := model.Job{
 			CompanyID:      company.UserID,
 			ApprovalStatus: model.JobApprovalAccepted,
+			IsOpen:         true,
 		}
 		if result := db.Create(&job); result.Error != nil {
 			t.Error(result.Error)
@@ -734,5 +739,2019 @@ func TestJob(t *testing.T) {
 			assert.Equal(t, editedJobApplication.Status, status)
 		}
 	})
+	t.Run("Search", func(t *testing.T) {
+		var err error
+		var userCreationResult *UserCreationResult
+		if userCreationResult, err = CreateUser(UserCreationInfo{
+			Username:  fmt.Sprintf("searchjobtester-%d", time.Now().UnixNano()),
+			IsCompany: true,
+		}); err != nil {
+			t.Error(err)
+			return
+		}
+		defer (func() {
+			_ = db.Delete(&userCreationResult.User)
+		})()
+		company := userCreationResult.Company
+		titleMatch := model.Job{
+			Name:           "Backend Engineer",
+			CompanyID:      company.UserID,
+			Position:       "engineer",
+			Duration:       "6 months",
+			Description:    "write go services",
+			Location:       "bangkok",
+			JobType:        model.JobTypeFullTime,
+			Experience:     model.ExperienceJunior,
+			IsOpen:         true,
+			ApprovalStatus: model.JobApprovalAccepted,
+		}
+		descriptionMatch := model.Job{
+			Name:           "Marketing Intern",
+			CompanyID:      company.UserID,
+			Position:       "marketing",
+			Duration:       "3 months",
+			Description:    "work closely with our engineering team",
+			Location:       "bangkok",
+			JobType:        model.JobTypeInternship,
+			Experience:     model.ExperienceInternship,
+			IsOpen:         true,
+			ApprovalStatus: model.JobApprovalAccepted,
+		}
+		unrelated := model.Job{
+			Name:           "Accountant",
+			CompanyID:      company.UserID,
+			Position:       "accounting",
+			Duration:       "1 year",
+			Description:    "balance the books",
+			Location:       "bangkok",
+			JobType:        model.JobTypeFullTime,
+			Experience:     model.ExperienceSenior,
+			IsOpen:         true,
+			ApprovalStatus: model.JobApprovalAccepted,
+		}
+		thaiMatch := model.Job{
+			Name:           "นักพัฒนาซอฟต์แวร์ฝึกงาน",
+			CompanyID:      company.UserID,
+			Position:       "นักพัฒนา",
+			Duration:       "3 เดือน",
+			Description:    "พัฒนาระบบหลังบ้านด้วย Go",
+			Location:       "bangkok",
+			JobType:        model.JobTypeInternship,
+			Experience:     model.ExperienceInternship,
+			IsOpen:         true,
+			ApprovalStatus: model.JobApprovalAccepted,
+		}
+		for _, job := range []*model.Job{&descriptionMatch, &titleMatch, &unrelated, &thaiMatch} {
+			if err := db.Create(job).Error; err != nil {
+				t.Error(err)
+				return
+			}
+		}
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/jobs?keyword=engineering&sort=relevance", strings.NewReader(""))
+		jwtHandler := handlers.NewJWTHandlers(db, redisClient)
+		jwtToken, _, err := jwtHandler.GenerateTokens(company.UserID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwtToken))
+		router.ServeHTTP(w, req)
+		assert.Equal(t, w.Code, 200)
+		type Result struct {
+			Jobs  []handlers.JobWithStatsResponse `json:"jobs"`
+			Error string                          `json:"error"`
+		}
+		result := Result{}
+		if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+			t.Error(err)
+			return
+		}
+		if result.Error != "" {
+			t.Error(result.Error)
+			return
+		}
+		assert.Equal(t, len(result.Jobs), 2)
+		assert.Equal(t, result.Jobs[0].ID, titleMatch.ID)
+		assert.Equal(t, result.Jobs[1].ID, descriptionMatch.ID)
+		if result.Jobs[0].Relevance == nil || result.Jobs[1].Relevance == nil {
+			t.Error("Expected relevance score for every hit")
+			return
+		}
+		if *result.Jobs[0].Relevance <= *result.Jobs[1].Relevance {
+			t.Error("Expected title match to rank above description match")
+		}
+
+		// Thai has no spaces between words, so keywords are found in the middle of a phrase,
+		// alone or together with English words
+		for _, keyword := range []string{"ซอฟต์แวร์", "หลังบ้าน go", "ซอฟต์แวร์ accounting"} {
+			w = httptest.NewRecorder()
+			req, _ = http.NewRequest("GET", "/jobs?keyword="+url.QueryEscape(keyword), strings.NewReader(""))
+			req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwtToken))
+			router.ServeHTTP(w, req)
+			assert.Equal(t, w.Code, 200)
+			result = Result{}
+			if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+				t.Error(err)
+				return
+			}
+			if keyword == "ซอฟต์แวร์ accounting" {
+				assert.Equal(t, len(result.Jobs), 0)
+				continue
+			}
+			assert.Equal(t, len(result.Jobs), 1)
+			assert.Equal(t, result.Jobs[0].ID, thaiMatch.ID)
+		}
+	})
+	t.Run("DeadlineClose", func(t *testing.T) {
+		var err error
+		var userCreationResult *UserCreationResult
+		if userCreationResult, err = CreateUser(UserCreationInfo{
+			Username:  fmt.Sprintf("deadlinejobtester-%d", time.Now().UnixNano()),
+			IsCompany: true,
+		}); err != nil {
+			t.Error(err)
+			return
+		}
+		defer (func() {
+			_ = db.Delete(&userCreationResult.User)
+		})()
+		company := userCreationResult.Company
+		past := time.Now().Add(-time.Hour)
+		future := time.Now().Add(24 * time.Hour)
+		expiredJob := model.Job{
+			Name:                fmt.Sprintf("expired-job-%d", time.Now().UnixNano()),
+			CompanyID:           company.UserID,
+			IsOpen:              true,
+			ApprovalStatus:      model.JobApprovalAccepted,
+			ApplicationDeadline: &past,
+		}
+		activeJob := model.Job{
+			Name:                fmt.Sprintf("active-job-%d", time.Now().UnixNano()),
+			CompanyID:           company.UserID,
+			IsOpen:              true,
+			ApprovalStatus:      model.JobApprovalAccepted,
+			ApplicationDeadline: &future,
+		}
+		for _, job := range []*model.Job{&expiredJob, &activeJob} {
+			if err := db.Create(job).Error; err != nil {
+				t.Error(err)
+				return
+			}
+		}
+		emailService, err := services.NewEmailService(db)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		jobLifecycleService, err := services.NewJobLifecycleService(db, emailService)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if err := jobLifecycleService.CloseExpiredJobs(); err != nil {
+			t.Error(err)
+			return
+		}
+		if err := db.First(&expiredJob, expiredJob.ID).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		if err := db.First(&activeJob, activeJob.ID).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, expiredJob.IsOpen, false)
+		assert.Equal(t, activeJob.IsOpen, true)
+	})
+	t.Run("ClearDeadline", func(t *testing.T) {
+		companyUser := CreateTestUser(t, UserCreationInfo{
+			Username:  fmt.Sprintf("cleardeadlinejobtester-%d", time.Now().UnixNano()),
+			IsCompany: true,
+		})
+		deadline := time.Now().Add(24 * time.Hour)
+		job := model.Job{
+			Name:                fmt.Sprintf("deadline-job-%d", time.Now().UnixNano()),
+			CompanyID:           companyUser.Company.UserID,
+			IsOpen:              true,
+			ApprovalStatus:      model.JobApprovalAccepted,
+			ApplicationDeadline: &deadline,
+		}
+		if err := db.Create(&job).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		companyToken := AccessToken(t, companyUser.User.ID)
+		jobPath := fmt.Sprintf("/jobs/%d", job.ID)
+
+		// Leaving the deadline out keeps it
+		w := DoRequest("PATCH", jobPath, companyToken, `{"open": true}`)
+		assert.Equal(t, w.Code, 200)
+		if err := db.First(&job, job.ID).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, job.ApplicationDeadline != nil, true)
+
+		w = DoRequest("PATCH", jobPath, companyToken, fmt.Sprintf(`{"clearApplicationDeadline": true, "applicationDeadline": %q}`, deadline.Format(time.RFC3339)))
+		assert.Equal(t, w.Code, 400)
+
+		w = DoRequest("PATCH", jobPath, companyToken, `{"clearApplicationDeadline": true}`)
+		assert.Equal(t, w.Code, 200)
+		cleared := model.Job{}
+		if err := db.First(&cleared, job.ID).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, cleared.ApplicationDeadline == nil, true)
+	})
+	t.Run("PublishWindow", func(t *testing.T) {
+		var err error
+		var companyUser *UserCreationResult
+		if companyUser, err = CreateUser(UserCreationInfo{
+			Username:  fmt.Sprintf("publishjobtester-company-%d", time.Now().UnixNano()),
+			IsCompany: true,
+		}); err != nil {
+			t.Error(err)
+			return
+		}
+		defer (func() {
+			_ = db.Delete(&companyUser.User)
+		})()
+		var viewerUser *UserCreationResult
+		if viewerUser, err = CreateUser(UserCreationInfo{
+			Username: fmt.Sprintf("publishjobtester-viewer-%d", time.Now().UnixNano()),
+			IsOAuth:  true,
+		}); err != nil {
+			t.Error(err)
+			return
+		}
+		defer (func() {
+			_ = db.Delete(&viewerUser.User)
+		})()
+		publishAt := time.Now().Add(24 * time.Hour)
+		job := model.Job{
+			Name:           fmt.Sprintf("scheduled-job-%d", time.Now().UnixNano()),
+			CompanyID:      companyUser.Company.UserID,
+			IsOpen:         true,
+			ApprovalStatus: model.JobApprovalAccepted,
+			PublishAt:      &publishAt,
+		}
+		if err := db.Create(&job).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		jwtHandler := handlers.NewJWTHandlers(db, redisClient)
+		for _, testCase := range []struct {
+			userID string
+			code   int
+		}{
+			{companyUser.User.ID, 200},
+			{viewerUser.User.ID, 404},
+		} {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", fmt.Sprintf("/jobs/%d", job.ID), strings.NewReader(""))
+			jwtToken, _, err := jwtHandler.GenerateTokens(testCase.userID)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwtToken))
+			router.ServeHTTP(w, req)
+			assert.Equal(t, w.Code, testCase.code)
+		}
+	})
+
+	t.Run("SavedJobs", func(t *testing.T) {
+		companyUser := CreateTestUser(t, UserCreationInfo{
+			Username:  fmt.Sprintf("savedjobtester-company-%d", time.Now().UnixNano()),
+			IsCompany: true,
+		})
+		studentUser := CreateTestUser(t, UserCreationInfo{
+			Username:  fmt.Sprintf("savedjobtester-student-%d", time.Now().UnixNano()),
+			IsStudent: true,
+		})
+		job := model.Job{
+			Name:           fmt.Sprintf("saved-job-%d", time.Now().UnixNano()),
+			CompanyID:      companyUser.Company.UserID,
+			IsOpen:         true,
+			ApprovalStatus: model.JobApprovalAccepted,
+		}
+		if err := db.Create(&job).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		studentToken := AccessToken(t, studentUser.User.ID)
+
+		// Saving twice must not fail
+		for range 2 {
+			w := DoRequest("POST", fmt.Sprintf("/me/saved-jobs/%d", job.ID), studentToken, "")
+			assert.Equal(t, w.Code, 200)
+		}
+
+		type SavedJobsResult struct {
+			Jobs  []handlers.JobResponse `json:"jobs"`
+			Total int64                  `json:"total"`
+		}
+		w := DoRequest("GET", "/me/saved-jobs", studentToken, "")
+		assert.Equal(t, w.Code, 200)
+		result := SavedJobsResult{}
+		if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, result.Total, int64(1))
+		if len(result.Jobs) == 1 {
+			assert.Equal(t, result.Jobs[0].ID, job.ID)
+			assert.Equal(t, result.Jobs[0].Saved, true)
+		}
+
+		// Closing the job removes it from every student's saved list
+		if err := db.Model(&job).Update("is_open", false).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		if err := services.RemoveSavedJobsForClosedJobs(db, job.ID); err != nil {
+			t.Error(err)
+			return
+		}
+		var count int64
+		if err := db.Model(&model.SavedJob{}).Where("job_id = ?", job.ID).Count(&count).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, count, int64(0))
+
+		w = DoRequest("POST", fmt.Sprintf("/me/saved-jobs/%d", job.ID), studentToken, "")
+		assert.Equal(t, w.Code, 404)
+
+		// A job past its deadline cannot be saved even before it is closed
+		pastDeadline := time.Now().Add(-time.Hour)
+		expiredJob := model.Job{
+			Name:                fmt.Sprintf("saved-expired-job-%d", time.Now().UnixNano()),
+			CompanyID:           companyUser.Company.UserID,
+			IsOpen:              true,
+			ApprovalStatus:      model.JobApprovalAccepted,
+			ApplicationDeadline: &pastDeadline,
+		}
+		if err := db.Create(&expiredJob).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		w = DoRequest("POST", fmt.Sprintf("/me/saved-jobs/%d", expiredJob.ID), studentToken, "")
+		assert.Equal(t, w.Code, 404)
+
+		// Disabling a company only removes the bookmarks on the jobs it closes
+		otherCompanyUser := CreateTestUser(t, UserCreationInfo{
+			Username:  fmt.Sprintf("savedjobtester-other-company-%d", time.Now().UnixNano()),
+			IsCompany: true,
+		})
+		openJob := model.Job{
+			Name:           fmt.Sprintf("saved-open-job-%d", time.Now().UnixNano()),
+			CompanyID:      companyUser.Company.UserID,
+			IsOpen:         true,
+			ApprovalStatus: model.JobApprovalAccepted,
+		}
+		otherClosedJob := model.Job{
+			Name:           fmt.Sprintf("saved-other-closed-job-%d", time.Now().UnixNano()),
+			CompanyID:      otherCompanyUser.Company.UserID,
+			ApprovalStatus: model.JobApprovalAccepted,
+		}
+		for _, savedJob := range []*model.Job{&openJob, &otherClosedJob} {
+			if err := db.Create(savedJob).Error; err != nil {
+				t.Error(err)
+				return
+			}
+			if err := db.Create(&model.SavedJob{UserID: studentUser.User.ID, JobID: savedJob.ID}).Error; err != nil {
+				t.Error(err)
+				return
+			}
+		}
+		if err := services.DisableCompanyJobPosts(db, companyUser.Company.UserID); err != nil {
+			t.Error(err)
+			return
+		}
+		for _, testCase := range []struct {
+			jobID uint
+			count int64
+		}{
+			{openJob.ID, 0},
+			{otherClosedJob.ID, 1},
+		} {
+			if err := db.Model(&model.SavedJob{}).Where("job_id = ?", testCase.jobID).Count(&count).Error; err != nil {
+				t.Error(err)
+				return
+			}
+			assert.Equal(t, count, testCase.count)
+		}
+	})
+
+	t.Run("Recommended", func(t *testing.T) {
+		var err error
+		var companyUser *UserCreationResult
+		if companyUser, err = CreateUser(UserCreationInfo{
+			Username:  fmt.Sprintf("recommendtester-company-%d", time.Now().UnixNano()),
+			IsCompany: true,
+		}); err != nil {
+			t.Error(err)
+			return
+		}
+		defer (func() {
+			_ = db.Delete(&companyUser.User)
+		})()
+		var studentUser *UserCreationResult
+		if studentUser, err = CreateUser(UserCreationInfo{
+			Username:  fmt.Sprintf("recommendtester-student-%d", time.Now().UnixNano()),
+			IsStudent: true,
+		}); err != nil {
+			t.Error(err)
+			return
+		}
+		defer (func() {
+			_ = db.Delete(&studentUser.User)
+		})()
+		skillName := fmt.Sprintf("recommend-skill-%d", time.Now().UnixNano())
+		skill := model.Skill{Name: skillName, Slug: skillName}
+		if err := db.Create(&skill).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		defer (func() {
+			_ = db.Delete(&skill)
+		})()
+		if err := db.Create(&model.StudentSkill{UserID: studentUser.User.ID, SkillID: skill.ID}).Error; err != nil {
+			t.Error(err)
+			return
+		}
 
+		jobs := make([]model.Job, 3)
+		for i := range jobs {
+			jobs[i] = model.Job{
+				Name:           fmt.Sprintf("recommend-job-%d-%d", i, time.Now().UnixNano()),
+				CompanyID:      companyUser.Company.UserID,
+				IsOpen:         true,
+				ApprovalStatus: model.JobApprovalAccepted,
+			}
+			if err := db.Create(&jobs[i]).Error; err != nil {
+				t.Error(err)
+				return
+			}
+		}
+		matchingJob, appliedJob := jobs[1], jobs[2]
+		for _, job := range []model.Job{matchingJob, appliedJob} {
+			if err := db.Create(&model.JobSkill{JobID: job.ID, SkillID: skill.ID, Required: true}).Error; err != nil {
+				t.Error(err)
+				return
+			}
+		}
+		if err := db.Create(&model.JobApplication{JobID: appliedJob.ID, UserID: studentUser.User.ID, Status: model.JobApplicationPending}).Error; err != nil {
+			t.Error(err)
+			return
+		}
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/jobs/recommended?limit=64", nil)
+		jwtToken, _, err := handlers.NewJWTHandlers(db, redisClient).GenerateTokens(studentUser.User.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwtToken))
+		router.ServeHTTP(w, req)
+		assert.Equal(t, w.Code, 200)
+
+		type RecommendedResult struct {
+			Jobs []handlers.RecommendedJobResponse `json:"jobs"`
+		}
+		result := RecommendedResult{}
+		if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+			t.Error(err)
+			return
+		}
+		if len(result.Jobs) == 0 {
+			t.Error("expected recommended jobs")
+			return
+		}
+		// The job sharing the student's skill ranks first and says why
+		assert.Equal(t, result.Jobs[0].ID, matchingJob.ID)
+		assert.Equal(t, len(result.Jobs[0].Reasons) > 0, true)
+		for _, job := range result.Jobs {
+			if job.ID == appliedJob.ID {
+				t.Error("applied job must not be recommended")
+			}
+		}
+	})
+
+	t.Run("Revisions", func(t *testing.T) {
+		var err error
+		var companyUser *UserCreationResult
+		if companyUser, err = CreateUser(UserCreationInfo{
+			Username:  fmt.Sprintf("revisionjobtester-%d", time.Now().UnixNano()),
+			IsCompany: true,
+		}); err != nil {
+			t.Error(err)
+			return
+		}
+		defer (func() {
+			_ = db.Delete(&companyUser.User)
+		})()
+		job := model.Job{
+			Name:           fmt.Sprintf("revision-job-%d", time.Now().UnixNano()),
+			CompanyID:      companyUser.Company.UserID,
+			Position:       "software engineer",
+			Description:    "make software",
+			MinSalary:      10,
+			MaxSalary:      100,
+			IsOpen:         true,
+			ApprovalStatus: model.JobApprovalAccepted,
+		}
+		if err := db.Create(&job).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		jwtToken, _, err := handlers.NewJWTHandlers(db, redisClient).GenerateTokens(companyUser.User.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		for _, testCase := range []struct {
+			payload string
+			status  model.JobApprovalStatus
+		}{
+			// Turning on application notifications is not material, retitling the job is
+			{`{"notifyOnApplication": true}`, model.JobApprovalAccepted},
+			{`{"name": "renamed job"}`, model.JobApprovalPending},
+		} {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("PATCH", fmt.Sprintf("/jobs/%d", job.ID), strings.NewReader(testCase.payload))
+			req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwtToken))
+			req.Header.Add("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+			assert.Equal(t, w.Code, 200)
+			edited := model.Job{}
+			if err := db.First(&edited, job.ID).Error; err != nil {
+				t.Error(err)
+				return
+			}
+			assert.Equal(t, edited.ApprovalStatus, testCase.status)
+		}
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/jobs/%d/revisions", job.ID), nil)
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwtToken))
+		router.ServeHTTP(w, req)
+		assert.Equal(t, w.Code, 200)
+		type RevisionsResult struct {
+			Revisions []model.JobRevision `json:"revisions"`
+			Total     int64               `json:"total"`
+		}
+		result := RevisionsResult{}
+		if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, result.Total, int64(2))
+		if len(result.Revisions) == 2 {
+			latest := result.Revisions[0]
+			assert.Equal(t, latest.RequiresReapproval, true)
+			assert.Equal(t, len(latest.Changes), 1)
+			assert.Equal(t, latest.Changes[0].Field, "name")
+			assert.Equal(t, result.Revisions[1].RequiresReapproval, false)
+		}
+	})
+
+	t.Run("CloneAndTemplate", func(t *testing.T) {
+		companyUser := CreateTestUser(t, UserCreationInfo{
+			Username:  fmt.Sprintf("clonejobtester-%d", time.Now().UnixNano()),
+			IsCompany: true,
+		})
+		job := model.Job{
+			Name:           fmt.Sprintf("clone-source-%d", time.Now().UnixNano()),
+			CompanyID:      companyUser.Company.UserID,
+			Position:       "software engineer",
+			Duration:       "6 months",
+			Description:    "make software",
+			Location:       "Bangkok",
+			JobType:        "fulltime",
+			Experience:     "junior",
+			MinSalary:      10,
+			MaxSalary:      100,
+			IsOpen:         true,
+			ApprovalStatus: model.JobApprovalAccepted,
+		}
+		if err := db.Create(&job).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		companyToken := AccessToken(t, companyUser.User.ID)
+		type CreatedResult struct {
+			ID uint `json:"id"`
+		}
+
+		w := DoRequest("POST", fmt.Sprintf("/jobs/%d/clone", job.ID), companyToken, `{"position": "senior engineer"}`)
+		assert.Equal(t, w.Code, 200)
+		created := CreatedResult{}
+		if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+			t.Error(err)
+			return
+		}
+		clone := model.Job{}
+		if err := db.First(&clone, created.ID).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, clone.Name, job.Name)
+		assert.Equal(t, clone.Position, "senior engineer")
+		assert.Equal(t, clone.ApprovalStatus, model.JobApprovalPending)
+		assert.Equal(t, clone.IsOpen, false)
+		assert.Equal(t, clone.ClonedFromID != nil && *clone.ClonedFromID == job.ID, true)
+
+		// Templates may be partial, missing fields must be provided when instantiating
+		w = DoRequest("POST", "/job-templates", companyToken, `{"title": "Intern", "position": "intern", "duration": "3 months", "description": "learn", "location": "Bangkok", "jobType": "internship", "experience": "internship", "minSalary": 1, "maxSalary": 2}`)
+		assert.Equal(t, w.Code, 200)
+		template := model.JobTemplate{}
+		if err := json.Unmarshal(w.Body.Bytes(), &template); err != nil {
+			t.Error(err)
+			return
+		}
+		w = DoRequest("POST", "/job-templates", companyToken, `{"title": "Intern"}`)
+		assert.Equal(t, w.Code, 409)
+
+		w = DoRequest("POST", fmt.Sprintf("/job-templates/%d/jobs", template.ID), companyToken, "")
+		assert.Equal(t, w.Code, 400)
+		w = DoRequest("POST", fmt.Sprintf("/job-templates/%d/jobs", template.ID), companyToken, `{"name": "summer intern"}`)
+		assert.Equal(t, w.Code, 200)
+		if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+			t.Error(err)
+			return
+		}
+		instance := model.Job{}
+		if err := db.First(&instance, created.ID).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, instance.Name, "summer intern")
+		assert.Equal(t, instance.Position, "intern")
+		assert.Equal(t, instance.ApprovalStatus, model.JobApprovalPending)
+		assert.Equal(t, instance.TemplateID != nil && *instance.TemplateID == template.ID, true)
+	})
+
+	t.Run("CursorPagination", func(t *testing.T) {
+		var err error
+		var companyUser *UserCreationResult
+		if companyUser, err = CreateUser(UserCreationInfo{
+			Username:  fmt.Sprintf("cursorjobtester-%d", time.Now().UnixNano()),
+			IsCompany: true,
+		}); err != nil {
+			t.Error(err)
+			return
+		}
+		defer (func() {
+			_ = db.Delete(&companyUser.User)
+		})()
+		for i := range 5 {
+			job := model.Job{
+				Name:           fmt.Sprintf("cursor-job-%d-%d", i, time.Now().UnixNano()),
+				CompanyID:      companyUser.Company.UserID,
+				Position:       "software engineer",
+				Description:    "make software",
+				MinSalary:      10,
+				MaxSalary:      100,
+				IsOpen:         true,
+				ApprovalStatus: model.JobApprovalAccepted,
+			}
+			if err := db.Create(&job).Error; err != nil {
+				t.Error(err)
+				return
+			}
+		}
+		jwtToken, _, err := handlers.NewJWTHandlers(db, redisClient).GenerateTokens(companyUser.User.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		type FetchJobsResult struct {
+			Jobs       []handlers.JobWithStatsResponse `json:"jobs"`
+			Total      int64                           `json:"total"`
+			NextCursor string                          `json:"nextCursor"`
+			PrevCursor string                          `json:"prevCursor"`
+		}
+		fetch := func(query string) FetchJobsResult {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/jobs?limit=2"+query, nil)
+			req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwtToken))
+			router.ServeHTTP(w, req)
+			assert.Equal(t, w.Code, 200)
+			result := FetchJobsResult{}
+			if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+				t.Error(err)
+			}
+			return result
+		}
+
+		// Walking forward visits every job exactly once
+		seen := map[uint]bool{}
+		pages := []FetchJobsResult{fetch("")}
+		for pages[len(pages)-1].NextCursor != "" && len(pages) < 5 {
+			pages = append(pages, fetch("&cursor="+pages[len(pages)-1].NextCursor))
+		}
+		for _, page := range pages {
+			for _, job := range page.Jobs {
+				assert.Equal(t, seen[job.ID], false)
+				seen[job.ID] = true
+			}
+		}
+		assert.Equal(t, len(pages), 3)
+		assert.Equal(t, len(seen), 5)
+		assert.Equal(t, pages[0].PrevCursor, "")
+
+		// Walking back from the second page returns the first
+		back := fetch("&cursor=" + pages[1].PrevCursor)
+		assert.Equal(t, len(back.Jobs), 2)
+		if len(back.Jobs) == 2 {
+			assert.Equal(t, back.Jobs[0].ID, pages[0].Jobs[0].ID)
+			assert.Equal(t, back.Jobs[1].ID, pages[0].Jobs[1].ID)
+		}
+		assert.Equal(t, back.PrevCursor, "")
+
+		// Offset keeps working for older clients
+		offsetPage := fetch("&offset=2")
+		if len(offsetPage.Jobs) > 0 {
+			assert.Equal(t, offsetPage.Jobs[0].ID, pages[1].Jobs[0].ID)
+		}
+	})
+
+	t.Run("Sorting", func(t *testing.T) {
+		var err error
+		var companyUser *UserCreationResult
+		if companyUser, err = CreateUser(UserCreationInfo{
+			Username:  fmt.Sprintf("sortjobtester-%d", time.Now().UnixNano()),
+			IsCompany: true,
+		}); err != nil {
+			t.Error(err)
+			return
+		}
+		defer (func() {
+			_ = db.Delete(&companyUser.User)
+		})()
+		var viewerUser *UserCreationResult
+		if viewerUser, err = CreateUser(UserCreationInfo{
+			Username: fmt.Sprintf("sortjobviewer-%d", time.Now().UnixNano()),
+			IsOAuth:  true,
+		}); err != nil {
+			t.Error(err)
+			return
+		}
+		defer (func() {
+			_ = db.Delete(&viewerUser.User)
+		})()
+		jobIDs := []uint{}
+		for _, maxSalary := range []uint{200, 300, 100} {
+			job := model.Job{
+				Name:           fmt.Sprintf("sort-job-%d-%d", maxSalary, time.Now().UnixNano()),
+				CompanyID:      companyUser.Company.UserID,
+				Position:       "software engineer",
+				Description:    "make software",
+				MinSalary:      10,
+				MaxSalary:      maxSalary,
+				IsOpen:         true,
+				ApprovalStatus: model.JobApprovalAccepted,
+			}
+			if err := db.Create(&job).Error; err != nil {
+				t.Error(err)
+				return
+			}
+			jobIDs = append(jobIDs, job.ID)
+		}
+		jwtHandler := handlers.NewJWTHandlers(db, redisClient)
+		fetch := func(userID string, query string) (int, []handlers.JobWithStatsResponse) {
+			jwtToken, _, err := jwtHandler.GenerateTokens(userID)
+			if err != nil {
+				t.Error(err)
+			}
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/jobs?"+query, nil)
+			req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwtToken))
+			router.ServeHTTP(w, req)
+			type FetchJobsResult struct {
+				Jobs []handlers.JobWithStatsResponse `json:"jobs"`
+			}
+			result := FetchJobsResult{}
+			if w.Code == 200 {
+				if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+					t.Error(err)
+				}
+			}
+			return w.Code, result.Jobs
+		}
+
+		code, jobs := fetch(companyUser.User.ID, "sortBy=maxSalary")
+		assert.Equal(t, code, 200)
+		if len(jobs) == 3 {
+			assert.Equal(t, jobs[0].MaxSalary, uint(300))
+			assert.Equal(t, jobs[1].MaxSalary, uint(200))
+			assert.Equal(t, jobs[2].MaxSalary, uint(100))
+		} else {
+			t.Errorf("expected 3 jobs, got %d", len(jobs))
+		}
+
+		code, jobs = fetch(companyUser.User.ID, "sortBy=oldest")
+		assert.Equal(t, code, 200)
+		if len(jobs) == 3 {
+			assert.Equal(t, jobs[0].ID, jobIDs[0])
+			assert.Equal(t, jobs[2].ID, jobIDs[2])
+		}
+
+		code, _ = fetch(companyUser.User.ID, "sortBy=pending")
+		assert.Equal(t, code, 200)
+
+		// Application status counts are private to the company
+		code, _ = fetch(viewerUser.User.ID, "sortBy=pending")
+		assert.Equal(t, code, 400)
+
+		code, _ = fetch(companyUser.User.ID, "sortBy=random")
+		assert.Equal(t, code, 400)
+	})
+
+	t.Run("Analytics", func(t *testing.T) {
+		companyUser := CreateTestUser(t, UserCreationInfo{
+			Username:  fmt.Sprintf("analyticsjobtester-%d", time.Now().UnixNano()),
+			IsCompany: true,
+		})
+		viewerUser := CreateTestUser(t, UserCreationInfo{
+			Username: fmt.Sprintf("analyticsjobviewer-%d", time.Now().UnixNano()),
+			IsOAuth:  true,
+		})
+		job := model.Job{
+			Name:           fmt.Sprintf("analytics-job-%d", time.Now().UnixNano()),
+			CompanyID:      companyUser.Company.UserID,
+			Position:       "software engineer",
+			Description:    "make software",
+			MinSalary:      10,
+			MaxSalary:      100,
+			IsOpen:         true,
+			ApprovalStatus: model.JobApprovalAccepted,
+		}
+		if err := db.Create(&job).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		companyToken := AccessToken(t, companyUser.User.ID)
+		viewerToken := AccessToken(t, viewerUser.User.ID)
+
+		// Two views by the same viewer, the owner's own view is not counted
+		for _, token := range []string{viewerToken, viewerToken, companyToken} {
+			w := DoRequest("GET", fmt.Sprintf("/jobs/%d", job.ID), token, "")
+			assert.Equal(t, w.Code, 200)
+		}
+		if err := services.NewJobAnalyticsService(db, redisClient).FlushJobViews(); err != nil {
+			t.Error(err)
+			return
+		}
+
+		w := DoRequest("GET", fmt.Sprintf("/jobs/%d/analytics", job.ID), viewerToken, "")
+		assert.Equal(t, w.Code, 403)
+
+		w = DoRequest("GET", fmt.Sprintf("/jobs/%d/analytics?days=7", job.ID), companyToken, "")
+		assert.Equal(t, w.Code, 200)
+		result := handlers.JobAnalyticsResponse{}
+		if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, result.Views, int64(2))
+		assert.Equal(t, result.UniqueViewers, int64(1))
+		assert.Equal(t, len(result.Daily), 7)
+		if len(result.Daily) == 7 {
+			assert.Equal(t, result.Daily[6].Views, int64(2))
+		}
+
+		// A batch that was written but not deleted from Redis is not counted again
+		ctx := context.Background()
+		leftoverKey := fmt.Sprintf("job_views:flushing:%s:%d", time.Now().UTC().Format("2006-01-02"), time.Now().UnixNano())
+		if err := redisClient.HSet(ctx, leftoverKey, fmt.Sprintf("%d:%s", job.ID, viewerUser.User.ID), 5).Err(); err != nil {
+			t.Error(err)
+			return
+		}
+		if err := db.Create(&model.JobViewFlush{Key: leftoverKey}).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		if err := services.NewJobAnalyticsService(db, redisClient).FlushJobViews(); err != nil {
+			t.Error(err)
+			return
+		}
+		exists, _ := redisClient.Exists(ctx, leftoverKey).Result()
+		assert.Equal(t, exists, int64(0))
+		var views int64
+		db.Model(&model.JobView{}).Where("job_id = ?", job.ID).Select("COALESCE(SUM(views), 0)").Scan(&views)
+		assert.Equal(t, views, int64(2))
+	})
+
+	t.Run("Compensation", func(t *testing.T) {
+		companyUser := CreateTestUser(t, UserCreationInfo{
+			Username:  fmt.Sprintf("salaryjobtester-%d", time.Now().UnixNano()),
+			IsCompany: true,
+		})
+		viewerUser := CreateTestUser(t, UserCreationInfo{
+			Username: fmt.Sprintf("salaryjobviewer-%d", time.Now().UnixNano()),
+			IsOAuth:  true,
+		})
+		companyToken := AccessToken(t, companyUser.User.ID)
+		viewerToken := AccessToken(t, viewerUser.User.ID)
+
+		jobInput := `{"name":"negotiable","position":"Engineer","duration":"6 months","description":"desc","location":"Bangkok","jobType":"fulltime","experience":"junior","salaryUndisclosed":true}`
+		w := DoRequest("POST", "/jobs", companyToken, jobInput)
+		assert.Equal(t, w.Code, 200)
+		w = DoRequest("POST", "/jobs", companyToken, strings.Replace(jobInput, `"salaryUndisclosed":true`, `"minSalary":1,"maxSalary":2,"salaryCurrency":"ABC"`, 1))
+		assert.Equal(t, w.Code, 400)
+
+		// 100 - 200 per hour is 17,600 - 35,200 per month
+		jobs := []model.Job{
+			{Name: "hourly", MinSalary: 100, MaxSalary: 200, SalaryCurrency: "THB", SalaryPeriod: model.SalaryPeriodHour},
+			{Name: "monthly", MinSalary: 20000, MaxSalary: 30000, SalaryCurrency: "THB", SalaryPeriod: model.SalaryPeriodMonth},
+			{Name: "dollars", MinSalary: 20000, MaxSalary: 30000, SalaryCurrency: "USD", SalaryPeriod: model.SalaryPeriodMonth},
+			{Name: "undisclosed", SalaryCurrency: "THB", SalaryPeriod: model.SalaryPeriodMonth, SalaryUndisclosed: true},
+		}
+		for i := range jobs {
+			jobs[i].CompanyID = companyUser.Company.UserID
+			jobs[i].Position = "software engineer"
+			jobs[i].Description = "make software"
+			jobs[i].IsOpen = true
+			jobs[i].ApprovalStatus = model.JobApprovalAccepted
+			if err := db.Create(&jobs[i]).Error; err != nil {
+				t.Error(err)
+				return
+			}
+		}
+
+		type FetchJobsResult struct {
+			Jobs []handlers.JobResponse `json:"jobs"`
+		}
+		for _, testCase := range []struct {
+			query    string
+			expected []string
+		}{
+			{"minSalary=18000", []string{"monthly"}},
+			{"minSalary=15000&sortBy=minSalary", []string{"monthly", "hourly"}},
+			{"maxSalary=32000", []string{"monthly"}},
+			{"minSalary=15000&currency=USD", []string{"dollars"}},
+		} {
+			w := DoRequest("GET", fmt.Sprintf("/jobs?companyId=%s&%s", companyUser.Company.UserID, testCase.query), viewerToken, "")
+			assert.Equal(t, w.Code, 200)
+			result := FetchJobsResult{}
+			if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+				t.Error(err)
+				return
+			}
+			names := []string{}
+			for _, job := range result.Jobs {
+				names = append(names, job.Name)
+			}
+			assert.Equal(t, names, testCase.expected, testCase.query)
+		}
+	})
+
+	t.Run("Location", func(t *testing.T) {
+		companyUser := CreateTestUser(t, UserCreationInfo{
+			Username:  fmt.Sprintf("locationjobtester-%d", time.Now().UnixNano()),
+			IsCompany: true,
+		})
+		viewerUser := CreateTestUser(t, UserCreationInfo{
+			Username: fmt.Sprintf("locationjobviewer-%d", time.Now().UnixNano()),
+			IsOAuth:  true,
+		})
+		companyToken := AccessToken(t, companyUser.User.ID)
+		viewerToken := AccessToken(t, viewerUser.User.ID)
+
+		jobInput := `{"name":"located","position":"Engineer","duration":"6 months","description":"desc","location":"Maejo Road","jobType":"fulltime","experience":"junior","minSalary":1,"maxSalary":2,"workMode":"hybrid","province":"chiang  mai","district":"สันทราย"}`
+		w := DoRequest("POST", "/jobs", companyToken, jobInput)
+		assert.Equal(t, w.Code, 200)
+		created := struct {
+			ID uint `json:"id"`
+		}{}
+		if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+			t.Error(err)
+			return
+		}
+		job := model.Job{}
+		if err := db.Take(&job, created.ID).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, job.WorkMode, model.WorkModeHybrid)
+		assert.Equal(t, job.Province, "Chiang Mai")
+		assert.Equal(t, job.District, "San Sai")
+		assert.Equal(t, job.Latitude != nil && job.Longitude != nil, true)
+		_ = db.Delete(&job)
+
+		w = DoRequest("POST", "/jobs", companyToken, strings.Replace(jobInput, `"chiang  mai"`, `"Atlantis"`, 1))
+		assert.Equal(t, w.Code, 400)
+		// Districts missing from the bundled dataset are placed at the centre of their province
+		w = DoRequest("POST", "/jobs", companyToken, strings.Replace(jobInput, `"สันทราย"`, `"Mae  Taeng"`, 1))
+		assert.Equal(t, w.Code, 200)
+		if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+			t.Error(err)
+			return
+		}
+		job = model.Job{}
+		if err := db.Take(&job, created.ID).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, job.Province, "Chiang Mai")
+		assert.Equal(t, job.District, "Mae Taeng")
+		province, _ := helper.FindProvince("Chiang Mai")
+		assert.Equal(t, job.Latitude != nil && *job.Latitude == province.Latitude, true)
+		_ = db.Delete(&job)
+
+		place := func(province string, district string) (*float64, *float64) {
+			p, err := helper.ResolvePlace(province, district)
+			if err != nil {
+				t.Error(err)
+			}
+			return &p.Latitude, &p.Longitude
+		}
+		jobs := []model.Job{
+			{Name: "chatuchak", WorkMode: model.WorkModeRemote, Province: "Bangkok", District: "Chatuchak"},
+			{Name: "pakkret", WorkMode: model.WorkModeHybrid, Province: "Nonthaburi", District: "Pak Kret"},
+			{Name: "chiangmai", WorkMode: model.WorkModeOnsite, Province: "Chiang Mai", District: "Mueang Chiang Mai"},
+			{Name: "unplaced", WorkMode: model.WorkModeOnsite},
+		}
+		for i := range jobs {
+			if jobs[i].Province != "" {
+				jobs[i].Latitude, jobs[i].Longitude = place(jobs[i].Province, jobs[i].District)
+			}
+			jobs[i].CompanyID = companyUser.Company.UserID
+			jobs[i].Position = "software engineer"
+			jobs[i].Description = "make software"
+			jobs[i].IsOpen = true
+			jobs[i].ApprovalStatus = model.JobApprovalAccepted
+			jobs[i].CreatedAt = time.Now().Add(time.Duration(i) * time.Second)
+			if err := db.Create(&jobs[i]).Error; err != nil {
+				t.Error(err)
+				return
+			}
+		}
+
+		type FetchJobsResult struct {
+			Jobs []handlers.JobResponse `json:"jobs"`
+		}
+		for _, testCase := range []struct {
+			query    string
+			expected []string
+		}{
+			{"workMode=remote", []string{"chatuchak"}},
+			{"workMode=onsite&workMode=hybrid", []string{"pakkret", "chiangmai", "unplaced"}},
+			{"province=เชียงใหม่", []string{"chiangmai"}},
+			// Pak Kret is about 10 km from Chatuchak, Chiang Mai is about 580 km away
+			{"nearLat=13.8283&nearLon=100.5597&radiusKm=20", []string{"chatuchak", "pakkret"}},
+			{"nearLat=13.8283&nearLon=100.5597&radiusKm=5", []string{"chatuchak"}},
+			{"nearLat=13.8283&nearLon=100.5597&radiusKm=1000", []string{"chatuchak", "pakkret", "chiangmai"}},
+		} {
+			w := DoRequest("GET", fmt.Sprintf("/jobs?companyId=%s&sortBy=oldest&%s", companyUser.Company.UserID, testCase.query), viewerToken, "")
+			assert.Equal(t, w.Code, 200)
+			result := FetchJobsResult{}
+			if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+				t.Error(err)
+				return
+			}
+			names := []string{}
+			for _, job := range result.Jobs {
+				names = append(names, job.Name)
+			}
+			assert.Equal(t, names, testCase.expected, testCase.query)
+		}
+
+		w = DoRequest("GET", "/jobs?province=Atlantis", viewerToken, "")
+		assert.Equal(t, w.Code, 400)
+		w = DoRequest("GET", "/jobs?nearLat=13.8&radiusKm=10", viewerToken, "")
+		assert.Equal(t, w.Code, 400)
+
+		w = DoRequest("GET", "/locations/provinces", viewerToken, "")
+		assert.Equal(t, w.Code, 200)
+		provinces := struct {
+			Provinces []helper.Province `json:"provinces"`
+		}{}
+		if err := json.Unmarshal(w.Body.Bytes(), &provinces); err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, len(provinces.Provinces), 77)
+	})
+
+	t.Run("Import", func(t *testing.T) {
+		var err error
+		var companyUser *UserCreationResult
+		if companyUser, err = CreateUser(UserCreationInfo{
+			Username:  fmt.Sprintf("importjobtester-%d", time.Now().UnixNano()),
+			IsCompany: true,
+		}); err != nil {
+			t.Error(err)
+			return
+		}
+		defer (func() {
+			_ = db.Delete(&companyUser.User)
+		})()
+		jwtHandler := handlers.NewJWTHandlers(db, redisClient)
+		doImport := func(fileName string, content string, dryRun bool) *httptest.ResponseRecorder {
+			var b bytes.Buffer
+			fw := multipart.NewWriter(&b)
+			if err := fw.WriteField("dryRun", fmt.Sprint(dryRun)); err != nil {
+				t.Error(err)
+			}
+			fiw, err := fw.CreateFormFile("file", fileName)
+			if err != nil {
+				t.Error(err)
+			}
+			if _, err := io.WriteString(fiw, content); err != nil {
+				t.Error(err)
+			}
+			if err := fw.Close(); err != nil {
+				t.Error(err)
+			}
+			jwtToken, _, err := jwtHandler.GenerateTokens(companyUser.User.ID)
+			if err != nil {
+				t.Error(err)
+			}
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/jobs/import", &b)
+			req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwtToken))
+			req.Header.Set("Content-Type", fw.FormDataContentType())
+			router.ServeHTTP(w, req)
+			return w
+		}
+		countJobs := func() int64 {
+			var count int64
+			if err := db.Model(&model.Job{}).Where("company_id = ?", companyUser.Company.UserID).Count(&count).Error; err != nil {
+				t.Error(err)
+			}
+			return count
+		}
+
+		csvFile := "name,position,duration,description,location,jobType,experience,minSalary,maxSalary,province,open\n" +
+			"first,Engineer,6 months,desc,Office,fulltime,junior,20000,30000,Bangkok,true\n" +
+			"no position,,6 months,desc,Office,fulltime,junior,20000,30000,,true\n" +
+			"bad range,Engineer,6 months,desc,Office,fulltime,junior,30000,20000,,true\n" +
+			"bad number,Engineer,6 months,desc,Office,fulltime,junior,lots,30000,,true\n"
+
+		w := doImport("jobs.csv", csvFile, true)
+		assert.Equal(t, w.Code, 200)
+		report := handlers.JobImportResponse{}
+		if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, report.Total, 4)
+		assert.Equal(t, report.Valid, 1)
+		assert.Equal(t, report.Invalid, 3)
+		assert.Equal(t, report.Created, 0)
+		assert.Equal(t, report.Rows[0].Valid, true)
+		assert.Equal(t, report.Rows[1].Errors, []string{"position failed the required rule"})
+		assert.Equal(t, report.Rows[2].Errors, []string{"minSalary must be lower than or equal to maxSalary"})
+		assert.Equal(t, report.Rows[3].Errors, []string{"minSalary: must be a whole number"})
+		assert.Equal(t, countJobs(), int64(0))
+
+		w = doImport("jobs.csv", csvFile, false)
+		assert.Equal(t, w.Code, 200)
+		report = handlers.JobImportResponse{}
+		if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, report.Created, 1)
+		if report.Rows[0].JobID == nil {
+			t.Error("imported row has no job ID")
+			return
+		}
+		job := model.Job{}
+		if err := db.Take(&job, *report.Rows[0].JobID).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, job.ApprovalStatus, model.JobApprovalPending)
+		assert.Equal(t, job.Province, "Bangkok")
+		assert.Equal(t, job.IsOpen, true)
+
+		jsonFile := `[{"name":"from json","position":"Engineer","duration":"1 year","description":"desc","location":"Office","jobType":"parttime","experience":"newgrad","salaryUndisclosed":true},{"name":"wrong type","minSalary":"many"}]`
+		w = doImport("jobs.json", jsonFile, false)
+		assert.Equal(t, w.Code, 200)
+		report = handlers.JobImportResponse{}
+		if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, report.Created, 1)
+		assert.Equal(t, report.Rows[1].Errors, []string{"minSalary has the wrong type"})
+		assert.Equal(t, countJobs(), int64(2))
+
+		w = doImport("jobs.csv", "name,salary\nx,1\n", true)
+		assert.Equal(t, w.Code, 400)
+		w = doImport("jobs.txt", csvFile, true)
+		assert.Equal(t, w.Code, 400)
+	})
+
+	t.Run("Openings", func(t *testing.T) {
+		var err error
+		var companyUser *UserCreationResult
+		if companyUser, err = CreateUser(UserCreationInfo{
+			Username:  fmt.Sprintf("openings-company-tester-%d", time.Now().UnixNano()),
+			IsCompany: true,
+		}); err != nil {
+			t.Error(err)
+			return
+		}
+		defer (func() {
+			_ = db.Delete(&companyUser.User)
+		})()
+		jwtToken, _, err := handlers.NewJWTHandlers(db, redisClient).GenerateTokens(companyUser.Company.UserID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		openings := uint(2)
+		job := model.Job{
+			Name:                    fmt.Sprintf("openings-job-%d", time.Now().UnixNano()),
+			CompanyID:               companyUser.Company.UserID,
+			Position:                "software engineer",
+			Description:             "make software",
+			JobType:                 model.JobTypeFullTime,
+			Experience:              model.ExperienceJunior,
+			ApprovalStatus:          model.JobApprovalAccepted,
+			IsOpen:                  true,
+			Openings:                &openings,
+			NotifyPendingWhenFilled: true,
+		}
+		if err := db.Create(&job).Error; err != nil {
+			t.Error(err)
+			return
+		}
+
+		students := []*UserCreationResult{}
+		for i := 0; i < 3; i++ {
+			studentUser, err := CreateUser(UserCreationInfo{
+				Username:  fmt.Sprintf("openings-student-%d-%d", i, time.Now().UnixNano()),
+				IsStudent: true,
+				IsOAuth:   true,
+			})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer (func() {
+				_ = db.Delete(&studentUser.User)
+			})()
+			if err := db.Create(&model.JobApplication{
+				JobID:  job.ID,
+				UserID: studentUser.User.ID,
+				Status: model.JobApplicationPending,
+			}).Error; err != nil {
+				t.Error(err)
+				return
+			}
+			students = append(students, studentUser)
+		}
+
+		type Result struct {
+			Status    string `json:"status"`
+			JobClosed bool   `json:"jobClosed"`
+		}
+		accept := func(studentID string) Result {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("PATCH", fmt.Sprintf("/jobs/%d/applications/%s/status", job.ID, studentID), strings.NewReader(`{"status": "accepted"}`))
+			req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwtToken))
+			req.Header.Add("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+			assert.Equal(t, w.Code, 200)
+			result := Result{}
+			if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+				t.Error(err)
+			}
+			return result
+		}
+
+		assert.Equal(t, accept(students[0].User.ID).JobClosed, false)
+		// Accepting the same applicant again does not count twice
+		assert.Equal(t, accept(students[0].User.ID).JobClosed, false)
+		if err := db.First(&job, job.ID).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, job.IsOpen, true)
+
+		assert.Equal(t, accept(students[1].User.ID).JobClosed, true)
+		if err := db.First(&job, job.ID).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, job.IsOpen, false)
+
+		// The closure is recorded in the job history
+		revision := model.JobRevision{}
+		if err := db.Where("job_id = ?", job.ID).Order("id DESC").First(&revision).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, len(revision.Changes), 1)
+		assert.Equal(t, revision.Changes[0].Field, "open")
+
+		// The last applicant is left pending
+		pending := model.JobApplication{JobID: job.ID, UserID: students[2].User.ID}
+		if err := db.Take(&pending).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, pending.Status, model.JobApplicationPending)
+
+		// Nobody else can apply once the job is closed
+		lateStudentUser := CreateTestUser(t, UserCreationInfo{
+			Username:  fmt.Sprintf("openings-late-student-%d", time.Now().UnixNano()),
+			IsStudent: true,
+			IsOAuth:   true,
+		})
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		file, err := form.CreateFormFile("files", "cv.pdf")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if _, err := io.WriteString(file, "%PDF-1.1\n%%EOF"); err != nil {
+			t.Error(err)
+			return
+		}
+		if err := form.Close(); err != nil {
+			t.Error(err)
+			return
+		}
+		w := DoRequestWithHeaders("POST", fmt.Sprintf("/jobs/%d/apply", job.ID), body.String(), map[string]string{
+			"Authorization": fmt.Sprintf("Bearer %s", AccessToken(t, lateStudentUser.User.ID)),
+			"Content-Type":  form.FormDataContentType(),
+		})
+		assert.Equal(t, w.Code, 403)
+	})
+	t.Run("Questions", func(t *testing.T) {
+		var err error
+		var companyUser, studentUser *UserCreationResult
+		if companyUser, err = CreateUser(UserCreationInfo{
+			Username:  fmt.Sprintf("questions-company-tester-%d", time.Now().UnixNano()),
+			IsCompany: true,
+		}); err != nil {
+			t.Error(err)
+			return
+		}
+		defer (func() {
+			_ = db.Delete(&companyUser.User)
+		})()
+		if studentUser, err = CreateUser(UserCreationInfo{
+			Username:  fmt.Sprintf("questions-student-tester-%d", time.Now().UnixNano()),
+			IsStudent: true,
+			IsOAuth:   true,
+		}); err != nil {
+			t.Error(err)
+			return
+		}
+		defer (func() {
+			_ = db.Delete(&studentUser.User)
+		})()
+		jwtHandler := handlers.NewJWTHandlers(db, redisClient)
+		companyToken, _, err := jwtHandler.GenerateTokens(companyUser.Company.UserID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		studentToken, _, err := jwtHandler.GenerateTokens(studentUser.User.ID)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		job := model.Job{
+			Name:           fmt.Sprintf("questions-job-%d", time.Now().UnixNano()),
+			CompanyID:      companyUser.Company.UserID,
+			Position:       "software engineer",
+			Description:    "make software",
+			JobType:        model.JobTypeFullTime,
+			Experience:     model.ExperienceJunior,
+			ApprovalStatus: model.JobApprovalAccepted,
+			IsOpen:         true,
+			Questions: []model.JobQuestion{
+				{Position: 0, Type: model.JobQuestionYesNo, Prompt: "Can you work on site?", Required: true},
+				{Position: 1, Type: model.JobQuestionMultiChoice, Prompt: "Which languages do you know?", Choices: []string{"Go", "Rust", "Python"}},
+				{Position: 2, Type: model.JobQuestionURL, Prompt: "Portfolio"},
+			},
+		}
+		if err := db.Create(&job).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		onSite, languages := job.Questions[0].ID, job.Questions[1].ID
+
+		apply := func(answers string) *httptest.ResponseRecorder {
+			var b bytes.Buffer
+			fw := multipart.NewWriter(&b)
+			if err := fw.WriteField("answers", answers); err != nil {
+				t.Error(err)
+			}
+			fiw, err := fw.CreateFormFile("files", "cv.pdf")
+			if err != nil {
+				t.Error(err)
+			}
+			if _, err := io.WriteString(fiw, "%PDF-1.1\n%âãÏÓ\n1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n2 0 obj\n<< /Type /Pages /Kids [3 0 R] /Count 1 >>\nendobj\n3 0 obj\n<< /Type /Page /Parent 2 0 R /MediaBox [0 0 200 200] /Contents 4 0 R >>\nendobj\n4 0 obj\n<< /Length 44 >>\nstream\nBT /F1 24 Tf 72 120 Td (CV) Tj ET\nendstream\nendobj\ntrailer\n<< /Root 1 0 R >>\n%%EOF"); err != nil {
+				t.Error(err)
+			}
+			if err := fw.Close(); err != nil {
+				t.Error(err)
+			}
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", fmt.Sprintf("/jobs/%d/apply", job.ID), &b)
+			req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", studentToken))
+			req.Header.Set("Content-Type", fw.FormDataContentType())
+			router.ServeHTTP(w, req)
+			return w
+		}
+
+		// Required questions must be answered, and answers must fit the question
+		assert.Equal(t, apply(`[]`).Code, 400)
+		assert.Equal(t, apply(`not json`).Code, 400)
+		assert.Equal(t, apply(fmt.Sprintf(`[{"questionId": %d, "value": "yes"}]`, onSite)).Code, 400)
+		assert.Equal(t, apply(fmt.Sprintf(`[{"questionId": %d, "value": true}, {"questionId": %d, "value": ["Java"]}]`, onSite, languages)).Code, 400)
+		assert.Equal(t, apply(fmt.Sprintf(`[{"questionId": %d, "value": true}, {"questionId": %d, "value": ["Go", "Rust"]}]`, onSite, languages)).Code, 200)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/jobs/%d/applications/%s", job.ID, studentUser.OAuth.Email), nil)
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", companyToken))
+		router.ServeHTTP(w, req)
+		assert.Equal(t, w.Code, 200)
+		detail := handlers.FullApplicantDetail{}
+		if err := json.Unmarshal(w.Body.Bytes(), &detail); err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, len(detail.ScreeningAnswers), 2)
+		assert.Equal(t, detail.ScreeningAnswers[0].Value, true)
+		assert.Equal(t, detail.ScreeningAnswers[1].Prompt, "Which languages do you know?")
+
+		// Changing the questions sends the job back for review, and keeps the answers already given
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("PATCH", fmt.Sprintf("/jobs/%d", job.ID), strings.NewReader(`{"questions": [{"type": "short_text", "prompt": "Why us?", "required": true}]}`))
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", companyToken))
+		req.Header.Add("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, w.Code, 200)
+		if err := db.First(&job, job.ID).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, job.ApprovalStatus, model.JobApprovalPending)
+		var questionCount int64
+		db.Model(&model.JobQuestion{}).Where("job_id = ?", job.ID).Count(&questionCount)
+		assert.Equal(t, questionCount, int64(1))
+		application := model.JobApplication{JobID: job.ID, UserID: studentUser.User.ID}
+		if err := db.Take(&application).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, len(application.Answers), 2)
+	})
+	t.Run("Withdraw", func(t *testing.T) {
+		companyUser := CreateTestUser(t, UserCreationInfo{
+			Username:  fmt.Sprintf("withdraw-company-tester-%d", time.Now().UnixNano()),
+			IsCompany: true,
+		})
+		studentUser := CreateTestUser(t, UserCreationInfo{
+			Username:  fmt.Sprintf("withdraw-student-tester-%d", time.Now().UnixNano()),
+			IsStudent: true,
+			IsOAuth:   true,
+		})
+		companyToken := AccessToken(t, companyUser.Company.UserID)
+		studentToken := AccessToken(t, studentUser.User.ID)
+
+		job := model.Job{
+			Name:           fmt.Sprintf("withdraw-job-%d", time.Now().UnixNano()),
+			CompanyID:      companyUser.Company.UserID,
+			Position:       "software engineer",
+			Description:    "make software",
+			JobType:        model.JobTypeFullTime,
+			Experience:     model.ExperienceJunior,
+			ApprovalStatus: model.JobApprovalAccepted,
+			IsOpen:         true,
+		}
+		if err := db.Create(&job).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		if err := db.Create(&model.JobApplication{
+			JobID:  job.ID,
+			UserID: studentUser.User.ID,
+			Status: model.JobApplicationAccepted,
+		}).Error; err != nil {
+			t.Error(err)
+			return
+		}
+
+		// Only the applicant can withdraw, and only once
+		w := DoRequest("POST", fmt.Sprintf("/applications/%d/withdraw", job.ID), companyToken, "")
+		assert.Equal(t, w.Code, 404)
+		w = DoRequest("POST", fmt.Sprintf("/applications/%d/withdraw", job.ID), studentToken, "")
+		assert.Equal(t, w.Code, 200)
+		w = DoRequest("POST", fmt.Sprintf("/applications/%d/withdraw", job.ID), studentToken, "")
+		assert.Equal(t, w.Code, 409)
+
+		application := model.JobApplication{JobID: job.ID, UserID: studentUser.User.ID}
+		if err := db.Take(&application).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, application.Status, model.JobApplicationWithdrawn)
+		assert.Equal(t, application.WithdrawnAt != nil, true)
+
+		// The company can no longer change it
+		w = DoRequest("PATCH", fmt.Sprintf("/jobs/%d/applications/%s/status", job.ID, studentUser.User.ID), companyToken, `{"status": "accepted"}`)
+		assert.Equal(t, w.Code, 409)
+
+		// It stays in the student's history
+		w = DoRequest("GET", "/applications?status=withdrawn", studentToken, "")
+		assert.Equal(t, w.Code, 200)
+		type History struct {
+			Total int64 `json:"total"`
+		}
+		history := History{}
+		if err := json.Unmarshal(w.Body.Bytes(), &history); err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, history.Total, int64(1))
+
+		// But not in the company's active counts
+		w = DoRequest("GET", "/jobs?companyId=self", companyToken, "")
+		assert.Equal(t, w.Code, 200)
+		type Jobs struct {
+			Jobs []handlers.JobWithStatsResponse `json:"jobs"`
+		}
+		jobs := Jobs{}
+		if err := json.Unmarshal(w.Body.Bytes(), &jobs); err != nil {
+			t.Error(err)
+			return
+		}
+		found := false
+		for _, listed := range jobs.Jobs {
+			if listed.ID == job.ID {
+				found = true
+				assert.Equal(t, listed.Pending+listed.Accepted+listed.Rejected, int64(0))
+			}
+		}
+		assert.Equal(t, found, true)
+	})
+	t.Run("Pipeline", func(t *testing.T) {
+		companyUser := CreateTestUser(t, UserCreationInfo{
+			Username:  fmt.Sprintf("pipeline-company-tester-%d", time.Now().UnixNano()),
+			IsCompany: true,
+		})
+		studentUser := CreateTestUser(t, UserCreationInfo{
+			Username:  fmt.Sprintf("pipeline-student-tester-%d", time.Now().UnixNano()),
+			IsStudent: true,
+			IsOAuth:   true,
+		})
+		companyToken := AccessToken(t, companyUser.Company.UserID)
+		studentToken := AccessToken(t, studentUser.User.ID)
+
+		job := model.Job{
+			Name:           fmt.Sprintf("pipeline-job-%d", time.Now().UnixNano()),
+			CompanyID:      companyUser.Company.UserID,
+			Position:       "software engineer",
+			Description:    "make software",
+			JobType:        model.JobTypeFullTime,
+			Experience:     model.ExperienceJunior,
+			ApprovalStatus: model.JobApprovalAccepted,
+			IsOpen:         true,
+			Pipeline: []model.PipelineStage{
+				{Key: "new", Name: "New", Outcome: model.JobApplicationPending},
+				{Key: "tech", Name: "Technical interview", Outcome: model.JobApplicationPending},
+				{Key: "hired", Name: "Hired", Outcome: model.JobApplicationAccepted},
+				{Key: "declined", Name: "Declined", Outcome: model.JobApplicationRejected},
+			},
+		}
+		if err := db.Create(&job).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		if err := db.Create(&model.JobApplication{
+			JobID:  job.ID,
+			UserID: studentUser.User.ID,
+			Status: model.JobApplicationPending,
+			Stage:  "new",
+		}).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		move := func(payload string) *httptest.ResponseRecorder {
+			return DoRequest("PATCH", fmt.Sprintf("/jobs/%d/applications/%s/status", job.ID, studentUser.User.ID), companyToken, payload)
+		}
+		currentApplication := func() model.JobApplication {
+			application := model.JobApplication{JobID: job.ID, UserID: studentUser.User.ID}
+			if err := db.Take(&application).Error; err != nil {
+				t.Error(err)
+			}
+			return application
+		}
+
+		// Moving forward keeps the simplified status, moving back is not allowed
+		assert.Equal(t, move(`{"stage": "tech"}`).Code, 200)
+		application := currentApplication()
+		assert.Equal(t, application.Stage, "tech")
+		assert.Equal(t, application.Status, model.JobApplicationPending)
+		assert.Equal(t, move(`{"stage": "new"}`).Code, 400)
+		assert.Equal(t, move(`{"stage": "interview"}`).Code, 400)
+		assert.Equal(t, move(`{"stage": "hired", "status": "rejected"}`).Code, 400)
+
+		// Per-stage counts for the company
+		w := DoRequest("GET", "/jobs?companyId=self", companyToken, "")
+		assert.Equal(t, w.Code, 200)
+		type Jobs struct {
+			Jobs []handlers.JobWithStatsResponse `json:"jobs"`
+		}
+		jobs := Jobs{}
+		if err := json.Unmarshal(w.Body.Bytes(), &jobs); err != nil {
+			t.Error(err)
+			return
+		}
+		found := false
+		for _, listed := range jobs.Jobs {
+			if listed.ID == job.ID {
+				found = true
+				assert.Equal(t, len(listed.Stages), 4)
+				assert.Equal(t, listed.Stages[1].Key, "tech")
+				assert.Equal(t, listed.Stages[1].Count, int64(1))
+				assert.Equal(t, listed.Pending, int64(1))
+			}
+		}
+		assert.Equal(t, found, true)
+
+		// Students see the simplified status only
+		w = DoRequest("GET", "/applications", studentToken, "")
+		assert.Equal(t, w.Code, 200)
+		assert.Equal(t, strings.Contains(w.Body.String(), `"stage"`), false)
+
+		// The stage in use cannot be dropped from the pipeline
+		w = DoRequest("PATCH", fmt.Sprintf("/jobs/%d", job.ID), companyToken, `{"pipeline": []}`)
+		assert.Equal(t, w.Code, 409)
+
+		// Rejected applications can be reconsidered, but not hired directly
+		assert.Equal(t, move(`{"status": "rejected"}`).Code, 200)
+		assert.Equal(t, currentApplication().Stage, "declined")
+		assert.Equal(t, move(`{"stage": "hired"}`).Code, 400)
+		assert.Equal(t, move(`{"stage": "tech"}`).Code, 200)
+		assert.Equal(t, move(`{"status": "accepted"}`).Code, 200)
+		application = currentApplication()
+		assert.Equal(t, application.Stage, "hired")
+		assert.Equal(t, application.Status, model.JobApplicationAccepted)
+	})
+	t.Run("History", func(t *testing.T) {
+		companyUser := CreateTestUser(t, UserCreationInfo{
+			Username:  fmt.Sprintf("history-company-tester-%d", time.Now().UnixNano()),
+			IsCompany: true,
+		})
+		studentUser := CreateTestUser(t, UserCreationInfo{
+			Username:  fmt.Sprintf("history-student-tester-%d", time.Now().UnixNano()),
+			IsStudent: true,
+			IsOAuth:   true,
+		})
+		otherStudentUser := CreateTestUser(t, UserCreationInfo{
+			Username:  fmt.Sprintf("history-other-student-tester-%d", time.Now().UnixNano()),
+			IsStudent: true,
+		})
+		companyToken := AccessToken(t, companyUser.Company.UserID)
+		studentToken := AccessToken(t, studentUser.User.ID)
+		otherStudentToken := AccessToken(t, otherStudentUser.User.ID)
+
+		job := model.Job{
+			Name:           fmt.Sprintf("history-job-%d", time.Now().UnixNano()),
+			CompanyID:      companyUser.Company.UserID,
+			Position:       "software engineer",
+			Description:    "make software",
+			JobType:        model.JobTypeFullTime,
+			Experience:     model.ExperienceJunior,
+			ApprovalStatus: model.JobApprovalAccepted,
+			IsOpen:         true,
+		}
+		if err := db.Create(&job).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		if err := db.Create(&model.JobApplication{
+			JobID:  job.ID,
+			UserID: studentUser.User.ID,
+			Status: model.JobApplicationPending,
+		}).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		statusPath := fmt.Sprintf("/jobs/%d/applications/%s/status", job.ID, studentUser.User.ID)
+		historyPath := fmt.Sprintf("/jobs/%d/applicants/%s/history", job.ID, studentUser.User.ID)
+		assert.Equal(t, DoRequest("PATCH", statusPath, companyToken, `{"stage": "interview", "reason": "strong portfolio"}`).Code, 200)
+		assert.Equal(t, DoRequest("PATCH", statusPath, companyToken, `{"status": "rejected", "reason": "position needs more experience"}`).Code, 200)
+
+		// The company sees every change with its actor and reason
+		w := DoRequest("GET", historyPath, companyToken, "")
+		assert.Equal(t, w.Code, 200)
+		type History struct {
+			History []handlers.ApplicationStatusChangeResponse `json:"history"`
+		}
+		history := History{}
+		if err := json.Unmarshal(w.Body.Bytes(), &history); err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, len(history.History), 2)
+		assert.Equal(t, history.History[0].FromStage, "applied")
+		assert.Equal(t, history.History[0].ToStage, "interview")
+		assert.Equal(t, history.History[0].Reason, "strong portfolio")
+		assert.Equal(t, history.History[1].ToStatus, model.JobApplicationRejected)
+		assert.Equal(t, history.History[1].ActorID, companyUser.Company.UserID)
+
+		// The student only sees changes of their status, without reasons
+		w = DoRequest("GET", historyPath, studentToken, "")
+		assert.Equal(t, w.Code, 200)
+		assert.Equal(t, strings.Contains(w.Body.String(), "reason"), false)
+		timeline := struct {
+			History []handlers.ApplicationTimelineEntry `json:"history"`
+		}{}
+		if err := json.Unmarshal(w.Body.Bytes(), &timeline); err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, len(timeline.History), 1)
+		assert.Equal(t, timeline.History[0].FromStatus, model.JobApplicationPending)
+		assert.Equal(t, timeline.History[0].ToStatus, model.JobApplicationRejected)
+
+		// Nobody else sees it
+		assert.Equal(t, DoRequest("GET", historyPath, otherStudentToken, "").Code, 403)
+	})
+	t.Run("Interview", func(t *testing.T) {
+		companyUser := CreateTestUser(t, UserCreationInfo{
+			Username:  fmt.Sprintf("interview-company-tester-%d", time.Now().UnixNano()),
+			IsCompany: true,
+		})
+		studentUser := CreateTestUser(t, UserCreationInfo{
+			Username:  fmt.Sprintf("interview-student-tester-%d", time.Now().UnixNano()),
+			IsStudent: true,
+			IsOAuth:   true,
+		})
+		otherStudentUser := CreateTestUser(t, UserCreationInfo{
+			Username:  fmt.Sprintf("interview-other-student-tester-%d", time.Now().UnixNano()),
+			IsStudent: true,
+		})
+		companyToken := AccessToken(t, companyUser.Company.UserID)
+		studentToken := AccessToken(t, studentUser.User.ID)
+		otherStudentToken := AccessToken(t, otherStudentUser.User.ID)
+
+		job := model.Job{
+			Name:           fmt.Sprintf("interview-job-%d", time.Now().UnixNano()),
+			CompanyID:      companyUser.Company.UserID,
+			Position:       "software engineer",
+			Description:    "make software",
+			JobType:        model.JobTypeFullTime,
+			Experience:     model.ExperienceJunior,
+			ApprovalStatus: model.JobApprovalAccepted,
+			IsOpen:         true,
+		}
+		if err := db.Create(&job).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		if err := db.Create(&model.JobApplication{
+			JobID:  job.ID,
+			UserID: studentUser.User.ID,
+			Status: model.JobApplicationPending,
+		}).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		proposePath := fmt.Sprintf("/jobs/%d/applicants/%s/interviews", job.ID, studentUser.User.ID)
+		slotAt := func(days int) string {
+			return time.Now().Add(time.Duration(days) * 24 * time.Hour).UTC().Format(time.RFC3339)
+		}
+
+		// Slots must be in the future and say where the interview takes place
+		assert.Equal(t, DoRequest("POST", proposePath, companyToken, fmt.Sprintf(`{"slots": [{"startsAt": %q, "durationMinutes": 30, "location": "Room 1"}]}`, slotAt(-1))).Code, 400)
+		assert.Equal(t, DoRequest("POST", proposePath, companyToken, fmt.Sprintf(`{"slots": [{"startsAt": %q, "durationMinutes": 30}]}`, slotAt(1))).Code, 400)
+		assert.Equal(t, DoRequest("POST", proposePath, studentToken, fmt.Sprintf(`{"slots": [{"startsAt": %q, "durationMinutes": 30, "location": "Room 1"}]}`, slotAt(1))).Code, 403)
+
+		w := DoRequest("POST", proposePath, companyToken, fmt.Sprintf(
+			`{"note": "Please bring your portfolio", "slots": [{"startsAt": %q, "durationMinutes": 45, "meetingUrl": "https://meet.example.com/abc"}, {"startsAt": %q, "durationMinutes": 60, "location": "Head office, floor 3"}]}`,
+			slotAt(3), slotAt(2)))
+		assert.Equal(t, w.Code, 201)
+		interview := model.Interview{}
+		if err := json.Unmarshal(w.Body.Bytes(), &interview); err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, interview.Status, model.InterviewProposed)
+		assert.Equal(t, len(interview.Slots), 2)
+
+		// The company sees the interviews it proposed to this applicant
+		w = DoRequest("GET", proposePath, companyToken, "")
+		assert.Equal(t, w.Code, 200)
+		proposed := struct {
+			Interviews []model.Interview `json:"interviews"`
+		}{}
+		if err := json.Unmarshal(w.Body.Bytes(), &proposed); err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, len(proposed.Interviews), 1)
+
+		// The student lists the interview and picks the in-person slot
+		w = DoRequest("GET", fmt.Sprintf("/applications/%d/interviews", job.ID), studentToken, "")
+		assert.Equal(t, w.Code, 200)
+		listed := struct {
+			Interviews []model.Interview `json:"interviews"`
+		}{}
+		if err := json.Unmarshal(w.Body.Bytes(), &listed); err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, len(listed.Interviews), 1)
+		assert.Equal(t, listed.Interviews[0].Slots[0].Location, "Head office, floor 3")
+		slotID := listed.Interviews[0].Slots[0].ID
+
+		selectPath := fmt.Sprintf("/applications/%d/interviews/%d/select", job.ID, interview.ID)
+		assert.Equal(t, DoRequest("POST", selectPath, otherStudentToken, fmt.Sprintf(`{"slotId": %d}`, slotID)).Code, 404)
+		assert.Equal(t, DoRequest("POST", selectPath, studentToken, `{"slotId": 4294967295}`).Code, 400)
+		assert.Equal(t, DoRequest("POST", selectPath, studentToken, fmt.Sprintf(`{"slotId": %d}`, slotID)).Code, 200)
+		stored := model.Interview{}
+		if err := db.Take(&stored, interview.ID).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, stored.Status, model.InterviewScheduled)
+		assert.Equal(t, *stored.SelectedSlotID, slotID)
+		assert.Equal(t, stored.InvitedAt != nil, true)
+		assert.Equal(t, stored.Sequence, uint(0))
+
+		// Rescheduling replaces the slots and raises the invitation sequence
+		w = DoRequest("POST", fmt.Sprintf("/jobs/%d/interviews/%d/reschedule", job.ID, interview.ID), companyToken, fmt.Sprintf(`{"startsAt": %q, "durationMinutes": 30, "meetingUrl": "https://meet.example.com/xyz"}`, slotAt(4)))
+		assert.Equal(t, w.Code, 200)
+		if err := db.Preload("Slots").Take(&stored, interview.ID).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, stored.Status, model.InterviewScheduled)
+		assert.Equal(t, len(stored.Slots), 1)
+		assert.Equal(t, *stored.SelectedSlotID, stored.Slots[0].ID)
+		assert.Equal(t, stored.Sequence, uint(1))
+
+		// Cancelling keeps the calendar UID and raises the sequence again
+		assert.Equal(t, DoRequest("POST", fmt.Sprintf("/applications/%d/interviews/%d/cancel", job.ID, interview.ID), studentToken, `{"reason": "accepted another offer"}`).Code, 200)
+		assert.Equal(t, DoRequest("POST", fmt.Sprintf("/jobs/%d/interviews/%d/cancel", job.ID, interview.ID), companyToken, "").Code, 409)
+		if err := db.Preload("Slots").Take(&stored, interview.ID).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, stored.Status, model.InterviewCancelled)
+		assert.Equal(t, stored.CancelReason, "accepted another offer")
+		assert.Equal(t, stored.Sequence, uint(2))
+
+		event := helper.CalendarEvent{
+			UID:      stored.CalendarUID(),
+			Sequence: stored.Sequence,
+			Method:   helper.CalendarCancel,
+			Stamp:    time.Now(),
+			Start:    stored.Slots[0].StartsAt,
+			End:      stored.Slots[0].EndsAt(),
+			Summary:  "Interview: software engineer, KU-Work; " + strings.Repeat("long title ", 10),
+		}
+		ics := string(event.ICS())
+		assert.Equal(t, strings.Contains(ics, "METHOD:CANCEL\r\n"), true)
+		assert.Equal(t, strings.Contains(ics, fmt.Sprintf("UID:interview-%d@ku-work\r\n", interview.ID)), true)
+		assert.Equal(t, strings.Contains(ics, "SEQUENCE:2\r\n"), true)
+		assert.Equal(t, strings.Contains(ics, "STATUS:CANCELLED\r\n"), true)
+		assert.Equal(t, strings.Contains(ics, "SUMMARY:Interview: software engineer\\, KU-Work\\; "), true)
+		for _, line := range strings.Split(strings.TrimSuffix(ics, "\r\n"), "\r\n") {
+			assert.Equal(t, len(line) <= 75, true)
+		}
+	})
+	t.Run("Evaluation", func(t *testing.T) {
+		companyUser := CreateTestUser(t, UserCreationInfo{
+			Username:  fmt.Sprintf("evaluation-company-tester-%d", time.Now().UnixNano()),
+			IsCompany: true,
+		})
+		adminUser := CreateTestUser(t, UserCreationInfo{
+			Username: fmt.Sprintf("evaluation-admin-tester-%d", time.Now().UnixNano()),
+			IsAdmin:  true,
+		})
+		studentUser := CreateTestUser(t, UserCreationInfo{
+			Username:  fmt.Sprintf("evaluation-student-tester-%d", time.Now().UnixNano()),
+			IsStudent: true,
+			IsOAuth:   true,
+		})
+		otherStudentUser := CreateTestUser(t, UserCreationInfo{
+			Username:  fmt.Sprintf("evaluation-other-student-tester-%d", time.Now().UnixNano()),
+			IsStudent: true,
+			IsOAuth:   true,
+		})
+		companyToken := AccessToken(t, companyUser.Company.UserID)
+		adminToken := AccessToken(t, adminUser.User.ID)
+		studentToken := AccessToken(t, studentUser.User.ID)
+
+		job := model.Job{
+			Name:           fmt.Sprintf("evaluation-job-%d", time.Now().UnixNano()),
+			CompanyID:      companyUser.Company.UserID,
+			Position:       "software engineer",
+			Description:    "make software",
+			JobType:        model.JobTypeFullTime,
+			Experience:     model.ExperienceJunior,
+			ApprovalStatus: model.JobApprovalAccepted,
+			IsOpen:         true,
+		}
+		if err := db.Create(&job).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		for _, student := range []*UserCreationResult{studentUser, otherStudentUser} {
+			if err := db.Create(&model.JobApplication{
+				JobID:  job.ID,
+				UserID: student.User.ID,
+				Status: model.JobApplicationPending,
+			}).Error; err != nil {
+				t.Error(err)
+				return
+			}
+		}
+		evaluationPath := func(student *UserCreationResult) string {
+			return fmt.Sprintf("/jobs/%d/applications/%s/evaluation", job.ID, student.User.ID)
+		}
+
+		// Only the company evaluates, with ratings from 1 to 5
+		assert.Equal(t, DoRequest("PATCH", evaluationPath(studentUser), studentToken, `{"rating": 5}`).Code, 403)
+		assert.Equal(t, DoRequest("PATCH", evaluationPath(studentUser), companyToken, `{"rating": 6}`).Code, 400)
+		assert.Equal(t, DoRequest("PATCH", evaluationPath(studentUser), companyToken, `{}`).Code, 400)
+		assert.Equal(t, DoRequest("PATCH", evaluationPath(studentUser), companyToken, `{"notes": "Great communicator", "rating": 4}`).Code, 200)
+		assert.Equal(t, DoRequest("PATCH", evaluationPath(otherStudentUser), companyToken, `{"rating": 2}`).Code, 200)
+
+		type Listed struct {
+			UserID     string                          `json:"userId"`
+			Evaluation *handlers.ApplicationEvaluation `json:"evaluation"`
+		}
+		list := func(token string, query string) (int, []Listed) {
+			w := DoRequest("GET", fmt.Sprintf("/jobs/%d/applications?%s", job.ID, query), token, "")
+			listed := []Listed{}
+			if w.Code == 200 {
+				if err := json.Unmarshal(w.Body.Bytes(), &listed); err != nil {
+					t.Error(err)
+				}
+			}
+			return w.Code, listed
+		}
+
+		code, listed := list(companyToken, "sortBy=rating_desc")
+		assert.Equal(t, code, 200)
+		assert.Equal(t, len(listed), 2)
+		assert.Equal(t, listed[0].UserID, studentUser.User.ID)
+		assert.Equal(t, *listed[0].Evaluation.Rating, uint(4))
+		assert.Equal(t, listed[0].Evaluation.Notes, "Great communicator")
+		code, listed = list(companyToken, "sortBy=latest&minRating=3")
+		assert.Equal(t, code, 200)
+		assert.Equal(t, len(listed), 1)
+		code, listed = list(companyToken, "sortBy=latest&minRating=3&maxRating=4")
+		assert.Equal(t, code, 200)
+		assert.Equal(t, len(listed), 1)
+		code, _ = list(companyToken, "sortBy=latest&minRating=4&maxRating=2")
+		assert.Equal(t, code, 400)
+		code, listed = list(companyToken, "sortBy=latest&notes=communicator")
+		assert.Equal(t, code, 200)
+		assert.Equal(t, len(listed), 1)
+		assert.Equal(t, listed[0].UserID, studentUser.User.ID)
+
+		// Admins see the applications but not the evaluations
+		code, listed = list(adminToken, "sortBy=latest")
+		assert.Equal(t, code, 200)
+		assert.Equal(t, len(listed), 2)
+		assert.Equal(t, listed[0].Evaluation == nil, true)
+		code, _ = list(adminToken, "sortBy=rating_desc")
+		assert.Equal(t, code, 403)
+
+		detailPath := fmt.Sprintf("/jobs/%d/applications/%s", job.ID, studentUser.OAuth.Email)
+		w := DoRequest("GET", detailPath, companyToken, "")
+		assert.Equal(t, w.Code, 200)
+		assert.Equal(t, strings.Contains(w.Body.String(), "Great communicator"), true)
+		w = DoRequest("GET", detailPath, studentToken, "")
+		assert.Equal(t, w.Code, 200)
+		assert.Equal(t, strings.Contains(w.Body.String(), "Great communicator"), false)
+
+		// Anonymizing the student removes the notes
+		if err := services.AnonymizeJobApplicationsForStudent(db, studentUser.User.ID); err != nil {
+			t.Error(err)
+			return
+		}
+		application := model.JobApplication{JobID: job.ID, UserID: studentUser.User.ID}
+		if err := db.Take(&application).Error; err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, application.RecruiterNotes, "")
+		assert.Equal(t, *application.Rating, uint(4))
+	})
 }
@@ -7,6 +7,8 @@ import (
 	"ku-work/backend/handlers"
 	"ku-work/backend/model"
 	"ku-work/backend/services"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
@@ -332,3 +334,46 @@ func CreateUser(config UserCreationInfo) (*UserCreationResult, error) {
 	success = true
 	return &result, nil
 }
+
+// CreateTestUser creates a user like CreateUser and deletes it once the test finishes.
+func CreateTestUser(t *testing.T, config UserCreationInfo) *UserCreationResult {
+	t.Helper()
+	result, err := CreateUser(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = db.Delete(&result.User)
+	})
+	return result
+}
+
+// AccessToken returns a JWT access token for the user.
+func AccessToken(t *testing.T, userID string) string {
+	t.Helper()
+	token, _, err := handlers.NewJWTHandlers(db, redisClient).GenerateTokens(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// DoRequest sends a request with a JSON payload to the router, authenticated with token unless it is empty.
+func DoRequest(method string, path string, token string, payload string) *httptest.ResponseRecorder {
+	headers := map[string]string{"Content-Type": "application/json"}
+	if token != "" {
+		headers["Authorization"] = fmt.Sprintf("Bearer %s", token)
+	}
+	return DoRequestWithHeaders(method, path, payload, headers)
+}
+
+// DoRequestWithHeaders sends a request to the router with only the given headers set.
+func DoRequestWithHeaders(method string, path string, payload string, headers map[string]string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, strings.NewReader(payload))
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
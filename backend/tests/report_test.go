@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"ku-work/backend/handlers"
+	"ku-work/backend/model"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties/assert"
+)
+
+func TestReport(t *testing.T) {
+	t.Run("Moderation", func(t *testing.T) {
+		adminUser := CreateTestUser(t, UserCreationInfo{
+			Username: fmt.Sprintf("reporttester-admin-%d", time.Now().UnixNano()),
+			IsAdmin:  true,
+		})
+		companyUser := CreateTestUser(t, UserCreationInfo{
+			Username:  fmt.Sprintf("reporttester-company-%d", time.Now().UnixNano()),
+			IsCompany: true,
+		})
+		studentUser := CreateTestUser(t, UserCreationInfo{
+			Username:  fmt.Sprintf("reporttester-student-%d", time.Now().UnixNano()),
+			IsStudent: true,
+		})
+
+		adminToken := AccessToken(t, adminUser.User.ID)
+		companyToken := AccessToken(t, companyUser.User.ID)
+		studentToken := AccessToken(t, studentUser.User.ID)
+
+		jobs := []model.Job{
+			{Name: "scam", ApprovalStatus: model.JobApprovalAccepted},
+			{Name: "other", ApprovalStatus: model.JobApprovalAccepted},
+			{Name: "pending", ApprovalStatus: model.JobApprovalPending},
+		}
+		for i := range jobs {
+			jobs[i].CompanyID = companyUser.Company.UserID
+			jobs[i].Position = "software engineer"
+			jobs[i].JobType = model.JobTypeFullTime
+			jobs[i].Experience = model.ExperienceJunior
+			jobs[i].IsOpen = true
+			if err := db.Create(&jobs[i]).Error; err != nil {
+				t.Error(err)
+				return
+			}
+		}
+		// Bookmarks on the jobs are removed once they are closed
+		for _, job := range jobs[:2] {
+			if err := db.Create(&model.SavedJob{UserID: studentUser.User.ID, JobID: job.ID}).Error; err != nil {
+				t.Error(err)
+				return
+			}
+		}
+		savedCount := func(jobID uint) int64 {
+			var count int64
+			db.Model(&model.SavedJob{}).Where("job_id = ?", jobID).Count(&count)
+			return count
+		}
+
+		// Filing reports
+		w := DoRequest("POST", fmt.Sprintf("/jobs/%d/report", jobs[0].ID), studentToken, `{"reason": "scam", "details": "asks for a deposit"}`)
+		assert.Equal(t, w.Code, 200)
+		jobReport := model.Report{}
+		if err := json.Unmarshal(w.Body.Bytes(), &jobReport); err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, jobReport.Status, model.ReportStatusOpen)
+		assert.Equal(t, jobReport.CompanyID, companyUser.Company.UserID)
+
+		w = DoRequest("POST", fmt.Sprintf("/jobs/%d/report", jobs[0].ID), studentToken, `{"reason": "misleading"}`)
+		assert.Equal(t, w.Code, 409)
+		w = DoRequest("POST", fmt.Sprintf("/jobs/%d/report", jobs[1].ID), studentToken, `{"reason": "other"}`)
+		assert.Equal(t, w.Code, 400)
+		w = DoRequest("POST", fmt.Sprintf("/jobs/%d/report", jobs[2].ID), studentToken, `{"reason": "spam"}`)
+		assert.Equal(t, w.Code, 404)
+		w = DoRequest("POST", fmt.Sprintf("/jobs/%d/report", jobs[1].ID), companyToken, `{"reason": "spam"}`)
+		assert.Equal(t, w.Code, 400)
+
+		w = DoRequest("POST", fmt.Sprintf("/company/%s/report", companyUser.Company.UserID), studentToken, `{"reason": "scam"}`)
+		assert.Equal(t, w.Code, 200)
+		companyReport := model.Report{}
+		if err := json.Unmarshal(w.Body.Bytes(), &companyReport); err != nil {
+			t.Error(err)
+			return
+		}
+
+		// The queue is admin only
+		w = DoRequest("GET", "/admin/reports", studentToken, "")
+		assert.Equal(t, w.Code, 403)
+		w = DoRequest("GET", fmt.Sprintf("/admin/reports?companyId=%s", companyUser.Company.UserID), adminToken, "")
+		assert.Equal(t, w.Code, 200)
+		queue := []handlers.ReportResponse{}
+		if err := json.Unmarshal(w.Body.Bytes(), &queue); err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, len(queue), 2)
+		assert.Equal(t, queue[0].ID, jobReport.ID)
+		assert.Equal(t, queue[0].JobName != nil && *queue[0].JobName == "scam", true)
+		assert.Equal(t, queue[0].ReporterName, studentUser.User.Username)
+
+		// Closing the job
+		w = DoRequest("PATCH", fmt.Sprintf("/admin/reports/%d", companyReport.ID), adminToken, `{"action": "close_job"}`)
+		assert.Equal(t, w.Code, 400)
+		w = DoRequest("PATCH", fmt.Sprintf("/admin/reports/%d", jobReport.ID), adminToken, `{"action": "close_job", "resolution": "confirmed scam"}`)
+		assert.Equal(t, w.Code, 200)
+		job := model.Job{}
+		db.First(&job, jobs[0].ID)
+		assert.Equal(t, job.IsOpen, false)
+		assert.Equal(t, savedCount(jobs[0].ID), int64(0))
+		assert.Equal(t, savedCount(jobs[1].ID), int64(1))
+		db.First(&jobReport, jobReport.ID)
+		assert.Equal(t, jobReport.Status, model.ReportStatusActioned)
+		var audits int64
+		db.Model(&model.Audit{}).Where("object_name = ? AND object_id = ? AND action = ?", "Job", strconv.FormatUint(uint64(jobs[0].ID), 10), "closed").Count(&audits)
+		assert.Equal(t, audits, int64(1))
+		w = DoRequest("PATCH", fmt.Sprintf("/admin/reports/%d", jobReport.ID), adminToken, `{"action": "dismiss"}`)
+		assert.Equal(t, w.Code, 409)
+
+		// Suspending the company closes its jobs and locks the account
+		w = DoRequest("PATCH", fmt.Sprintf("/admin/reports/%d", companyReport.ID), adminToken, `{"action": "suspend_company"}`)
+		assert.Equal(t, w.Code, 200)
+		db.First(&job, jobs[1].ID)
+		assert.Equal(t, job.IsOpen, false)
+		assert.Equal(t, savedCount(jobs[1].ID), int64(0))
+		db.Model(&model.Audit{}).Where("object_name = ? AND object_id = ? AND action = ?", "Company", companyUser.Company.UserID, "suspended").Count(&audits)
+		assert.Equal(t, audits, int64(1))
+		w = DoRequest("GET", "/me", companyToken, "")
+		assert.Equal(t, w.Code, 403)
+
+		w = DoRequest("POST", fmt.Sprintf("/company/%s/suspension", companyUser.Company.UserID), adminToken, `{"suspend": false, "reason": "appeal accepted"}`)
+		assert.Equal(t, w.Code, 200)
+		w = DoRequest("GET", "/me", companyToken, "")
+		assert.Equal(t, w.Code, 200)
+	})
+}
@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"ku-work/backend/handlers"
+	"ku-work/backend/model"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties/assert"
+)
+
+func TestSkill(t *testing.T) {
+	t.Run("TagJobsAndFilter", func(t *testing.T) {
+		adminUser := CreateTestUser(t, UserCreationInfo{
+			Username: fmt.Sprintf("skilltester-admin-%d", time.Now().UnixNano()),
+			IsAdmin:  true,
+		})
+		companyUser := CreateTestUser(t, UserCreationInfo{
+			Username:  fmt.Sprintf("skilltester-company-%d", time.Now().UnixNano()),
+			IsCompany: true,
+		})
+		adminToken := AccessToken(t, adminUser.User.ID)
+		companyToken := AccessToken(t, companyUser.User.ID)
+
+		suffix := time.Now().UnixNano()
+		skillName := fmt.Sprintf("Go %d", suffix)
+		alias := fmt.Sprintf("golang %d", suffix)
+		w := DoRequest("POST", "/admin/skills", adminToken, fmt.Sprintf(`{"name":%q,"aliases":[%q]}`, skillName, alias))
+		assert.Equal(t, w.Code, 200)
+		skill := model.Skill{}
+		if err := json.Unmarshal(w.Body.Bytes(), &skill); err != nil {
+			t.Error(err)
+			return
+		}
+		defer (func() {
+			_ = db.Delete(&skill)
+		})()
+
+		// Aliases may not be reused by another skill
+		w = DoRequest("POST", "/admin/skills", adminToken, fmt.Sprintf(`{"name":%q}`, strings.ToUpper(alias)))
+		assert.Equal(t, w.Code, 409)
+
+		jobInput := fmt.Sprintf(`{"name":"skilled-job-%d","position":"Engineer","duration":"6 months","description":"desc","location":"Bangkok","jobType":"fulltime","experience":"junior","minSalary":1,"maxSalary":2,"open":true,"requiredSkills":[%q]}`, suffix, strings.ToUpper(alias))
+		w = DoRequest("POST", "/jobs", companyToken, jobInput)
+		assert.Equal(t, w.Code, 200)
+
+		w = DoRequest("POST", "/jobs", companyToken, strings.Replace(jobInput, alias, "no-such-skill", 1))
+		assert.Equal(t, w.Code, 400)
+
+		type FetchJobsResult struct {
+			Jobs []handlers.JobResponse `json:"jobs"`
+		}
+		w = DoRequest("GET", "/jobs?skills="+strings.ReplaceAll(skillName, " ", "+"), adminToken, "")
+		assert.Equal(t, w.Code, 200)
+		result := FetchJobsResult{}
+		if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, len(result.Jobs), 1)
+		if len(result.Jobs) == 1 {
+			assert.Equal(t, len(result.Jobs[0].Skills), 1)
+			if len(result.Jobs[0].Skills) == 1 {
+				assert.Equal(t, result.Jobs[0].Skills[0].Name, skillName)
+				assert.Equal(t, result.Jobs[0].Skills[0].Required, true)
+			}
+		}
+	})
+}